@@ -38,24 +38,287 @@ type AgentParameters struct {
 	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
 	// +optional
 	Description *string `json:"description,omitempty"`
+	// DescriptionFrom sources the description from a key of a ConfigMap.
+	// Description takes precedence when both are set.
+	// +optional
+	DescriptionFrom *ConfigMapKeySelector `json:"descriptionFrom,omitempty"`
+	// +kubebuilder:validation:MaxProperties=128
 	// +optional
 	Tags *map[string]string `json:"tags,omitempty"`
+	// Name of the agent. Defaults to the name of the Agent.
 	// +optional
 	Name *string `json:"name,omitempty"`
+	// Identifier of the agent. Harness assigns one if omitted, which is
+	// recorded as the Agent's external name. To import an existing agent,
+	// set the external name to its identifier instead.
 	// +optional
 	Identifier *string `json:"identifier,omitempty"`
+	// Type of the agent. MANAGED_ARGO agents install and manage their own
+	// Argo CD, while CONNECTED_ARGO agents connect to an existing one.
+	// Harness's default is used if omitted. The type cannot be updated.
+	// +kubebuilder:validation:Enum=MANAGED_ARGO;CONNECTED_ARGO
+	// +optional
+	Type *string `json:"type,omitempty"`
+	// Namespace the agent is installed in. Defaults to harness.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+	// HighAvailability installs the agent with replicated components.
+	// Disabling it saves resources, for example in development clusters.
+	// +kubebuilder:default=true
+	// +optional
+	HighAvailability *bool `json:"highAvailability,omitempty"`
+	// MappedProjects maps Argo CD projects the agent manages to the Harness
+	// projects their applications belong to.
+	// +listType=map
+	// +listMapKey=argoProject
+	// +optional
+	MappedProjects []ProjectMapping `json:"mappedProjects,omitempty"`
+	// InClusterDeployment is the Deployment of the agent, when the agent
+	// runs in the same cluster as the provider. Its readiness is checked in
+	// addition to the health Harness reports when the provider is run with
+	// in-cluster agent health enabled.
+	// +optional
+	InClusterDeployment *DeploymentReference `json:"inClusterDeployment,omitempty"`
+}
+
+// DefaultScope sets the supplied account, organization and project
+// identifiers where the parameters do not specify their own. Identifiers set
+// to an empty string are specified. The organization is only defaulted if
+// neither an organization nor a project is specified, and the project only if
+// the organization is the supplied one. Empty defaults are not applied. It
+// returns true if it changed any parameter.
+func (p *AgentParameters) DefaultScope(account, org, project string) bool {
+	changed := false
+	if p.AccountIdentifier == nil && account != "" {
+		p.AccountIdentifier = &account
+		changed = true
+	}
+	if p.OrgIdentifier == nil && p.ProjectIdentifier == nil && org != "" {
+		p.OrgIdentifier = &org
+		changed = true
+	}
+	if p.ProjectIdentifier == nil && project != "" && org != "" && p.OrgIdentifier != nil && *p.OrgIdentifier == org {
+		p.ProjectIdentifier = &project
+		changed = true
+	}
+	return changed
+}
+
+// A ProjectMapping maps an Argo CD project to a Harness project.
+type ProjectMapping struct {
+	// ArgoProject is the name of the Argo CD project.
+	ArgoProject string `json:"argoProject"`
+	// OrgIdentifier of the organization the Harness project belongs to.
+	OrgIdentifier string `json:"orgIdentifier"`
+	// ProjectIdentifier of the Harness project.
+	ProjectIdentifier string `json:"projectIdentifier"`
+}
+
+// A DeploymentReference references a Deployment.
+type DeploymentReference struct {
+	// Name of the Deployment.
+	// +kubebuilder:default=gitops-agent
+	// +optional
+	Name string `json:"name,omitempty"`
+	// Namespace of the Deployment. The Deployment of a NamespacedAgent must
+	// be in its own namespace, which an empty namespace refers to.
+	Namespace string `json:"namespace"`
+}
+
+// AgentHealth is the health of an agent's components.
+type AgentHealth struct {
+	// ConnectionStatus is whether the agent is connected to Harness, e.g.
+	// CONNECTED or DISCONNECTED.
+	// +optional
+	ConnectionStatus string `json:"connectionStatus,omitempty"`
+
+	// LastHeartbeat is when the agent last reported to Harness. It is
+	// refreshed at most every five minutes.
+	// +optional
+	LastHeartbeat *metav1.Time `json:"lastHeartbeat,omitempty"`
+
+	// GitOpsAgent is the health of the Harness GitOps agent.
+	// +optional
+	GitOpsAgent *AgentComponentHealth `json:"gitopsAgent,omitempty"`
+
+	// ApplicationController is the health of the Argo CD application
+	// controller.
+	// +optional
+	ApplicationController *AgentComponentHealth `json:"applicationController,omitempty"`
+
+	// RepoServer is the health of the Argo CD repo server.
+	// +optional
+	RepoServer *AgentComponentHealth `json:"repoServer,omitempty"`
+
+	// Redis is the health of the Argo CD Redis server.
+	// +optional
+	Redis *AgentComponentHealth `json:"redis,omitempty"`
+}
+
+// AgentComponentHealth is the health of one of an agent's components.
+type AgentComponentHealth struct {
+	// Status of the component, e.g. HEALTHY or UNHEALTHY.
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// Message explains the status of the component.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Version of the component.
+	// +optional
+	Version string `json:"version,omitempty"`
+}
+
+// A ConfigMapKeySelector selects a key of a ConfigMap.
+type ConfigMapKeySelector struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Namespace of the ConfigMap. The ConfigMap of a NamespacedAgent must be
+	// in its own namespace, which an empty namespace refers to.
+	Namespace string `json:"namespace"`
+	// Key within the ConfigMap.
+	Key string `json:"key"`
 }
 
 // AgentObservation are the observable fields of a Agent.
 type AgentObservation struct {
-	// Health *nextgen.V1AgentHealth `json:"health,omitempty"`
-	State string `json:"state"`
+	// State is the health of the agent as reported by Harness.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Identifier of the agent in Harness.
+	// +optional
+	Identifier string `json:"identifier,omitempty"`
+
+	// Health is the health of the agent's components as reported by
+	// Harness.
+	// +optional
+	Health *AgentHealth `json:"health,omitempty"`
+
+	// AccountIdentifier is the Harness account the agent was last observed
+	// in. It is used to detect the Agent being repointed at another account.
+	// +optional
+	AccountIdentifier string `json:"accountIdentifier,omitempty"`
+
+	// InClusterState is the readiness of the agent's in-cluster Deployment:
+	// Ready, NotReady or NotFound. It is only reported when in-cluster agent
+	// health is enabled and the agent has an in-cluster Deployment.
+	// +optional
+	InClusterState string `json:"inClusterState,omitempty"`
+
+	// LastError is the error Harness reports for an unhealthy agent, e.g.
+	// that an image could not be pulled. It is cleared once the agent is
+	// healthy again.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// CreatedAt is when the agent was created in Harness.
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// LastModifiedAt is when the agent was last modified in Harness.
+	// +optional
+	LastModifiedAt *metav1.Time `json:"lastModifiedAt,omitempty"`
+
+	// LastSyncedTime is when the provider last confirmed the agent matched
+	// the desired state. Unlike LastModifiedAt it reflects reconciliation,
+	// not changes made in Harness. It is refreshed at most every five
+	// minutes.
+	// +optional
+	LastSyncedTime *metav1.Time `json:"lastSyncedTime,omitempty"`
+
+	// DeletionRequestedAt is when Harness was last asked to delete the
+	// agent. The Agent reports the agent as being deleted until Harness no
+	// longer returns it, and the delete is only requested again once it has
+	// been pending for five minutes.
+	// +optional
+	DeletionRequestedAt *metav1.Time `json:"deletionRequestedAt,omitempty"`
+
+	// ServerVersion is the agent version Harness expects.
+	// +optional
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// ClientVersion is the version reported by the running agent.
+	// +optional
+	ClientVersion string `json:"clientVersion,omitempty"`
+
+	// UpgradeAvailable indicates Harness offers a newer version of the agent
+	// than the one installed, and the agent should be reinstalled.
+	// +optional
+	UpgradeAvailable bool `json:"upgradeAvailable,omitempty"`
+
+	// RepoCount is the number of repositories the agent manages.
+	// +optional
+	RepoCount *int32 `json:"repoCount,omitempty"`
+
+	// ClusterCount is the number of clusters the agent manages.
+	// +optional
+	ClusterCount *int32 `json:"clusterCount,omitempty"`
+
+	// CountsObservedAt is when RepoCount and ClusterCount were last
+	// observed. They are refreshed at most every thirty minutes.
+	// +optional
+	CountsObservedAt *metav1.Time `json:"countsObservedAt,omitempty"`
+
+	// ManagedByVersion is the version of the provider that last reconciled
+	// the agent.
+	// +optional
+	ManagedByVersion string `json:"managedByVersion,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the agent that
+	// have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+
+	// Explanation summarizes the controller's last reconcile decision. It is
+	// only recorded for Agents annotated with harness.crossplane.io/explain
+	// set to "true".
+	// +optional
+	Explanation string `json:"explanation,omitempty"`
 }
 
+// Types of agent.
+const (
+	// AgentTypeManagedArgo agents install and manage their own Argo CD.
+	AgentTypeManagedArgo = "MANAGED_ARGO"
+
+	// AgentTypeConnectedArgo agents connect to an existing Argo CD.
+	AgentTypeConnectedArgo = "CONNECTED_ARGO"
+)
+
+// Policies for changes to immutable fields.
+const (
+	// ImmutableChangeError reports changes to immutable fields as errors.
+	ImmutableChangeError = "Error"
+
+	// ImmutableChangeRecreate deletes and recreates an external resource
+	// whose immutable fields changed.
+	ImmutableChangeRecreate = "Recreate"
+)
+
 // A AgentSpec defines the desired state of a Agent.
 type AgentSpec struct {
 	xpv1.ResourceSpec `json:",inline"`
 	ForProvider       AgentParameters `json:"forProvider"`
+
+	// ConnectionDetailsFormat additionally publishes the connection details
+	// rendered as a dotenv file under the ".env" key, or as a JSON object
+	// under the "connection.json" key. The raw keys are always published.
+	// +kubebuilder:validation:Enum=Raw;Dotenv;JSON
+	// +kubebuilder:default=Raw
+	// +optional
+	ConnectionDetailsFormat string `json:"connectionDetailsFormat,omitempty"`
+
+	// OnImmutableChange determines what happens when a field that Harness
+	// does not allow to be updated, like the agent's organization, changes.
+	// Error, the default, reports the change and leaves the agent as it
+	// is. Recreate deletes the agent and creates it again with the new
+	// fields, which requires it to be reinstalled.
+	// +kubebuilder:validation:Enum=Error;Recreate
+	// +kubebuilder:default=Error
+	// +optional
+	OnImmutableChange string `json:"onImmutableChange,omitempty"`
 }
 
 // A AgentStatus represents the observed state of a Agent.
@@ -69,8 +332,14 @@ type AgentStatus struct {
 // A Agent is an example API type.
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="IDENTIFIER",type="string",JSONPath=".status.atProvider.identifier"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="LAST-SYNCED",type="date",JSONPath=".status.atProvider.lastSyncedTime"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="REPOS",type="integer",JSONPath=".status.atProvider.repoCount",priority=1
+// +kubebuilder:printcolumn:name="CLUSTERS",type="integer",JSONPath=".status.atProvider.clusterCount",priority=1
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.atProvider.managedByVersion",priority=1
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
 type Agent struct {
@@ -90,6 +359,21 @@ type AgentList struct {
 	Items           []Agent `json:"items"`
 }
 
+// GetConsecutiveFailures of this Agent.
+func (mg *Agent) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this Agent.
+func (mg *Agent) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// GetConnectionDetailsFormat of this Agent.
+func (mg *Agent) GetConnectionDetailsFormat() string {
+	return mg.Spec.ConnectionDetailsFormat
+}
+
 // Agent type metadata.
 var (
 	AgentKind             = reflect.TypeOf(Agent{}).Name()
@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// TestAgentObservationJSON guards the JSON keys of observed fields, which
+// must match the CRD schema for the API server to keep them.
+func TestAgentObservationJSON(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		o      AgentObservation
+		want   map[string]interface{}
+	}{
+		"State": {
+			reason: "The state should be serialized under the lowercase state key.",
+			o:      AgentObservation{State: "HEALTHY"},
+			want:   map[string]interface{}{"state": "HEALTHY"},
+		},
+		"NoState": {
+			reason: "An unknown state should be omitted.",
+			o:      AgentObservation{},
+			want:   map[string]interface{}{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			b, err := json.Marshal(tc.o)
+			if err != nil {
+				t.Fatalf("json.Marshal(...): %v", err)
+			}
+			got := map[string]interface{}{}
+			if err := json.Unmarshal(b, &got); err != nil {
+				t.Fatalf("json.Unmarshal(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\njson.Marshal(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Labels of Agents composed from an AgentClaim.
+const (
+	// LabelKeyClaimName is the name of the AgentClaim an Agent was composed
+	// from.
+	LabelKeyClaimName = "gitops.harness.crossplane.io/claim-name"
+
+	// LabelKeyClaimNamespace is the namespace of the AgentClaim an Agent was
+	// composed from.
+	LabelKeyClaimNamespace = "gitops.harness.crossplane.io/claim-namespace"
+)
+
+// AgentTemplateSpec defines the Harness scope and credentials of Agents
+// composed from claims that use the template.
+type AgentTemplateSpec struct {
+	// ProviderConfigReference specifies the ProviderConfig used to manage
+	// composed Agents.
+	// +kubebuilder:default={"name": "default"}
+	ProviderConfigReference *xpv1.Reference `json:"providerConfigRef,omitempty"`
+
+	// Account Identifier of composed Agents.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Organization Identifier of composed Agents.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+	// Project Identifier of composed Agents.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+
+	// Tags applied to composed Agents. A claim's tags take precedence over
+	// the template's tags with the same key.
+	// +kubebuilder:validation:MaxProperties=128
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AgentTemplate is a platform-managed template from which AgentClaims
+// compose Agents. It fills in the Harness scope and credentials so that
+// claim authors need not know them.
+// +kubebuilder:printcolumn:name="ACCOUNT",type="string",JSONPath=".spec.accountIdentifier"
+// +kubebuilder:printcolumn:name="ORG",type="string",JSONPath=".spec.orgIdentifier"
+// +kubebuilder:printcolumn:name="PROJECT",type="string",JSONPath=".spec.projectIdentifier"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,harness}
+type AgentTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec AgentTemplateSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+
+// AgentTemplateList contains a list of AgentTemplate
+type AgentTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AgentTemplate `json:"items"`
+}
+
+// AgentClaimSpec defines the desired state of an AgentClaim.
+type AgentClaimSpec struct {
+	// TemplateReference specifies the AgentTemplate the claimed Agent is
+	// composed from.
+	TemplateReference xpv1.Reference `json:"templateRef"`
+
+	// +optional
+	Identifier *string `json:"identifier,omitempty"`
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// +optional
+	Description *string `json:"description,omitempty"`
+	// +kubebuilder:validation:MaxProperties=128
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// AgentClaimStatus represents the observed state of an AgentClaim.
+type AgentClaimStatus struct {
+	xpv1.ConditionedStatus `json:",inline"`
+
+	// AgentReference references the Agent composed from the claim.
+	// +optional
+	AgentReference *xpv1.Reference `json:"agentRef,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AgentClaim claims an Agent composed from a platform-managed
+// AgentTemplate. Its Ready condition mirrors that of the composed Agent.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="TEMPLATE",type="string",JSONPath=".spec.templateRef.name"
+// +kubebuilder:printcolumn:name="AGENT",type="string",JSONPath=".status.agentRef.name"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,claim,harness}
+type AgentClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AgentClaimSpec   `json:"spec"`
+	Status AgentClaimStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// AgentClaimList contains a list of AgentClaim
+type AgentClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AgentClaim `json:"items"`
+}
+
+// GetCondition of this AgentClaim.
+func (c *AgentClaim) GetCondition(ct xpv1.ConditionType) xpv1.Condition {
+	return c.Status.GetCondition(ct)
+}
+
+// SetConditions of this AgentClaim.
+func (c *AgentClaim) SetConditions(cd ...xpv1.Condition) {
+	c.Status.SetConditions(cd...)
+}
+
+// AgentTemplate type metadata.
+var (
+	AgentTemplateKind             = reflect.TypeOf(AgentTemplate{}).Name()
+	AgentTemplateGroupKind        = schema.GroupKind{Group: Group, Kind: AgentTemplateKind}.String()
+	AgentTemplateKindAPIVersion   = AgentTemplateKind + "." + SchemeGroupVersion.String()
+	AgentTemplateGroupVersionKind = SchemeGroupVersion.WithKind(AgentTemplateKind)
+)
+
+// AgentClaim type metadata.
+var (
+	AgentClaimKind             = reflect.TypeOf(AgentClaim{}).Name()
+	AgentClaimGroupKind        = schema.GroupKind{Group: Group, Kind: AgentClaimKind}.String()
+	AgentClaimKindAPIVersion   = AgentClaimKind + "." + SchemeGroupVersion.String()
+	AgentClaimGroupVersionKind = SchemeGroupVersion.WithKind(AgentClaimKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&AgentTemplate{}, &AgentTemplateList{}, &AgentClaim{}, &AgentClaimList{})
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ApplicationParameters are the configurable fields of an Application.
+type ApplicationParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Organization Identifier for the Entity.
+	OrgIdentifier string `json:"orgIdentifier"`
+	// Project Identifier for the Entity.
+	ProjectIdentifier string `json:"projectIdentifier"`
+
+	// AgentIdentifier is the identifier of the GitOps agent that deploys
+	// the application.
+	AgentIdentifier string `json:"agentIdentifier"`
+	// Name of the application.
+	Name string `json:"name"`
+
+	// ClusterIdentifier is the identifier of the Harness cluster the
+	// application is deployed to.
+	// +optional
+	ClusterIdentifier *string `json:"clusterIdentifier,omitempty"`
+	// RepoIdentifier is the identifier of the Harness repository the
+	// application is sourced from.
+	// +optional
+	RepoIdentifier *string `json:"repoIdentifier,omitempty"`
+
+	// Project is the Argo CD project the application belongs to. The
+	// default project is used if omitted.
+	// +optional
+	Project *string `json:"project,omitempty"`
+
+	// Source of the application's manifests.
+	Source ApplicationSource `json:"source"`
+	// Destination the application's manifests are deployed to.
+	Destination ApplicationDestination `json:"destination"`
+}
+
+// An ApplicationSource is where an application's manifests are read from.
+type ApplicationSource struct {
+	// RepoURL is the URL of the repository holding the manifests.
+	RepoURL string `json:"repoURL"`
+	// Path of the manifests within the repository.
+	// +optional
+	Path *string `json:"path,omitempty"`
+	// TargetRevision is the commit, tag or branch to sync the application
+	// to. HEAD is used if omitted.
+	// +optional
+	TargetRevision *string `json:"targetRevision,omitempty"`
+}
+
+// An ApplicationDestination is where an application's manifests are
+// deployed to.
+type ApplicationDestination struct {
+	// Server is the URL of the API server of the cluster to deploy to.
+	Server string `json:"server"`
+	// Namespace to deploy namespaced resources to.
+	// +optional
+	Namespace *string `json:"namespace,omitempty"`
+}
+
+// ApplicationObservation are the observable fields of an Application.
+type ApplicationObservation struct {
+	// SyncStatus is whether the deployed resources match the application's
+	// source, e.g. Synced or OutOfSync.
+	// +optional
+	SyncStatus string `json:"syncStatus,omitempty"`
+
+	// Revision is the revision of the source the application was last
+	// compared to.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// HealthStatus is the health of the deployed resources, e.g. Healthy or
+	// Degraded.
+	// +optional
+	HealthStatus string `json:"healthStatus,omitempty"`
+
+	// HealthMessage explains the health status.
+	// +optional
+	HealthMessage string `json:"healthMessage,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the application
+	// that have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// An ApplicationSpec defines the desired state of an Application.
+type ApplicationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ApplicationParameters `json:"forProvider"`
+}
+
+// An ApplicationStatus represents the observed state of an Application.
+type ApplicationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ApplicationObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Application is an Argo CD application deployed by a GitOps agent. An
+// Application whose deployed resources drift from its source is synced.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SYNC-STATUS",type="string",JSONPath=".status.atProvider.syncStatus"
+// +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".status.atProvider.healthStatus"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type Application struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ApplicationSpec   `json:"spec"`
+	Status ApplicationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ApplicationList contains a list of Application
+type ApplicationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Application `json:"items"`
+}
+
+// GetConsecutiveFailures of this Application.
+func (mg *Application) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this Application.
+func (mg *Application) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// Application type metadata.
+var (
+	ApplicationKind             = reflect.TypeOf(Application{}).Name()
+	ApplicationGroupKind        = schema.GroupKind{Group: Group, Kind: ApplicationKind}.String()
+	ApplicationKindAPIVersion   = ApplicationKind + "." + SchemeGroupVersion.String()
+	ApplicationGroupVersionKind = SchemeGroupVersion.WithKind(ApplicationKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Application{}, &ApplicationList{})
+}
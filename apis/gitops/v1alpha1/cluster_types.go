@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ClusterParameters are the configurable fields of a Cluster.
+type ClusterParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Project Identifier for the Entity.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+	// Organization Identifier for the Entity.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+
+	// AgentIdentifier is the identifier of the GitOps agent the cluster is
+	// registered with.
+	AgentIdentifier string `json:"agentIdentifier"`
+	// Identifier of the cluster.
+	Identifier string `json:"identifier"`
+
+	// Server is the URL of the cluster's API server.
+	Server string `json:"server"`
+	// Name of the cluster. Harness names the cluster after its server if
+	// omitted.
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// Namespaces of the cluster the agent may deploy to. The agent may
+	// deploy to any namespace, and manage cluster scoped resources, if
+	// omitted.
+	// +optional
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// ConnectionType is how the agent connects to the cluster: IN_CLUSTER
+	// for the cluster the agent runs in, or SERVICE_ACCOUNT to authenticate
+	// with a bearer token.
+	// +kubebuilder:validation:Enum=IN_CLUSTER;SERVICE_ACCOUNT
+	// +optional
+	ConnectionType *string `json:"connectionType,omitempty"`
+	// BearerTokenSecretRef references the bearer token used to authenticate
+	// to the cluster when connecting with a service account.
+	// +optional
+	BearerTokenSecretRef *xpv1.SecretKeySelector `json:"bearerTokenSecretRef,omitempty"`
+}
+
+// ClusterObservation are the observable fields of a Cluster.
+type ClusterObservation struct {
+	// ConnectionStatus is the status of the agent's connection to the
+	// cluster as reported by Harness.
+	// +optional
+	ConnectionStatus string `json:"connectionStatus,omitempty"`
+
+	// ConnectionMessage explains the connection status.
+	// +optional
+	ConnectionMessage string `json:"connectionMessage,omitempty"`
+
+	// ServerVersion is the Kubernetes version of the cluster.
+	// +optional
+	ServerVersion string `json:"serverVersion,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the cluster that
+	// have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// A ClusterSpec defines the desired state of a Cluster.
+type ClusterSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ClusterParameters `json:"forProvider"`
+}
+
+// A ClusterStatus represents the observed state of a Cluster.
+type ClusterStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ClusterObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Cluster is a Kubernetes cluster registered with a GitOps agent as a
+// target to deploy applications to.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SERVER",type="string",JSONPath=".spec.forProvider.server",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type Cluster struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterSpec   `json:"spec"`
+	Status ClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterList contains a list of Cluster
+type ClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Cluster `json:"items"`
+}
+
+// GetConsecutiveFailures of this Cluster.
+func (mg *Cluster) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this Cluster.
+func (mg *Cluster) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// Cluster type metadata.
+var (
+	ClusterKind             = reflect.TypeOf(Cluster{}).Name()
+	ClusterGroupKind        = schema.GroupKind{Group: Group, Kind: ClusterKind}.String()
+	ClusterKindAPIVersion   = ClusterKind + "." + SchemeGroupVersion.String()
+	ClusterGroupVersionKind = SchemeGroupVersion.WithKind(ClusterKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Cluster{}, &ClusterList{})
+}
@@ -0,0 +1,452 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types.
+const (
+	// TypeVersionSkew indicates whether the version of a GitOps agent has
+	// drifted outside the range supported by Harness.
+	TypeVersionSkew xpv1.ConditionType = "VersionSkew"
+
+	// TypeThrottled indicates whether reconciles of a resource are being
+	// throttled because it exceeded its reconcile budget.
+	TypeThrottled xpv1.ConditionType = "Throttled"
+
+	// TypeReconcileFailing indicates whether a resource has failed to
+	// reconcile repeatedly.
+	TypeReconcileFailing xpv1.ConditionType = "ReconcileFailing"
+
+	// TypeInstallOutdated indicates whether Harness offers a newer version
+	// of an agent than the one installed.
+	TypeInstallOutdated xpv1.ConditionType = "InstallOutdated"
+
+	// TypeDeletionBlocked indicates a resource cannot be deleted because
+	// other Harness entities still reference it.
+	TypeDeletionBlocked xpv1.ConditionType = "DeletionBlocked"
+
+	// TypeProviderConfigChanged indicates whether a resource was repointed
+	// at a different Harness account since it was last observed.
+	TypeProviderConfigChanged xpv1.ConditionType = "ProviderConfigChanged"
+
+	// TypeUnreachable indicates whether the last test of a GitOps agent's
+	// connection to a repository failed.
+	TypeUnreachable xpv1.ConditionType = "Unreachable"
+
+	// TypeRecreating indicates whether an external resource was deleted so
+	// that it could be recreated with changed immutable fields.
+	TypeRecreating xpv1.ConditionType = "Recreating"
+
+	// TypeDegraded indicates whether any of a GitOps agent's components, or
+	// its connection to Harness, is reported as unhealthy.
+	TypeDegraded xpv1.ConditionType = "Degraded"
+
+	// TypeDryRun indicates whether a resource in dry run mode has changes
+	// that the provider would make, but did not.
+	TypeDryRun xpv1.ConditionType = "DryRun"
+)
+
+// Condition reasons. These are a stable API: alerts may key off them, so a
+// reason must never be renamed or reused with a different meaning.
+const (
+	// ReasonAvailable indicates the agent is healthy.
+	ReasonAvailable = xpv1.ReasonAvailable
+
+	// ReasonCreating indicates the agent has been created in Harness but
+	// has not yet been observed to be healthy.
+	ReasonCreating = xpv1.ReasonCreating
+
+	// ReasonAgentUnhealthy indicates Harness reports the agent as unhealthy.
+	ReasonAgentUnhealthy xpv1.ConditionReason = "AgentUnhealthy"
+
+	// ReasonAgentNotRunning indicates Harness reports the agent as healthy,
+	// but its in-cluster Deployment is not ready.
+	ReasonAgentNotRunning xpv1.ConditionReason = "AgentNotRunning"
+
+	// ReasonModuleNotLicensed indicates the Harness account does not hold a
+	// license for the module the resource requires.
+	ReasonModuleNotLicensed xpv1.ConditionReason = "ModuleNotLicensed"
+
+	// ReasonRateLimited indicates Harness rejected a request because the
+	// account's API rate limit was exceeded.
+	ReasonRateLimited xpv1.ConditionReason = "RateLimited"
+
+	// ReasonUnauthenticated indicates Harness rejected the credentials of
+	// the resource's ProviderConfig.
+	ReasonUnauthenticated xpv1.ConditionReason = "Unauthenticated"
+
+	// ReasonScopeMismatch indicates the resource may not use its
+	// ProviderConfig, or targets a scope it is not allowed to.
+	ReasonScopeMismatch xpv1.ConditionReason = "ScopeMismatch"
+
+	// ReasonVersionSkewed indicates the agent's version is outside the range
+	// supported by Harness.
+	ReasonVersionSkewed xpv1.ConditionReason = "UnsupportedVersionSkew"
+
+	// ReasonVersionsCompatible indicates the agent's version is supported by
+	// Harness.
+	ReasonVersionsCompatible xpv1.ConditionReason = "VersionsCompatible"
+
+	// ReasonReconcileBudgetExceeded indicates the resource reconciled more
+	// often than its reconcile budget allows.
+	ReasonReconcileBudgetExceeded xpv1.ConditionReason = "ReconcileBudgetExceeded"
+
+	// ReasonWithinReconcileBudget indicates the resource is reconciling
+	// within its reconcile budget.
+	ReasonWithinReconcileBudget xpv1.ConditionReason = "WithinReconcileBudget"
+
+	// ReasonRepeatedFailures indicates the resource failed to reconcile
+	// several times in a row.
+	ReasonRepeatedFailures xpv1.ConditionReason = "RepeatedReconcileFailures"
+
+	// ReasonReconcileRecovered indicates the resource reconciled
+	// successfully after failing repeatedly.
+	ReasonReconcileRecovered xpv1.ConditionReason = "ReconcileRecovered"
+
+	// ReasonUpgradeAvailable indicates Harness offers a newer version of
+	// the agent than the one installed.
+	ReasonUpgradeAvailable xpv1.ConditionReason = "UpgradeAvailable"
+
+	// ReasonInstallCurrent indicates the installed agent is the latest
+	// version Harness offers.
+	ReasonInstallCurrent xpv1.ConditionReason = "InstallCurrent"
+
+	// ReasonDeletionConflict indicates Harness refused to delete the
+	// resource because other entities still reference it.
+	ReasonDeletionConflict xpv1.ConditionReason = "DeletionConflict"
+
+	// ReasonAccountChanged indicates the resource was repointed at a
+	// different Harness account and is being re-observed there.
+	ReasonAccountChanged xpv1.ConditionReason = "AccountChanged"
+
+	// ReasonAccountObserved indicates the resource was observed in the
+	// Harness account it points at.
+	ReasonAccountObserved xpv1.ConditionReason = "AccountObserved"
+
+	// ReasonConnectionFailed indicates the GitOps agent could not connect to
+	// the repository.
+	ReasonConnectionFailed xpv1.ConditionReason = "ConnectionFailed"
+
+	// ReasonConnectionSuccessful indicates the GitOps agent connected to the
+	// repository.
+	ReasonConnectionSuccessful xpv1.ConditionReason = "ConnectionSuccessful"
+
+	// ReasonImmutableFieldChanged indicates the resource was deleted because
+	// an immutable field changed, and is being recreated.
+	ReasonImmutableFieldChanged xpv1.ConditionReason = "ImmutableFieldChanged"
+
+	// ReasonRecreated indicates the resource was recreated with its changed
+	// immutable fields.
+	ReasonRecreated xpv1.ConditionReason = "Recreated"
+
+	// ReasonAgentDegraded indicates Harness reports the agent as healthy,
+	// but one or more of its other components as unhealthy.
+	ReasonAgentDegraded xpv1.ConditionReason = "AgentDegraded"
+
+	// ReasonComponentsUnhealthy indicates Harness reports one or more of the
+	// agent's components, or its connection, as unhealthy.
+	ReasonComponentsUnhealthy xpv1.ConditionReason = "ComponentsUnhealthy"
+
+	// ReasonComponentsHealthy indicates Harness reports none of the agent's
+	// components, nor its connection, as unhealthy.
+	ReasonComponentsHealthy xpv1.ConditionReason = "ComponentsHealthy"
+
+	// ReasonChangePending indicates the provider would change the external
+	// resource, but did not because the resource is in dry run mode.
+	ReasonChangePending xpv1.ConditionReason = "ChangePending"
+
+	// ReasonNoChangePending indicates the provider has no change to make to
+	// the external resource that it did not make.
+	ReasonNoChangePending xpv1.ConditionReason = "NoChangePending"
+)
+
+// Unhealthy returns a condition that indicates Harness reports the agent as
+// unhealthy.
+func Unhealthy(msg string) xpv1.Condition {
+	return unavailable(ReasonAgentUnhealthy, msg)
+}
+
+// NotRunning returns a condition that indicates the agent's in-cluster
+// Deployment is not ready.
+func NotRunning(msg string) xpv1.Condition {
+	return unavailable(ReasonAgentNotRunning, msg)
+}
+
+// PartiallyHealthy returns a condition that indicates Harness reports the
+// agent as healthy, but one or more of its other components as unhealthy.
+func PartiallyHealthy(msg string) xpv1.Condition {
+	return unavailable(ReasonAgentDegraded, msg)
+}
+
+// ModuleNotLicensed returns a condition that indicates the Harness account
+// does not hold a license for the module the resource requires.
+func ModuleNotLicensed(msg string) xpv1.Condition {
+	return unavailable(ReasonModuleNotLicensed, msg)
+}
+
+// RateLimited returns a condition that indicates requests for the resource
+// are being rate limited by Harness.
+func RateLimited(msg string) xpv1.Condition {
+	return unavailable(ReasonRateLimited, msg)
+}
+
+// Unauthenticated returns a condition that indicates Harness rejected the
+// credentials used for the resource.
+func Unauthenticated(msg string) xpv1.Condition {
+	return unavailable(ReasonUnauthenticated, msg)
+}
+
+// ScopeMismatch returns a condition that indicates the resource may not be
+// managed with its ProviderConfig or in its scope.
+func ScopeMismatch(msg string) xpv1.Condition {
+	return unavailable(ReasonScopeMismatch, msg)
+}
+
+func unavailable(r xpv1.ConditionReason, msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               xpv1.TypeReady,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             r,
+		Message:            msg,
+	}
+}
+
+// VersionSkewed returns a condition that indicates the agent's version is
+// outside the range supported by Harness.
+func VersionSkewed(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionSkew,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonVersionSkewed,
+		Message:            msg,
+	}
+}
+
+// VersionsCompatible returns a condition that indicates the agent's version
+// is supported by Harness.
+func VersionsCompatible() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeVersionSkew,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonVersionsCompatible,
+	}
+}
+
+// Throttled returns a condition that indicates reconciles of the resource are
+// being throttled.
+func Throttled(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeThrottled,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReconcileBudgetExceeded,
+		Message:            msg,
+	}
+}
+
+// Unthrottled returns a condition that indicates reconciles of the resource
+// are no longer being throttled.
+func Unthrottled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeThrottled,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWithinReconcileBudget,
+	}
+}
+
+// ReconcileFailing returns a condition that indicates the resource has failed
+// to reconcile repeatedly.
+func ReconcileFailing(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeReconcileFailing,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRepeatedFailures,
+		Message:            msg,
+	}
+}
+
+// ReconcileRecovered returns a condition that indicates the resource
+// reconciled successfully after failing repeatedly.
+func ReconcileRecovered() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeReconcileFailing,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReconcileRecovered,
+	}
+}
+
+// InstallOutdated returns a condition that indicates Harness offers a newer
+// version of the agent than the one installed.
+func InstallOutdated(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeInstallOutdated,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUpgradeAvailable,
+		Message:            msg,
+	}
+}
+
+// InstallCurrent returns a condition that indicates the installed agent is
+// the latest version Harness offers.
+func InstallCurrent() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeInstallOutdated,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonInstallCurrent,
+	}
+}
+
+// DeletionBlocked returns a condition that indicates Harness refused to
+// delete the resource because other entities still reference it.
+func DeletionBlocked(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDeletionBlocked,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonDeletionConflict,
+		Message:            msg,
+	}
+}
+
+// ProviderConfigChanged returns a condition that indicates the resource was
+// repointed at a different Harness account and is being re-observed there.
+func ProviderConfigChanged(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderConfigChanged,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAccountChanged,
+		Message:            msg,
+	}
+}
+
+// ProviderConfigSettled returns a condition that indicates the resource was
+// observed in the Harness account it points at.
+func ProviderConfigSettled() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProviderConfigChanged,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonAccountObserved,
+	}
+}
+
+// Unreachable returns a condition that indicates the GitOps agent could not
+// connect to the repository when it was last tested.
+func Unreachable(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeUnreachable,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonConnectionFailed,
+		Message:            msg,
+	}
+}
+
+// Reachable returns a condition that indicates the GitOps agent connected to
+// the repository when it was last tested.
+func Reachable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeUnreachable,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonConnectionSuccessful,
+	}
+}
+
+// Recreating returns a condition that indicates the external resource was
+// deleted because an immutable field changed, and is being recreated.
+func Recreating(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRecreating,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonImmutableFieldChanged,
+		Message:            msg,
+	}
+}
+
+// Recreated returns a condition that indicates the external resource was
+// recreated with its changed immutable fields.
+func Recreated() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRecreating,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonRecreated,
+	}
+}
+
+// Degraded returns a condition that indicates Harness reports one or more of
+// the agent's components, or its connection, as unhealthy.
+func Degraded(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDegraded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonComponentsUnhealthy,
+		Message:            msg,
+	}
+}
+
+// NotDegraded returns a condition that indicates Harness reports none of the
+// agent's components, nor its connection, as unhealthy.
+func NotDegraded() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDegraded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonComponentsHealthy,
+	}
+}
+
+// ChangePending returns a condition that indicates the provider would change
+// the external resource, but did not because the resource is in dry run mode.
+func ChangePending(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDryRun,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonChangePending,
+		Message:            msg,
+	}
+}
+
+// NoChangePending returns a condition that indicates the provider has no
+// change to make to the external resource that it did not make.
+func NoChangePending() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeDryRun,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonNoChangePending,
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TestReasonsAreStable guards against renaming condition reasons, which users
+// may have written alerts against.
+func TestReasonsAreStable(t *testing.T) {
+	cases := map[string]struct {
+		got  xpv1.ConditionReason
+		want string
+	}{
+		"Available":             {got: ReasonAvailable, want: "Available"},
+		"Creating":              {got: ReasonCreating, want: "Creating"},
+		"AgentUnhealthy":        {got: ReasonAgentUnhealthy, want: "AgentUnhealthy"},
+		"AgentNotRunning":       {got: ReasonAgentNotRunning, want: "AgentNotRunning"},
+		"ModuleNotLicensed":     {got: ReasonModuleNotLicensed, want: "ModuleNotLicensed"},
+		"RateLimited":           {got: ReasonRateLimited, want: "RateLimited"},
+		"Unauthenticated":       {got: ReasonUnauthenticated, want: "Unauthenticated"},
+		"ScopeMismatch":         {got: ReasonScopeMismatch, want: "ScopeMismatch"},
+		"VersionSkewed":         {got: ReasonVersionSkewed, want: "UnsupportedVersionSkew"},
+		"VersionsCompatible":    {got: ReasonVersionsCompatible, want: "VersionsCompatible"},
+		"BudgetExceeded":        {got: ReasonReconcileBudgetExceeded, want: "ReconcileBudgetExceeded"},
+		"WithinBudget":          {got: ReasonWithinReconcileBudget, want: "WithinReconcileBudget"},
+		"RepeatedFailures":      {got: ReasonRepeatedFailures, want: "RepeatedReconcileFailures"},
+		"Recovered":             {got: ReasonReconcileRecovered, want: "ReconcileRecovered"},
+		"UpgradeAvailable":      {got: ReasonUpgradeAvailable, want: "UpgradeAvailable"},
+		"InstallCurrent":        {got: ReasonInstallCurrent, want: "InstallCurrent"},
+		"DeletionConflict":      {got: ReasonDeletionConflict, want: "DeletionConflict"},
+		"AccountChanged":        {got: ReasonAccountChanged, want: "AccountChanged"},
+		"AccountObserved":       {got: ReasonAccountObserved, want: "AccountObserved"},
+		"ConnectionFailed":      {got: ReasonConnectionFailed, want: "ConnectionFailed"},
+		"ConnectionSuccessful":  {got: ReasonConnectionSuccessful, want: "ConnectionSuccessful"},
+		"ImmutableFieldChanged": {got: ReasonImmutableFieldChanged, want: "ImmutableFieldChanged"},
+		"Recreated":             {got: ReasonRecreated, want: "Recreated"},
+		"AgentDegraded":         {got: ReasonAgentDegraded, want: "AgentDegraded"},
+		"ComponentsUnhealthy":   {got: ReasonComponentsUnhealthy, want: "ComponentsUnhealthy"},
+		"ComponentsHealthy":     {got: ReasonComponentsHealthy, want: "ComponentsHealthy"},
+		"ChangePending":         {got: ReasonChangePending, want: "ChangePending"},
+		"NoChangePending":       {got: ReasonNoChangePending, want: "NoChangePending"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, string(tc.got)); diff != "" {
+				t.Errorf("reason changed: -want, +got:\n%s", diff)
+			}
+		})
+	}
+}
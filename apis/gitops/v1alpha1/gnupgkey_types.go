@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// GnuPGKeyParameters are the configurable fields of a GnuPGKey.
+type GnuPGKeyParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Project Identifier for the Entity.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+	// Organization Identifier for the Entity.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+
+	// AgentIdentifier is the identifier of the GitOps agent the key is
+	// registered with.
+	AgentIdentifier string `json:"agentIdentifier"`
+
+	// PublicKey is the ASCII armored GnuPG public key used to verify signed
+	// commits.
+	PublicKey string `json:"publicKey"`
+}
+
+// GnuPGKeyObservation are the observable fields of a GnuPGKey.
+type GnuPGKeyObservation struct {
+	// KeyID is the ID Harness assigned the key. It is also recorded as the
+	// GnuPGKey's external name.
+	// +optional
+	KeyID string `json:"keyID,omitempty"`
+
+	// Fingerprint of the key.
+	// +optional
+	Fingerprint string `json:"fingerprint,omitempty"`
+
+	// Owner of the key.
+	// +optional
+	Owner string `json:"owner,omitempty"`
+
+	// Trust is the trust level of the key.
+	// +optional
+	Trust string `json:"trust,omitempty"`
+
+	// SubType is the key's algorithm, e.g. rsa4096.
+	// +optional
+	SubType string `json:"subType,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the key that have
+	// failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// A GnuPGKeySpec defines the desired state of a GnuPGKey.
+type GnuPGKeySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       GnuPGKeyParameters `json:"forProvider"`
+}
+
+// A GnuPGKeyStatus represents the observed state of a GnuPGKey.
+type GnuPGKeyStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          GnuPGKeyObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A GnuPGKey is a GnuPG public key a GitOps agent uses to verify signed
+// commits.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="KEY-ID",type="string",JSONPath=".status.atProvider.keyID"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type GnuPGKey struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GnuPGKeySpec   `json:"spec"`
+	Status GnuPGKeyStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// GnuPGKeyList contains a list of GnuPGKey
+type GnuPGKeyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GnuPGKey `json:"items"`
+}
+
+// GetConsecutiveFailures of this GnuPGKey.
+func (mg *GnuPGKey) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this GnuPGKey.
+func (mg *GnuPGKey) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// GnuPGKey type metadata.
+var (
+	GnuPGKeyKind             = reflect.TypeOf(GnuPGKey{}).Name()
+	GnuPGKeyGroupKind        = schema.GroupKind{Group: Group, Kind: GnuPGKeyKind}.String()
+	GnuPGKeyKindAPIVersion   = GnuPGKeyKind + "." + SchemeGroupVersion.String()
+	GnuPGKeyGroupVersionKind = SchemeGroupVersion.WithKind(GnuPGKeyKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&GnuPGKey{}, &GnuPGKeyList{})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// +kubebuilder:object:root=true
+
+// A NamespacedAgent is a namespaced variant of an Agent, allowing RBAC to
+// isolate tenants sharing a cluster. It may only use a ProviderConfig that
+// allows its namespace. The objects it references, like its connection
+// secret, must be in its own namespace. An empty namespace refers to its own.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STATE",type="string",JSONPath=".status.atProvider.state"
+// +kubebuilder:printcolumn:name="IDENTIFIER",type="string",JSONPath=".status.atProvider.identifier"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="LAST-SYNCED",type="date",JSONPath=".status.atProvider.lastSyncedTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:printcolumn:name="REPOS",type="integer",JSONPath=".status.atProvider.repoCount",priority=1
+// +kubebuilder:printcolumn:name="CLUSTERS",type="integer",JSONPath=".status.atProvider.clusterCount",priority=1
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.atProvider.managedByVersion",priority=1
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Namespaced,categories={crossplane,managed,harness}
+type NamespacedAgent struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AgentSpec   `json:"spec"`
+	Status AgentStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// NamespacedAgentList contains a list of NamespacedAgent
+type NamespacedAgentList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespacedAgent `json:"items"`
+}
+
+// GetConsecutiveFailures of this NamespacedAgent.
+func (mg *NamespacedAgent) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this NamespacedAgent.
+func (mg *NamespacedAgent) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// GetConnectionDetailsFormat of this NamespacedAgent.
+func (mg *NamespacedAgent) GetConnectionDetailsFormat() string {
+	return mg.Spec.ConnectionDetailsFormat
+}
+
+// ForeignReferences returns the fields of this NamespacedAgent that reference
+// an object in another namespace. An Agent may reference objects in any
+// namespace, but a tenant must not be able to use a NamespacedAgent to read
+// or write those of another.
+func (mg *NamespacedAgent) ForeignReferences() []string {
+	ns := mg.GetNamespace()
+	foreign := func(refNamespace string) bool { return refNamespace != "" && refNamespace != ns }
+
+	var fields []string
+	if ref := mg.Spec.WriteConnectionSecretToReference; ref != nil && foreign(ref.Namespace) {
+		fields = append(fields, "spec.writeConnectionSecretToRef")
+	}
+	if ref := mg.Spec.ForProvider.DescriptionFrom; ref != nil && foreign(ref.Namespace) {
+		fields = append(fields, "spec.forProvider.descriptionFrom")
+	}
+	if ref := mg.Spec.ForProvider.InClusterDeployment; ref != nil && foreign(ref.Namespace) {
+		fields = append(fields, "spec.forProvider.inClusterDeployment")
+	}
+	return fields
+}
+
+// NamespacedAgent type metadata.
+var (
+	NamespacedAgentKind             = reflect.TypeOf(NamespacedAgent{}).Name()
+	NamespacedAgentGroupKind        = schema.GroupKind{Group: Group, Kind: NamespacedAgentKind}.String()
+	NamespacedAgentKindAPIVersion   = NamespacedAgentKind + "." + SchemeGroupVersion.String()
+	NamespacedAgentGroupVersionKind = SchemeGroupVersion.WithKind(NamespacedAgentKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&NamespacedAgent{}, &NamespacedAgentList{})
+}
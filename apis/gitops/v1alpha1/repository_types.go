@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RepositoryParameters are the configurable fields of a Repository.
+type RepositoryParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Project Identifier for the Entity.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+	// Organization Identifier for the Entity.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+
+	// AgentIdentifier is the identifier of the GitOps agent the repository
+	// is added to.
+	AgentIdentifier string `json:"agentIdentifier"`
+	// Identifier of the repository.
+	Identifier string `json:"identifier"`
+
+	// URL of the repository.
+	URL string `json:"url"`
+	// Type of the repository. Git is assumed if omitted.
+	// +kubebuilder:validation:Enum=git;helm
+	// +optional
+	Type *string `json:"type,omitempty"`
+	// Name of the repository. Required for Helm repositories.
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// ConnectionType of the repository, e.g. HTTPS, HTTPS_ANONYMOUS or SSH.
+	// +optional
+	ConnectionType *string `json:"connectionType,omitempty"`
+	// Insecure skips verification of the repository server's certificate or
+	// host key.
+	// +optional
+	Insecure *bool `json:"insecure,omitempty"`
+	// Project is the Argo CD project the repository is scoped to.
+	// +optional
+	Project *string `json:"project,omitempty"`
+
+	// UsernameSecretRef references the username used to authenticate to
+	// the repository.
+	// +optional
+	UsernameSecretRef *xpv1.SecretKeySelector `json:"usernameSecretRef,omitempty"`
+	// PasswordSecretRef references the password or token used to
+	// authenticate to the repository.
+	// +optional
+	PasswordSecretRef *xpv1.SecretKeySelector `json:"passwordSecretRef,omitempty"`
+	// SSHPrivateKeySecretRef references the SSH private key used to
+	// authenticate to a Git repository.
+	// +optional
+	SSHPrivateKeySecretRef *xpv1.SecretKeySelector `json:"sshPrivateKeySecretRef,omitempty"`
+}
+
+// RepositoryObservation are the observable fields of a Repository.
+type RepositoryObservation struct {
+	// ConnectionStatus is the result of the last test of the agent's
+	// connection to the repository.
+	// +optional
+	ConnectionStatus string `json:"connectionStatus,omitempty"`
+
+	// ConnectionMessage explains the result of the last connection test.
+	// +optional
+	ConnectionMessage string `json:"connectionMessage,omitempty"`
+
+	// LastConnectionTestTime is when the connection was last tested.
+	// +optional
+	LastConnectionTestTime *metav1.Time `json:"lastConnectionTestTime,omitempty"`
+
+	// LastSuccessfulConnectionTestTime is when the connection was last
+	// tested successfully. A successful test is repeated at most hourly.
+	// +optional
+	LastSuccessfulConnectionTestTime *metav1.Time `json:"lastSuccessfulConnectionTestTime,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the repository
+	// that have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// A RepositorySpec defines the desired state of a Repository.
+type RepositorySpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RepositoryParameters `json:"forProvider"`
+}
+
+// A RepositoryStatus represents the observed state of a Repository.
+type RepositoryStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RepositoryObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Repository is a Git or Helm repository added to a GitOps agent. The
+// agent's connection to it is tested whenever it is created or updated.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="UNREACHABLE",type="string",JSONPath=".status.conditions[?(@.type=='Unreachable')].status"
+// +kubebuilder:printcolumn:name="URL",type="string",JSONPath=".spec.forProvider.url",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type Repository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositorySpec   `json:"spec"`
+	Status RepositoryStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryList contains a list of Repository
+type RepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Repository `json:"items"`
+}
+
+// GetConsecutiveFailures of this Repository.
+func (mg *Repository) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this Repository.
+func (mg *Repository) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// Repository type metadata.
+var (
+	RepositoryKind             = reflect.TypeOf(Repository{}).Name()
+	RepositoryGroupKind        = schema.GroupKind{Group: Group, Kind: RepositoryKind}.String()
+	RepositoryKindAPIVersion   = RepositoryKind + "." + SchemeGroupVersion.String()
+	RepositoryGroupVersionKind = SchemeGroupVersion.WithKind(RepositoryKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Repository{}, &RepositoryList{})
+}
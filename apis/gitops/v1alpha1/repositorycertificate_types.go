@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RepositoryCertificateParameters are the configurable fields of a
+// RepositoryCertificate.
+type RepositoryCertificateParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Project Identifier for the Entity.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+	// Organization Identifier for the Entity.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+
+	// AgentIdentifier is the identifier of the GitOps agent the certificate
+	// is registered with.
+	AgentIdentifier string `json:"agentIdentifier"`
+
+	// ServerName is the host name of the repository server the certificate
+	// belongs to.
+	ServerName string `json:"serverName"`
+	// CertType is the type of the certificate: https for a TLS certificate,
+	// or ssh for an SSH known host key.
+	// +kubebuilder:validation:Enum=https;ssh
+	CertType string `json:"certType"`
+	// CertSubType is the type of an SSH known host key, e.g. ssh-rsa or
+	// ecdsa-sha2-nistp256. It is ignored for TLS certificates.
+	// +optional
+	CertSubType *string `json:"certSubType,omitempty"`
+	// CertData is the PEM encoded TLS certificate, or the SSH known host key.
+	CertData string `json:"certData"`
+}
+
+// RepositoryCertificateObservation are the observable fields of a
+// RepositoryCertificate.
+type RepositoryCertificateObservation struct {
+	// CertInfo describes the certificate, e.g. its fingerprint or subject,
+	// as reported by Harness.
+	// +optional
+	CertInfo string `json:"certInfo,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the certificate
+	// that have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// A RepositoryCertificateSpec defines the desired state of a
+// RepositoryCertificate.
+type RepositoryCertificateSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RepositoryCertificateParameters `json:"forProvider"`
+}
+
+// A RepositoryCertificateStatus represents the observed state of a
+// RepositoryCertificate.
+type RepositoryCertificateStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RepositoryCertificateObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A RepositoryCertificate is a TLS certificate or SSH known host key a GitOps
+// agent trusts when connecting to a repository server, for example a private
+// Git server with a certificate signed by a custom CA.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SERVER",type="string",JSONPath=".spec.forProvider.serverName",priority=1
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.forProvider.certType",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type RepositoryCertificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RepositoryCertificateSpec   `json:"spec"`
+	Status RepositoryCertificateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RepositoryCertificateList contains a list of RepositoryCertificate
+type RepositoryCertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RepositoryCertificate `json:"items"`
+}
+
+// GetConsecutiveFailures of this RepositoryCertificate.
+func (mg *RepositoryCertificate) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this RepositoryCertificate.
+func (mg *RepositoryCertificate) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// RepositoryCertificate type metadata.
+var (
+	RepositoryCertificateKind             = reflect.TypeOf(RepositoryCertificate{}).Name()
+	RepositoryCertificateGroupKind        = schema.GroupKind{Group: Group, Kind: RepositoryCertificateKind}.String()
+	RepositoryCertificateKindAPIVersion   = RepositoryCertificateKind + "." + SchemeGroupVersion.String()
+	RepositoryCertificateGroupVersionKind = SchemeGroupVersion.WithKind(RepositoryCertificateKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&RepositoryCertificate{}, &RepositoryCertificateList{})
+}
@@ -21,6 +21,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 
 	gitopsv1alpha1 "github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	platformv1alpha1 "github.com/crossplane/provider-harness/apis/platform/v1alpha1"
 	harnessv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
 )
 
@@ -29,6 +30,7 @@ func init() {
 	AddToSchemes = append(AddToSchemes,
 		harnessv1alpha1.SchemeBuilder.AddToScheme,
 		gitopsv1alpha1.SchemeBuilder.AddToScheme,
+		platformv1alpha1.SchemeBuilder.AddToScheme,
 	)
 }
 
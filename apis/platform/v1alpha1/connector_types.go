@@ -0,0 +1,223 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Types of connectors.
+const (
+	ConnectorTypeGit            = "Git"
+	ConnectorTypeDockerRegistry = "DockerRegistry"
+	ConnectorTypeAws            = "Aws"
+)
+
+// Types of connector authentication.
+const (
+	ConnectorAuthAnonymous           = "Anonymous"
+	ConnectorAuthUsernamePassword    = "UsernamePassword"
+	ConnectorAuthSSHKey              = "SSHKey"
+	ConnectorAuthAccessKey           = "AccessKey"
+	ConnectorAuthInheritFromDelegate = "InheritFromDelegate"
+)
+
+// ConnectorParameters are the configurable fields of a Connector.
+type ConnectorParameters struct {
+	// Account Identifier for the Entity.
+	AccountIdentifier string `json:"accountIdentifier"`
+	// Project Identifier for the Entity.
+	// +optional
+	ProjectIdentifier *string `json:"projectIdentifier,omitempty"`
+	// Organization Identifier for the Entity.
+	// +optional
+	OrgIdentifier *string `json:"orgIdentifier,omitempty"`
+
+	// Identifier of the connector.
+	Identifier string `json:"identifier"`
+	// Name of the connector. The identifier is used if omitted.
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// Description of the connector.
+	// +optional
+	Description *string `json:"description,omitempty"`
+	// Tags of the connector.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Type of the connector.
+	// +kubebuilder:validation:Enum=Git;DockerRegistry;Aws
+	Type string `json:"type"`
+
+	// URL of the Git repository or account, or of the Docker registry.
+	// Required for Git and Docker registry connectors.
+	// +optional
+	URL *string `json:"url,omitempty"`
+	// ConnectionType of a Git connector; whether it connects to a single
+	// repository or to all repositories of an account. Repo is assumed if
+	// omitted.
+	// +kubebuilder:validation:Enum=Repo;Account
+	// +optional
+	ConnectionType *string `json:"connectionType,omitempty"`
+	// ValidationRepo is the repository an Account Git connector's
+	// connection is tested against.
+	// +optional
+	ValidationRepo *string `json:"validationRepo,omitempty"`
+	// RegistryType of a Docker registry connector. Other is assumed if
+	// omitted.
+	// +kubebuilder:validation:Enum=DockerHub;Harbor;Quay;Other
+	// +optional
+	RegistryType *string `json:"registryType,omitempty"`
+
+	// Auth configures how the connector authenticates.
+	Auth ConnectorAuth `json:"auth"`
+
+	// DelegateSelectors select the delegates the connector connects
+	// through.
+	// +optional
+	DelegateSelectors []string `json:"delegateSelectors,omitempty"`
+}
+
+// ConnectorAuth configures how a connector authenticates. Credentials are
+// references to Harness secrets, e.g. account.github-token, rather than
+// Kubernetes secrets.
+type ConnectorAuth struct {
+	// Type of authentication. Git connectors support UsernamePassword and
+	// SSHKey, Docker registry connectors Anonymous and UsernamePassword, and
+	// AWS connectors AccessKey and InheritFromDelegate.
+	// +kubebuilder:validation:Enum=Anonymous;UsernamePassword;SSHKey;AccessKey;InheritFromDelegate
+	Type string `json:"type"`
+
+	// Username used to authenticate, if it is not a secret.
+	// +optional
+	Username *string `json:"username,omitempty"`
+	// UsernameRef references the Harness secret holding the username used
+	// to authenticate.
+	// +optional
+	UsernameRef *string `json:"usernameRef,omitempty"`
+	// PasswordRef references the Harness secret holding the password or
+	// token used to authenticate.
+	// +optional
+	PasswordRef *string `json:"passwordRef,omitempty"`
+	// SSHKeyRef references the Harness SSH credential used to authenticate.
+	// +optional
+	SSHKeyRef *string `json:"sshKeyRef,omitempty"`
+
+	// AccessKey is the AWS access key ID, if it is not a secret.
+	// +optional
+	AccessKey *string `json:"accessKey,omitempty"`
+	// AccessKeyRef references the Harness secret holding the AWS access key
+	// ID.
+	// +optional
+	AccessKeyRef *string `json:"accessKeyRef,omitempty"`
+	// SecretKeyRef references the Harness secret holding the AWS secret
+	// access key.
+	// +optional
+	SecretKeyRef *string `json:"secretKeyRef,omitempty"`
+}
+
+// ConnectorObservation are the observable fields of a Connector.
+type ConnectorObservation struct {
+	// ConnectivityStatus is the result of the last test of the connector's
+	// connection, as reported by Harness.
+	// +optional
+	ConnectivityStatus string `json:"connectivityStatus,omitempty"`
+
+	// ConnectivityError summarizes why the last connection test failed.
+	// +optional
+	ConnectivityError string `json:"connectivityError,omitempty"`
+
+	// CreatedAt is when the connector was created in Harness.
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// LastModifiedAt is when the connector was last modified in Harness.
+	// +optional
+	LastModifiedAt *metav1.Time `json:"lastModifiedAt,omitempty"`
+
+	// ConsecutiveFailures is the number of reconciles of the connector that
+	// have failed in a row.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+}
+
+// A ConnectorSpec defines the desired state of a Connector.
+type ConnectorSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ConnectorParameters `json:"forProvider"`
+}
+
+// A ConnectorStatus represents the observed state of a Connector.
+type ConnectorStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ConnectorObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Connector connects Harness to a Git repository, a Docker registry, or a
+// cloud provider, so that pipelines and GitOps can use it.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.forProvider.type"
+// +kubebuilder:printcolumn:name="CONNECTIVITY",type="string",JSONPath=".status.atProvider.connectivityStatus",priority=1
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,harness}
+type Connector struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ConnectorSpec   `json:"spec"`
+	Status ConnectorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ConnectorList contains a list of Connector
+type ConnectorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Connector `json:"items"`
+}
+
+// GetConsecutiveFailures of this Connector.
+func (mg *Connector) GetConsecutiveFailures() int64 {
+	return mg.Status.AtProvider.ConsecutiveFailures
+}
+
+// SetConsecutiveFailures of this Connector.
+func (mg *Connector) SetConsecutiveFailures(n int64) {
+	mg.Status.AtProvider.ConsecutiveFailures = n
+}
+
+// Connector type metadata.
+var (
+	ConnectorKind             = reflect.TypeOf(Connector{}).Name()
+	ConnectorGroupKind        = schema.GroupKind{Group: Group, Kind: ConnectorKind}.String()
+	ConnectorKindAPIVersion   = ConnectorKind + "." + SchemeGroupVersion.String()
+	ConnectorGroupVersionKind = SchemeGroupVersion.WithKind(ConnectorKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Connector{}, &ConnectorList{})
+}
@@ -29,15 +29,178 @@ import (
 type ProviderConfigSpec struct {
 	// Credentials required to authenticate to this provider.
 	Credentials ProviderCredentials `json:"credentials"`
+
+	// Region of the Harness SaaS cluster hosting the account. Each region
+	// maps to a known Harness base URL. Ignored when BaseURL is set.
+	// +kubebuilder:validation:Enum=prod1;prod2;prod3;eu
+	// +kubebuilder:default=prod1
+	// +optional
+	Region *string `json:"region,omitempty"`
+
+	// BaseURL of the Harness API. Overrides the URL derived from Region, for
+	// example to reach a self-managed Harness installation.
+	// +optional
+	BaseURL *string `json:"baseURL,omitempty"`
+
+	// AllowedNamespaces lists the namespaces whose namespaced managed
+	// resources may use this ProviderConfig. Namespaced managed resources
+	// may not use a ProviderConfig that does not list their namespace.
+	// Cluster scoped managed resources are not affected.
+	// +optional
+	AllowedNamespaces []string `json:"allowedNamespaces,omitempty"`
+
+	// ImpersonatePrincipal is sent with every request as the principal the
+	// provider acts on behalf of, for gateways that audit requests by the
+	// acting principal. It complements, rather than replaces, API key
+	// authentication.
+	// +kubebuilder:validation:MinLength=1
+	// +optional
+	ImpersonatePrincipal *string `json:"impersonatePrincipal,omitempty"`
+
+	// DefaultTags are applied to every Harness entity managed using this
+	// ProviderConfig. Tags set on a managed resource take precedence over
+	// default tags with the same key.
+	// +kubebuilder:validation:MaxProperties=128
+	// +optional
+	DefaultTags map[string]string `json:"defaultTags,omitempty"`
+
+	// DefaultScope is the Harness account, organization and project of
+	// Agents managed using this ProviderConfig that do not specify their
+	// own. Identifiers set on an Agent, even to an empty string, take
+	// precedence. The defaults are written to an Agent's spec once its agent
+	// is observed, so changing them does not move existing agents.
+	// +optional
+	DefaultScope *Scope `json:"defaultScope,omitempty"`
+
+	// DeduplicateReads lets concurrent identical reads of a Harness entity,
+	// for example a connector referenced by many managed resources, share a
+	// single request.
+	// +kubebuilder:default=true
+	// +optional
+	DeduplicateReads *bool `json:"deduplicateReads,omitempty"`
+
+	// HTTPClient configures how requests to the Harness API are retried,
+	// timed out and proxied.
+	// +optional
+	HTTPClient *HTTPClientConfig `json:"httpClient,omitempty"`
+
+	// TLS configures how the certificate of the Harness API is verified,
+	// for example to trust the private CA of a self-managed installation.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+}
+
+// A Scope identifies a Harness account, and optionally an organization and
+// project within it.
+type Scope struct {
+	// AccountIdentifier of the account.
+	// +optional
+	AccountIdentifier string `json:"accountIdentifier,omitempty"`
+
+	// OrgIdentifier of the organization. It only applies to Agents that
+	// specify neither an organization nor a project.
+	// +optional
+	OrgIdentifier string `json:"orgIdentifier,omitempty"`
+
+	// ProjectIdentifier of the project, which must belong to the
+	// organization. It only applies to Agents in that organization that do
+	// not specify a project.
+	// +optional
+	ProjectIdentifier string `json:"projectIdentifier,omitempty"`
+}
+
+// TLSConfig configures how the certificate of the Harness API is verified.
+type TLSConfig struct {
+	// CABundle is a PEM encoded bundle of CA certificates to trust in
+	// addition to the system's.
+	// +optional
+	CABundle *string `json:"caBundle,omitempty"`
+
+	// CABundleSecretRef references a key of a Secret holding a PEM encoded
+	// bundle of CA certificates to trust in addition to the system's.
+	// CABundle takes precedence when both are set.
+	// +optional
+	CABundleSecretRef *xpv1.SecretKeySelector `json:"caBundleSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables verification of the Harness API's
+	// certificate. It makes requests vulnerable to interception, and should
+	// only be used for testing.
+	// +optional
+	InsecureSkipVerify *bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// HTTPClientConfig configures the HTTP client used to call the Harness API.
+type HTTPClientConfig struct {
+	// RetryMax is the maximum number of times a failed request is retried.
+	// Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	RetryMax *int `json:"retryMax,omitempty"`
+
+	// RetryWaitMin is the minimum time to wait before retrying a failed
+	// request. Defaults to 5s.
+	// +optional
+	RetryWaitMin *metav1.Duration `json:"retryWaitMin,omitempty"`
+
+	// RetryWaitMax is the maximum time to wait before retrying a failed
+	// request. Defaults to 10s.
+	// +optional
+	RetryWaitMax *metav1.Duration `json:"retryWaitMax,omitempty"`
+
+	// Timeout of each request, including reading its response. Defaults to
+	// 10s.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// ProxyURL of the proxy requests are sent through, e.g.
+	// http://proxy.example.org:3128. Defaults to the proxy configured by the
+	// provider's HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables.
+	// +optional
+	ProxyURL *string `json:"proxyURL,omitempty"`
 }
 
+// CredentialsSourceSecretStore reads credentials from an external secret
+// store, such as Vault, configured by a StoreConfig. It requires external
+// secret stores to be enabled.
+const CredentialsSourceSecretStore xpv1.CredentialsSource = "SecretStore"
+
 // ProviderCredentials required to authenticate.
 type ProviderCredentials struct {
 	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem
+	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem;SecretStore
 	Source xpv1.CredentialsSource `json:"source"`
 
 	xpv1.CommonCredentialSelectors `json:",inline"`
+
+	// SecretStore selects the credentials in an external secret store. It is
+	// required when the source is SecretStore.
+	// +optional
+	SecretStore *SecretStoreSelector `json:"secretStore,omitempty"`
+
+	// APIKeyPath is the path of a file containing the Harness API key, for
+	// example one mounted by a CSI secret driver. Unlike the Filesystem
+	// source it holds the bare API key, and does not require a Secret to
+	// exist. The file is read on every connection so the key may be rotated
+	// in place. Takes precedence over Source when set.
+	// +optional
+	APIKeyPath *string `json:"apiKeyPath,omitempty"`
+}
+
+// A SecretStoreSelector selects a key of a secret in an external secret store.
+type SecretStoreSelector struct {
+	// StoreConfigRef references the StoreConfig of the secret store.
+	StoreConfigRef xpv1.Reference `json:"storeConfigRef"`
+
+	// Name of the secret.
+	Name string `json:"name"`
+
+	// Scope of the secret, e.g. its namespace in a Kubernetes secret store.
+	// The store's default scope is used if omitted.
+	// +optional
+	Scope string `json:"scope,omitempty"`
+
+	// Key of the credentials within the secret.
+	Key string `json:"key"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
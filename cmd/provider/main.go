@@ -20,11 +20,13 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"gopkg.in/alecthomas/kingpin.v2"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -39,7 +41,10 @@ import (
 	"github.com/crossplane/provider-harness/apis"
 	"github.com/crossplane/provider-harness/apis/v1alpha1"
 	harness "github.com/crossplane/provider-harness/internal/controller"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
 	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/webhook"
 )
 
 func main() {
@@ -50,14 +55,27 @@ func main() {
 
 		syncInterval     = app.Flag("sync", "How often all resources will be double-checked for drift from the desired state.").Short('s').Default("1h").Duration()
 		pollInterval     = app.Flag("poll", "How often individual resources will be checked for drift from the desired state").Default("1m").Duration()
+		pollIntervals    = app.Flag("poll-kind", "Overrides --poll for a kind of managed resource, e.g. Agent=30s. May be repeated.").PlaceHolder("KIND=DURATION").StringMap()
 		maxReconcileRate = app.Flag("max-reconcile-rate", "The global maximum rate per second at which resources may checked for drift from the desired state.").Default("10").Int()
 
+		reconcileFailureThreshold = app.Flag("reconcile-failure-threshold", "The number of consecutive failed reconciles after which a resource is flagged with a ReconcileFailing condition. Zero disables flagging.").Default(strconv.Itoa(deadletter.DefaultThreshold)).Envar("RECONCILE_FAILURE_THRESHOLD").Int64()
+		controllerNamePrefix      = app.Flag("controller-name-prefix", "A prefix for the names of controllers, used to label their metrics and as the source of their events.").Envar("CONTROLLER_NAME_PREFIX").String()
+		maxResourceReconcileRate  = app.Flag("max-resource-reconcile-rate", "The maximum rate per minute at which a single resource may be reconciled. Zero means unlimited. May be lowered, but not raised, per resource by annotation, which also applies when unlimited.").Default("0").Envar("MAX_RESOURCE_RECONCILE_RATE").Int()
+
 		namespace                  = app.Flag("namespace", "Namespace used to set as default scope in default secret store config.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for ExternalSecretStores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
 		enableManagementPolicies   = app.Flag("enable-management-policies", "Enable support for Management Policies.").Default("false").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+		enableAgentDependentGC     = app.Flag("enable-agent-dependent-gc", "Enable garbage collection of resources that depend on a deleted Agent.").Default("false").Envar("ENABLE_AGENT_DEPENDENT_GC").Bool()
+		enableInClusterAgentHealth = app.Flag("enable-in-cluster-agent-health", "Enable checking the Deployment of agents running in this cluster. Requires permission to get Deployments.").Default("false").Envar("ENABLE_IN_CLUSTER_AGENT_HEALTH").Bool()
+
+		webhookTLSCertDir    = app.Flag("webhook-tls-cert-dir", "The directory of TLS certificate that will be used by the webhook server. Webhooks are disabled if unset.").Envar("WEBHOOK_TLS_CERT_DIR").String()
+		scopePolicyConfigMap = app.Flag("scope-policy-configmap", "Name of a ConfigMap in the provider's namespace listing the Harness organizations and projects managed resources may target.").Default("provider-harness-scope-policy").Envar("SCOPE_POLICY_CONFIGMAP").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
+	kindPollIntervals, err := options.ParsePollIntervals(*pollIntervals, harness.PolledKinds)
+	kingpin.FatalIfError(err, "Cannot parse per-kind poll intervals")
+
 	zl := zap.New(zap.UseDevMode(*debug))
 	log := logging.NewLogrLogger(zl.WithName("provider-harness"))
 	if *debug {
@@ -85,16 +103,25 @@ func main() {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+
+		Port:    9443,
+		CertDir: *webhookTLSCertDir,
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Harness APIs to scheme")
 
-	o := controller.Options{
-		Logger:                  log,
-		MaxConcurrentReconciles: *maxReconcileRate,
-		PollInterval:            *pollInterval,
-		GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
-		Features:                &feature.Flags{},
+	o := options.Options{
+		Options: controller.Options{
+			Logger:                  log,
+			MaxConcurrentReconciles: *maxReconcileRate,
+			PollInterval:            *pollInterval,
+			GlobalRateLimiter:       ratelimiter.NewGlobal(*maxReconcileRate),
+			Features:                &feature.Flags{},
+		},
+		MaxReconcilesPerMinute:    *maxResourceReconcileRate,
+		ReconcileFailureThreshold: *reconcileFailureThreshold,
+		ControllerNamePrefix:      *controllerNamePrefix,
+		PollIntervals:             kindPollIntervals,
 	}
 
 	if *enableExternalSecretStores {
@@ -121,6 +148,19 @@ func main() {
 		log.Info("Alpha feature enabled", "flag", features.EnableAlphaManagementPolicies)
 	}
 
+	if *enableAgentDependentGC {
+		o.Features.Enable(features.EnableAlphaAgentDependentGC)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaAgentDependentGC)
+	}
+
+	if *enableInClusterAgentHealth {
+		o.Features.Enable(features.EnableAlphaInClusterAgentHealth)
+		log.Info("Alpha feature enabled", "flag", features.EnableAlphaInClusterAgentHealth)
+	}
+
 	kingpin.FatalIfError(harness.Setup(mgr, o), "Cannot setup Harness controllers")
+	if *webhookTLSCertDir != "" {
+		kingpin.FatalIfError(webhook.Setup(mgr, types.NamespacedName{Namespace: *namespace, Name: *scopePolicyConfigMap}), "Cannot setup webhooks")
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
@@ -0,0 +1,86 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+// A ServiceFn returns a Service configured by the supplied ProviderConfig,
+// authenticated using the supplied credentials.
+type ServiceFn func(pc *apisv1alpha1.ProviderConfig, creds []byte) (*Service, error)
+
+// A ServiceCache reuses the Services it builds across reconciles, so that
+// managed resources sharing a ProviderConfig share its HTTP connections. A
+// ProviderConfig's Service is rebuilt when its credentials or spec change.
+// It is safe for concurrent use.
+type ServiceCache struct {
+	newFn ServiceFn
+
+	mu      sync.Mutex
+	entries map[string]serviceEntry
+}
+
+type serviceEntry struct {
+	hash    [sha256.Size]byte
+	service *Service
+}
+
+// NewServiceCache returns a ServiceCache that builds Services using the
+// supplied function.
+func NewServiceCache(fn ServiceFn) *ServiceCache {
+	return &ServiceCache{newFn: fn, entries: map[string]serviceEntry{}}
+}
+
+// NewService returns the cached Service of the supplied ProviderConfig,
+// building and caching a new one if none is cached or the cached one was built
+// from different credentials, a different CA bundle, or a different generation
+// of the ProviderConfig.
+// Errors are not cached.
+func (c *ServiceCache) NewService(pc *apisv1alpha1.ProviderConfig, creds []byte) (*Service, error) {
+	h := sha256.New()
+	_ = binary.Write(h, binary.BigEndian, pc.GetGeneration())
+	_, _ = h.Write(creds)
+	// A CA bundle read from a Secret may change without the ProviderConfig
+	// changing.
+	if t := pc.Spec.TLS; t != nil && t.CABundle != nil {
+		_, _ = h.Write([]byte(*t.CABundle))
+	}
+	var hash [sha256.Size]byte
+	copy(hash[:], h.Sum(nil))
+
+	c.mu.Lock()
+	e, ok := c.entries[pc.GetName()]
+	c.mu.Unlock()
+	if ok && e.hash == hash {
+		return e.service, nil
+	}
+
+	svc, err := c.newFn(pc, creds)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.entries[pc.GetName()] = serviceEntry{hash: hash, service: svc}
+	c.mu.Unlock()
+	return svc, nil
+}
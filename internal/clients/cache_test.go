@@ -0,0 +1,124 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestServiceCache(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	pc := func(name string, generation int64) *apisv1alpha1.ProviderConfig {
+		pc := &apisv1alpha1.ProviderConfig{}
+		pc.SetName(name)
+		pc.SetGeneration(generation)
+		return pc
+	}
+
+	withCABundle := func(pc *apisv1alpha1.ProviderConfig, bundle string) *apisv1alpha1.ProviderConfig {
+		pc.Spec.TLS = &apisv1alpha1.TLSConfig{CABundle: &bundle}
+		return pc
+	}
+
+	type call struct {
+		pc    *apisv1alpha1.ProviderConfig
+		creds string
+	}
+	type want struct {
+		builds int
+		reused bool
+		err    error
+	}
+	cases := map[string]struct {
+		reason string
+		err    error
+		calls  []call
+		want   want
+	}{
+		"Reused": {
+			reason: "A Service should be reused while its ProviderConfig and credentials are unchanged.",
+			calls:  []call{{pc: pc("default", 1), creds: "key"}, {pc: pc("default", 1), creds: "key"}},
+			want:   want{builds: 1, reused: true},
+		},
+		"CredentialsChanged": {
+			reason: "A Service should be rebuilt when its credentials change.",
+			calls:  []call{{pc: pc("default", 1), creds: "key"}, {pc: pc("default", 1), creds: "rotated"}},
+			want:   want{builds: 2},
+		},
+		"ProviderConfigChanged": {
+			reason: "A Service should be rebuilt when its ProviderConfig's spec changes.",
+			calls:  []call{{pc: pc("default", 1), creds: "key"}, {pc: pc("default", 2), creds: "key"}},
+			want:   want{builds: 2},
+		},
+		"CABundleChanged": {
+			reason: "A Service should be rebuilt when the CA bundle its ProviderConfig references changes.",
+			calls:  []call{{pc: withCABundle(pc("default", 1), "ca"), creds: "key"}, {pc: withCABundle(pc("default", 1), "rotated"), creds: "key"}},
+			want:   want{builds: 2},
+		},
+		"OtherProviderConfig": {
+			reason: "ProviderConfigs should not share Services, even if their credentials are the same.",
+			calls:  []call{{pc: pc("default", 1), creds: "key"}, {pc: pc("other", 1), creds: "key"}},
+			want:   want{builds: 2},
+		},
+		"Error": {
+			reason: "Errors building a Service should be returned and not cached.",
+			err:    errBoom,
+			calls:  []call{{pc: pc("default", 1), creds: "key"}, {pc: pc("default", 1), creds: "key"}},
+			want:   want{builds: 2, err: errBoom},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			builds := 0
+			c := NewServiceCache(func(_ *apisv1alpha1.ProviderConfig, _ []byte) (*Service, error) {
+				builds++
+				if tc.err != nil {
+					return nil, tc.err
+				}
+				return &Service{}, nil
+			})
+
+			var got []*Service
+			var err error
+			for _, call := range tc.calls {
+				var svc *Service
+				svc, err = c.NewService(call.pc, []byte(call.creds))
+				got = append(got, svc)
+			}
+
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.NewService(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.builds, builds); diff != "" {
+				t.Errorf("\n%s\nc.NewService(...): -want builds, +got builds:\n%s\n", tc.reason, diff)
+			}
+			reused := got[0] != nil && got[0] == got[1]
+			if diff := cmp.Diff(tc.want.reused, reused); diff != "" {
+				t.Errorf("\n%s\nc.NewService(...): -want reused, +got reused:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
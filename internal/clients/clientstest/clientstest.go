@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clientstest contains helpers for testing controllers that use the
+// Harness API.
+package clientstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/hashicorp/go-retryablehttp"
+
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+// NewService returns a Service backed by a test server that handles requests
+// using the supplied handler. Failed requests are not retried.
+func NewService(t *testing.T, h http.HandlerFunc) *clients.Service {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	cfg := nextgen.NewConfiguration()
+	cfg.BasePath = srv.URL
+	cfg.HTTPClient = retryablehttp.NewClient()
+	cfg.HTTPClient.RetryMax = 0
+	cfg.HTTPClient.Logger = nil
+	cfg.HTTPClient.ErrorHandler = retryablehttp.PassthroughErrorHandler
+	return &clients.Service{APIClient: nextgen.NewAPIClient(cfg)}
+}
+
+// Respond returns a handler that responds to every request with the supplied
+// status and JSON body.
+func Respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+// JSON returns the supplied value encoded as JSON, for use as the body of a
+// response.
+func JSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal(...): %s", err)
+	}
+	return string(b)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+const (
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetPC        = "cannot get ProviderConfig"
+	errGetCreds     = "cannot get credentials"
+	errGetCABundle  = "cannot get CA bundle"
+	errNewClient    = "cannot create new Service"
+)
+
+// A Connector produces the Service a managed resource's ProviderConfig
+// configures. Controllers whose managed resources need nothing more than a
+// Service to connect share it.
+type Connector struct {
+	Kube         client.Client
+	Usage        resource.Tracker
+	NewServiceFn func(pc *apisv1alpha1.ProviderConfig, creds []byte) (*Service, error)
+	SecretStores bool
+}
+
+// NewConnector returns a Connector that tracks ProviderConfig usage and caches
+// the Services it produces. Credentials may only be read from an external
+// secret store if secretStores is true.
+func NewConnector(kube client.Client, secretStores bool) *Connector {
+	return &Connector{
+		Kube:         kube,
+		Usage:        resource.NewProviderConfigUsageTracker(kube, &apisv1alpha1.ProviderConfigUsage{}),
+		NewServiceFn: NewServiceCache(NewService).NewService,
+		SecretStores: secretStores,
+	}
+}
+
+// Connect returns a Service authenticated using the credentials of the
+// supplied managed resource's ProviderConfig.
+func (c *Connector) Connect(ctx context.Context, mg resource.Managed) (*Service, error) {
+	if err := c.Usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := c.Kube.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	data, err := Credentials(ctx, c.Kube, pc.Spec.Credentials, c.SecretStores)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+
+	if err := ResolveCABundle(ctx, c.Kube, pc); err != nil {
+		return nil, errors.Wrap(err, errGetCABundle)
+	}
+
+	svc, err := c.NewServiceFn(pc, data)
+	if err != nil {
+		return nil, errors.Wrap(err, errNewClient)
+	}
+	return svc, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestConnectorConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+	svc := &Service{APIKey: "key"}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+	track := resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil })
+	get := func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(*apisv1alpha1.ProviderConfig).Spec.Credentials.Source = xpv1.CredentialsSourceNone
+		return nil
+	}
+	newService := func(_ *apisv1alpha1.ProviderConfig, _ []byte) (*Service, error) { return svc, nil }
+
+	type want struct {
+		svc *Service
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		c      *Connector
+		want   want
+	}{
+		"TrackError": {
+			reason: "Errors tracking ProviderConfig usage should be returned.",
+			c: &Connector{
+				Usage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return errBoom }),
+			},
+			want: want{err: errors.Wrap(errBoom, errTrackPCUsage)},
+		},
+		"GetProviderConfigError": {
+			reason: "Errors getting the ProviderConfig should be returned.",
+			c: &Connector{
+				Kube:  &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				Usage: track,
+			},
+			want: want{err: errors.Wrap(errBoom, errGetPC)},
+		},
+		"NewServiceError": {
+			reason: "Errors creating the Service should be returned.",
+			c: &Connector{
+				Kube:  &test.MockClient{MockGet: get},
+				Usage: track,
+				NewServiceFn: func(_ *apisv1alpha1.ProviderConfig, _ []byte) (*Service, error) {
+					return nil, errBoom
+				},
+			},
+			want: want{err: errors.Wrap(errBoom, errNewClient)},
+		},
+		"Success": {
+			reason: "The Service the ProviderConfig configures should be returned.",
+			c: &Connector{
+				Kube:         &test.MockClient{MockGet: get},
+				Usage:        track,
+				NewServiceFn: newService,
+			},
+			want: want{svc: svc},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := tc.c.Connect(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.svc, got, cmp.AllowUnexported(Service{})); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
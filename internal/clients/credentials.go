@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+const (
+	errReadAPIKeyFile   = "cannot read API key file %q"
+	errEmptyAPIKeyFile  = "API key file %q is empty"
+	errParseCredentials = "cannot parse Harness credentials"
+	errNoCredentialsKey = "Harness credentials must include an apiKey"
+
+	errNoSecretStore      = "a secretStore must be specified to read credentials from a secret store"
+	errGetStoreConfig     = "cannot get StoreConfig"
+	errConnectSecretStore = "cannot connect to secret store"
+	errReadSecretStore    = "cannot read credentials from secret store"
+	errNoSecretStoreKey   = "key %q not found in secret %q"
+)
+
+// APICredentials are the credentials a ProviderConfig supplies as a JSON
+// document, for example:
+//
+//	{"apiKey": "pat.abc.def"}
+//
+// The document is the same whichever source it is read from, be it a Secret,
+// an environment variable, a file or an external secret store. Only an API
+// key file holds a bare API key instead.
+type APICredentials struct {
+	// APIKey authenticates requests to the Harness API.
+	APIKey string `json:"apiKey"`
+}
+
+// ParseCredentials parses the supplied JSON credentials document.
+func ParseCredentials(data []byte) (APICredentials, error) {
+	c := APICredentials{}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return APICredentials{}, errors.Wrap(err, errParseCredentials)
+	}
+	c.APIKey = strings.TrimSpace(c.APIKey)
+	if c.APIKey == "" {
+		return APICredentials{}, errors.New(errNoCredentialsKey)
+	}
+	return c, nil
+}
+
+// ReadAPIKeyFile reads an API key from the supplied file, for example one
+// mounted by a CSI secret driver. Callers should read the file each time
+// they need the key so that it may be rotated in place.
+func ReadAPIKeyFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path) //nolint:gosec // Reading a user supplied path is the point.
+	if err != nil {
+		return nil, errors.Wrapf(err, errReadAPIKeyFile, path)
+	}
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 {
+		return nil, errors.Errorf(errEmptyAPIKeyFile, path)
+	}
+	return b, nil
+}
+
+// SecretStoreCredentials reads the credentials selected by the supplied
+// selector from an external secret store, connecting to it using the supplied
+// store builder.
+func SecretStoreCredentials(ctx context.Context, kube client.Client, sel *apisv1alpha1.SecretStoreSelector, build connection.StoreBuilderFn) ([]byte, error) {
+	if sel == nil {
+		return nil, errors.New(errNoSecretStore)
+	}
+	sc := &apisv1alpha1.StoreConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: sel.StoreConfigRef.Name}, sc); err != nil {
+		return nil, errors.Wrap(err, errGetStoreConfig)
+	}
+	ss, err := build(ctx, kube, nil, sc.GetStoreConfig())
+	if err != nil {
+		return nil, errors.Wrap(err, errConnectSecretStore)
+	}
+	s := &store.Secret{}
+	if err := ss.ReadKeyValues(ctx, store.ScopedName{Name: sel.Name, Scope: sel.Scope}, s); err != nil {
+		return nil, errors.Wrap(err, errReadSecretStore)
+	}
+	data, ok := s.Data[sel.Key]
+	if !ok {
+		return nil, errors.Errorf(errNoSecretStoreKey, sel.Key, sel.Name)
+	}
+	return data, nil
+}
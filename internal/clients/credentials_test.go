@@ -0,0 +1,226 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/connection/store"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestReadAPIKeyFile(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile(...): %v", err)
+		}
+		return p
+	}
+	key := write("key", "pat.abc.def\n")
+	empty := write("empty", " \n")
+	missing := filepath.Join(dir, "missing")
+	_, errMissing := os.ReadFile(missing)
+
+	type want struct {
+		key string
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		path   string
+		want   want
+	}{
+		"Key": {
+			reason: "The key should be returned without surrounding whitespace.",
+			path:   key,
+			want:   want{key: "pat.abc.def"},
+		},
+		"Empty": {
+			reason: "An empty file should be rejected.",
+			path:   empty,
+			want:   want{err: errors.Errorf(errEmptyAPIKeyFile, empty)},
+		},
+		"Missing": {
+			reason: "A missing file should be rejected.",
+			path:   missing,
+			want:   want{err: errors.Wrapf(errMissing, errReadAPIKeyFile, missing)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ReadAPIKeyFile(tc.path)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nReadAPIKeyFile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.key, string(got)); diff != "" {
+				t.Errorf("\n%s\nReadAPIKeyFile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParseCredentials(t *testing.T) {
+	errSyntax := json.Unmarshal([]byte("pat.abc.def"), &APICredentials{})
+
+	type want struct {
+		creds APICredentials
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   want
+	}{
+		"Valid": {
+			reason: "The API key should be parsed from the credentials.",
+			data:   `{"apiKey": " pat.abc.def "}`,
+			want:   want{creds: APICredentials{APIKey: "pat.abc.def"}},
+		},
+		"NoAPIKey": {
+			reason: "Credentials without an API key should be rejected.",
+			data:   `{}`,
+			want:   want{err: errors.New(errNoCredentialsKey)},
+		},
+		"NotJSON": {
+			reason: "Credentials that are not a JSON document should be rejected.",
+			data:   "pat.abc.def",
+			want:   want{err: errors.Wrap(errSyntax, errParseCredentials)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParseCredentials([]byte(tc.data))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParseCredentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.creds, got); diff != "" {
+				t.Errorf("\n%s\nParseCredentials(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretStoreCredentials(t *testing.T) {
+	errBoom := errors.New("boom")
+	creds := []byte(`{"apiKey":"pat.abc.def"}`)
+	sel := &apisv1alpha1.SecretStoreSelector{
+		StoreConfigRef: xpv1.Reference{Name: "vault"},
+		Name:           "harness",
+		Scope:          "crossplane-system",
+		Key:            "credentials",
+	}
+	kube := &test.MockClient{MockGet: test.NewMockGetFn(nil)}
+	build := func(ss connection.Store, err error) connection.StoreBuilderFn {
+		return func(_ context.Context, _ client.Client, _ *tls.Config, _ xpv1.SecretStoreConfig) (connection.Store, error) {
+			return ss, err
+		}
+	}
+	read := func(data store.KeyValues) connection.Store {
+		return &fake.SecretStore{ReadKeyValuesFn: func(_ context.Context, n store.ScopedName, s *store.Secret) error {
+			if n.Name != sel.Name || n.Scope != sel.Scope {
+				return errors.Errorf("unexpected secret %s/%s", n.Scope, n.Name)
+			}
+			s.Data = data
+			return nil
+		}}
+	}
+
+	type want struct {
+		creds []byte
+		err   error
+	}
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		sel    *apisv1alpha1.SecretStoreSelector
+		build  connection.StoreBuilderFn
+		want   want
+	}{
+		"Read": {
+			reason: "The selected key of the selected secret should be returned.",
+			kube:   kube,
+			sel:    sel,
+			build:  build(read(store.KeyValues{"credentials": creds}), nil),
+			want:   want{creds: creds},
+		},
+		"NoSelector": {
+			reason: "Credentials cannot be read from a secret store without a selector.",
+			kube:   kube,
+			build:  build(read(nil), nil),
+			want:   want{err: errors.New(errNoSecretStore)},
+		},
+		"GetStoreConfigError": {
+			reason: "Errors getting the StoreConfig should be returned.",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			sel:    sel,
+			build:  build(read(nil), nil),
+			want:   want{err: errors.Wrap(errBoom, errGetStoreConfig)},
+		},
+		"ConnectError": {
+			reason: "Errors connecting to the secret store should be returned.",
+			kube:   kube,
+			sel:    sel,
+			build:  build(nil, errBoom),
+			want:   want{err: errors.Wrap(errBoom, errConnectSecretStore)},
+		},
+		"MissingKey": {
+			reason: "A secret without the selected key should be reported.",
+			kube:   kube,
+			sel:    sel,
+			build:  build(read(store.KeyValues{"other": creds}), nil),
+			want:   want{err: errors.Errorf(errNoSecretStoreKey, "credentials", "harness")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SecretStoreCredentials(context.Background(), tc.kube, tc.sel, tc.build)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSecretStoreCredentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.creds, got); diff != "" {
+				t.Errorf("\n%s\nSecretStoreCredentials(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCredentialsSecretStoresDisabled(t *testing.T) {
+	cd := apisv1alpha1.ProviderCredentials{Source: apisv1alpha1.CredentialsSourceSecretStore}
+	_, err := Credentials(context.Background(), &test.MockClient{}, cd, false)
+	if diff := cmp.Diff(errors.New(errSecretStoresDisabled), err, test.EquateErrors()); diff != "" {
+		t.Errorf("Credentials(...): -want error, +got error:\n%s", diff)
+	}
+}
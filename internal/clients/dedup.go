@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxReadDuration bounds a shared read whose request has no deadline.
+const maxReadDuration = 30 * time.Second
+
+// reads are the identical GET requests currently in flight, shared by every
+// deduplicating transport so that reconcilers of different managed resources
+// and kinds share reads of the same Harness entity.
+var reads = &readGroup{calls: map[[sha256.Size]byte]*read{}}
+
+// NewDedupTransport returns an http.RoundTripper that lets concurrent
+// identical GET requests share a single in-flight request. Requests are
+// identical when their URL, which includes the account, endpoint and
+// identifier, and their headers, which include the API key, are the same.
+// Other requests are delegated to base, or to http.DefaultTransport if base is
+// nil.
+func NewDedupTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &dedupTransport{base: base, group: reads}
+}
+
+type dedupTransport struct {
+	base  http.RoundTripper
+	group *readGroup
+}
+
+func (t *dedupTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Body != nil && req.Body != http.NoBody {
+		return t.base.RoundTrip(req)
+	}
+	return t.group.do(req, t.base)
+}
+
+type readGroup struct {
+	mu    sync.Mutex
+	calls map[[sha256.Size]byte]*read
+}
+
+// A read is an in-flight request, and once done its response.
+type read struct {
+	done chan struct{}
+
+	status     string
+	statusCode int
+	proto      string
+	header     http.Header
+	body       []byte
+	err        error
+}
+
+func (g *readGroup) do(req *http.Request, base http.RoundTripper) (*http.Response, error) {
+	key := readKey(req)
+
+	g.mu.Lock()
+	r, ok := g.calls[key]
+	if !ok {
+		r = &read{done: make(chan struct{})}
+		g.calls[key] = r
+		go g.run(key, r, req, base)
+	}
+	g.mu.Unlock()
+
+	// Each caller waits for the shared read subject to its own context, so
+	// a caller giving up neither cancels the read nor fails other callers.
+	select {
+	case <-r.done:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return &http.Response{
+		Status:        r.status,
+		StatusCode:    r.statusCode,
+		Proto:         r.proto,
+		Header:        r.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.body)),
+		ContentLength: int64(len(r.body)),
+		Request:       req,
+	}, nil
+}
+
+func (g *readGroup) run(key [sha256.Size]byte, r *read, req *http.Request, base http.RoundTripper) {
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(r.done)
+	}()
+
+	// The read outlives the context of the request that started it, but
+	// keeps its deadline so that it cannot run forever.
+	ctx, cancel := detach(req.Context())
+	defer cancel()
+
+	rsp, err := base.RoundTrip(req.Clone(ctx))
+	if err != nil {
+		r.err = err
+		return
+	}
+	defer rsp.Body.Close() //nolint:errcheck // Nothing useful to do with this error.
+	r.body, r.err = io.ReadAll(rsp.Body)
+	r.status, r.statusCode, r.proto, r.header = rsp.Status, rsp.StatusCode, rsp.Proto, rsp.Header
+}
+
+// readKey identifies identical requests without retaining their API key.
+func readKey(req *http.Request) [sha256.Size]byte {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.URL.String())
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range req.Header[k] {
+			_, _ = io.WriteString(h, "\x00"+k+"\x00"+v)
+		}
+	}
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key
+}
+
+// detach returns a context with the values and deadline of the supplied
+// context that is not cancelled when it is. A context without a deadline is
+// given one maxReadDuration from now.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := detached{parent: ctx}
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(d, deadline)
+	}
+	return context.WithTimeout(d, maxReadDuration)
+}
+
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detached) Done() <-chan struct{}               { return nil }
+func (detached) Err() error                          { return nil }
+func (d detached) Value(key interface{}) interface{} { return d.parent.Value(key) }
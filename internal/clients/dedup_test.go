@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+const connectorURL = "https://app.harness.io/gateway/ng/api/connectors/github?accountIdentifier=account"
+
+// gatedTransport counts the requests it receives, and holds them until it is
+// released.
+type gatedTransport struct {
+	requests atomic.Int32
+	release  chan struct{}
+	err      error
+}
+
+func (t *gatedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	<-t.release
+	if t.err != nil {
+		return nil, t.err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"identifier":"github"}`)),
+		Request:    req,
+	}, nil
+}
+
+// A joinContext reports when a caller first waits on it. A caller only waits
+// on its context once it has joined a read, so the report tells tests that the
+// caller will share the read.
+type joinContext struct {
+	context.Context
+	once   sync.Once
+	joined chan<- struct{}
+}
+
+func joining(ctx context.Context, joined chan<- struct{}) context.Context {
+	return &joinContext{Context: ctx, joined: joined}
+}
+
+func (c *joinContext) Done() <-chan struct{} {
+	c.once.Do(func() { c.joined <- struct{}{} })
+	return c.Context.Done()
+}
+
+// waitForJoins blocks until n callers have joined reads.
+func waitForJoins(t *testing.T, joined <-chan struct{}, n int) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for i := 0; i < n; i++ {
+		select {
+		case <-joined:
+		case <-timeout:
+			t.Fatalf("timed out waiting for %d callers", n)
+		}
+	}
+}
+
+func get(ctx context.Context, t *testing.T, url, key string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatalf("NewRequest(...): %v", err)
+	}
+	req.Header.Set("x-api-key", key)
+	return req
+}
+
+func TestDedupTransportConcurrentReads(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	cases := map[string]struct {
+		reason   string
+		err      error
+		requests int32
+	}{
+		"SharedRead": {
+			reason:   "Concurrent identical reads should share one request and each receive its response.",
+			requests: 1,
+		},
+		"SharedError": {
+			reason:   "An error reading should be returned to every caller sharing the read.",
+			err:      errBoom,
+			requests: 1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			const callers = 10
+			base := &gatedTransport{release: make(chan struct{}), err: tc.err}
+			g := &readGroup{calls: map[[sha256.Size]byte]*read{}}
+			rt := &dedupTransport{base: base, group: g}
+
+			var wg sync.WaitGroup
+			joined := make(chan struct{}, callers)
+			bodies := make([]string, callers)
+			errs := make([]error, callers)
+			for i := 0; i < callers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					rsp, err := rt.RoundTrip(get(joining(context.Background(), joined), t, connectorURL, "secret"))
+					errs[i] = err
+					if err != nil {
+						return
+					}
+					b, _ := io.ReadAll(rsp.Body)
+					_ = rsp.Body.Close()
+					bodies[i] = string(b)
+				}(i)
+			}
+			waitForJoins(t, joined, callers)
+			close(base.release)
+			wg.Wait()
+
+			if diff := cmp.Diff(tc.requests, base.requests.Load()); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+			for i := 0; i < callers; i++ {
+				if diff := cmp.Diff(tc.err, errs[i], test.EquateErrors()); diff != "" {
+					t.Errorf("\n%s\nRoundTrip(...): -want error, +got error:\n%s\n", tc.reason, diff)
+				}
+				if tc.err == nil && bodies[i] != `{"identifier":"github"}` {
+					t.Errorf("\n%s\nRoundTrip(...): got body %q", tc.reason, bodies[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDedupTransportCancelledCaller(t *testing.T) {
+	base := &gatedTransport{release: make(chan struct{})}
+	g := &readGroup{calls: map[[sha256.Size]byte]*read{}}
+	rt := &dedupTransport{base: base, group: g}
+
+	// The caller that starts the read gives up before it completes.
+	joined := make(chan struct{}, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+	go func() {
+		_, err := rt.RoundTrip(get(joining(ctx, joined), t, connectorURL, "secret"))
+		cancelled <- err
+	}()
+	waitForJoins(t, joined, 1)
+
+	waiting := make(chan error)
+	go func() {
+		rsp, err := rt.RoundTrip(get(joining(context.Background(), joined), t, connectorURL, "secret"))
+		if err == nil {
+			_ = rsp.Body.Close()
+		}
+		waiting <- err
+	}()
+	waitForJoins(t, joined, 1)
+
+	cancel()
+	if diff := cmp.Diff(context.Canceled, <-cancelled, test.EquateErrors()); diff != "" {
+		t.Errorf("RoundTrip(...): -want error, +got error:\n%s", diff)
+	}
+	close(base.release)
+	if err := <-waiting; err != nil {
+		t.Errorf("RoundTrip(...): a cancelled caller should not fail other callers: %v", err)
+	}
+}
+
+func TestDedupTransportDistinctReads(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a      func(t *testing.T) *http.Request
+		b      func(t *testing.T) *http.Request
+	}{
+		"DifferentIdentifier": {
+			reason: "Reads of different entities should not be shared.",
+			a:      func(t *testing.T) *http.Request { return get(context.Background(), t, connectorURL, "secret") },
+			b: func(t *testing.T) *http.Request {
+				return get(context.Background(), t, strings.Replace(connectorURL, "github", "gitlab", 1), "secret")
+			},
+		},
+		"DifferentAPIKey": {
+			reason: "Reads made with different credentials should not be shared.",
+			a:      func(t *testing.T) *http.Request { return get(context.Background(), t, connectorURL, "secret") },
+			b:      func(t *testing.T) *http.Request { return get(context.Background(), t, connectorURL, "other") },
+		},
+		"NotARead": {
+			reason: "Requests other than GETs should never be shared.",
+			a: func(t *testing.T) *http.Request {
+				req := get(context.Background(), t, connectorURL, "secret")
+				req.Method = http.MethodDelete
+				return req
+			},
+			b: func(t *testing.T) *http.Request {
+				req := get(context.Background(), t, connectorURL, "secret")
+				req.Method = http.MethodDelete
+				return req
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			base := &gatedTransport{release: make(chan struct{})}
+			rt := &dedupTransport{base: base, group: &readGroup{calls: map[[sha256.Size]byte]*read{}}}
+
+			var wg sync.WaitGroup
+			for _, req := range []*http.Request{tc.a(t), tc.b(t)} {
+				wg.Add(1)
+				go func(req *http.Request) {
+					defer wg.Done()
+					if rsp, err := rt.RoundTrip(req); err == nil {
+						_ = rsp.Body.Close()
+					}
+				}(req)
+			}
+			deadline := time.Now().Add(5 * time.Second)
+			for base.requests.Load() < 2 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			close(base.release)
+			wg.Wait()
+
+			if diff := cmp.Diff(int32(2), base.requests.Load()); diff != "" {
+				t.Errorf("\n%s\nRoundTrip(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+)
+
+// ErrorMessage returns the message of the supplied Harness API error. The SDK
+// reports only the status of most failed requests, so the message is read
+// from the response body when it has one. It returns the error's own message
+// otherwise.
+func ErrorMessage(err error) string {
+	var serr nextgen.GenericSwaggerError
+	if !errors.As(err, &serr) {
+		return err.Error()
+	}
+	body := nextgen.GatewayruntimeError{}
+	if json.Unmarshal(serr.Body(), &body) != nil {
+		return err.Error()
+	}
+	switch {
+	case body.Message != "":
+		return body.Message
+	case body.Error_ != "":
+		return body.Error_
+	}
+	return err.Error()
+}
+
+// IsNotFound returns true if the supplied response reports that the requested
+// entity does not exist. It is the only response that may be taken to mean
+// an external resource is absent.
+func IsNotFound(response *http.Response) bool {
+	return response != nil && response.StatusCode == http.StatusNotFound
+}
+
+// IsTransient returns true if the supplied error, returned with the supplied
+// response, may not recur if the request is retried. Requests that failed
+// without a response, timed out, were rate limited, or hit a server error are
+// transient. Any other error is terminal until the request changes. Transient
+// errors should be returned, so that the managed reconciler backs off before
+// observing the resource again.
+func IsTransient(err error, response *http.Response) bool {
+	switch {
+	case err == nil, errors.Is(err, context.Canceled):
+		return false
+	case errors.Is(err, context.DeadlineExceeded), response == nil:
+		return true
+	}
+	switch response.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	}
+	return response.StatusCode >= http.StatusInternalServerError
+}
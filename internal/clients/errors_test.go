@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+)
+
+func TestErrorMessage(t *testing.T) {
+	// GenericSwaggerError has no exported fields, so errors carrying a body
+	// can only be produced by the SDK itself.
+	swaggerError := func(body string) error {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			_, _ = w.Write([]byte(body))
+		}))
+		defer srv.Close()
+		cfg := nextgen.NewConfiguration()
+		cfg.BasePath = srv.URL
+		_, _, err := nextgen.NewAPIClient(cfg).AgentApi.AgentServiceForServerDelete(context.Background(), "agent", nil)
+		return err
+	}
+
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   string
+	}{
+		"Message": {
+			reason: "The message of the response body should be returned.",
+			err:    swaggerError(`{"error":"conflict","code":9,"message":"agent is referenced by application checkout"}`),
+			want:   "agent is referenced by application checkout",
+		},
+		"ErrorOnly": {
+			reason: "The error of the response body should be returned if it has no message.",
+			err:    swaggerError(`{"error":"agent has applications"}`),
+			want:   "agent has applications",
+		},
+		"UnparseableBody": {
+			reason: "The error's own message should be returned if the body cannot be parsed.",
+			err:    swaggerError(`<html>conflict</html>`),
+			want:   "409 Conflict",
+		},
+		"NotSwaggerError": {
+			reason: "The message of other errors should be returned.",
+			err:    errors.New("boom"),
+			want:   "boom",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ErrorMessage(tc.err)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nErrorMessage(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		response *http.Response
+		want     bool
+	}{
+		"NotFound": {
+			reason:   "A 404 response should be reported as not found.",
+			response: &http.Response{StatusCode: http.StatusNotFound},
+			want:     true,
+		},
+		"ServerError": {
+			reason:   "Other error responses should not be reported as not found.",
+			response: &http.Response{StatusCode: http.StatusInternalServerError},
+			want:     false,
+		},
+		"NoResponse": {
+			reason: "A request that failed without a response should not be reported as not found.",
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsNotFound(tc.response)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsNotFound(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	errBoom := errors.New("boom")
+	status := func(code int) *http.Response { return &http.Response{StatusCode: code} }
+
+	cases := map[string]struct {
+		reason   string
+		err      error
+		response *http.Response
+		want     bool
+	}{
+		"NoError": {
+			reason:   "A request that succeeded should not be reported as failing transiently.",
+			response: status(http.StatusOK),
+			want:     false,
+		},
+		"NoResponse": {
+			reason: "A request that failed without a response should be reported as transient.",
+			err:    errBoom,
+			want:   true,
+		},
+		"DeadlineExceeded": {
+			reason: "A request that timed out should be reported as transient.",
+			err:    fmt.Errorf("get agent: %w", context.DeadlineExceeded),
+			want:   true,
+		},
+		"Canceled": {
+			reason: "A request that was canceled should not be reported as transient.",
+			err:    fmt.Errorf("get agent: %w", context.Canceled),
+			want:   false,
+		},
+		"RequestTimeout": {
+			reason:   "A 408 response should be reported as transient.",
+			err:      errBoom,
+			response: status(http.StatusRequestTimeout),
+			want:     true,
+		},
+		"TooManyRequests": {
+			reason:   "A 429 response should be reported as transient.",
+			err:      errBoom,
+			response: status(http.StatusTooManyRequests),
+			want:     true,
+		},
+		"ServiceUnavailable": {
+			reason:   "A 503 response should be reported as transient.",
+			err:      errBoom,
+			response: status(http.StatusServiceUnavailable),
+			want:     true,
+		},
+		"NotImplemented": {
+			reason:   "A 501 response should be reported as terminal.",
+			err:      errBoom,
+			response: status(http.StatusNotImplemented),
+			want:     false,
+		},
+		"BadRequest": {
+			reason:   "A 400 response should be reported as terminal.",
+			err:      errBoom,
+			response: status(http.StatusBadRequest),
+			want:     false,
+		},
+		"NotFound": {
+			reason:   "A 404 response should be reported as terminal.",
+			err:      errBoom,
+			response: status(http.StatusNotFound),
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := IsTransient(tc.err, tc.response)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nIsTransient(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clients contains helpers shared by the Harness API clients used by
+// the provider's controllers.
+package clients
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+// Known Harness SaaS regions.
+const (
+	RegionProd1 = "prod1"
+	RegionProd2 = "prod2"
+	RegionProd3 = "prod3"
+	RegionEU    = "eu"
+
+	// DefaultRegion is used when a ProviderConfig does not specify a region.
+	DefaultRegion = RegionProd1
+)
+
+const (
+	errUnknownRegion  = "unknown Harness region %q, must be one of: %s"
+	errInvalidBaseURL = "invalid Harness base URL %q"
+)
+
+var regionBaseURLs = map[string]string{
+	RegionProd1: "https://app.harness.io",
+	RegionProd2: "https://app.harness.io/gratis",
+	RegionProd3: "https://app3.harness.io",
+	RegionEU:    "https://app.eu.harness.io",
+}
+
+// BaseURLForRegion returns the Harness API base URL of the supplied SaaS
+// region. An empty region resolves to the DefaultRegion.
+func BaseURLForRegion(region string) (string, error) {
+	if region == "" {
+		region = DefaultRegion
+	}
+	u, ok := regionBaseURLs[region]
+	if !ok {
+		known := make([]string, 0, len(regionBaseURLs))
+		for r := range regionBaseURLs {
+			known = append(known, r)
+		}
+		sort.Strings(known)
+		return "", errors.Errorf(errUnknownRegion, region, strings.Join(known, ", "))
+	}
+	return u, nil
+}
+
+// BaseURL returns the Harness API base URL a ProviderConfig points to. An
+// explicit BaseURL takes precedence over the Region.
+func BaseURL(spec apisv1alpha1.ProviderConfigSpec) (string, error) {
+	if spec.BaseURL != nil && *spec.BaseURL != "" {
+		u, err := url.Parse(*spec.BaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "", errors.Errorf(errInvalidBaseURL, *spec.BaseURL)
+		}
+		return strings.TrimSuffix(*spec.BaseURL, "/"), nil
+	}
+	region := ""
+	if spec.Region != nil {
+		region = *spec.Region
+	}
+	return BaseURLForRegion(region)
+}
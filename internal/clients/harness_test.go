@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestBaseURL(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	type want struct {
+		url string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		spec   apisv1alpha1.ProviderConfigSpec
+		want   want
+	}{
+		"Default": {
+			reason: "An empty ProviderConfig should resolve to the primary SaaS cluster.",
+			spec:   apisv1alpha1.ProviderConfigSpec{},
+			want:   want{url: "https://app.harness.io"},
+		},
+		"KnownRegion": {
+			reason: "A known region should resolve to its base URL.",
+			spec:   apisv1alpha1.ProviderConfigSpec{Region: str(RegionProd3)},
+			want:   want{url: "https://app3.harness.io"},
+		},
+		"UnknownRegion": {
+			reason: "An unknown region should return an error listing the known regions.",
+			spec:   apisv1alpha1.ProviderConfigSpec{Region: str("prod9")},
+			want:   want{err: errors.Errorf(errUnknownRegion, "prod9", "eu, prod1, prod2, prod3")},
+		},
+		"BaseURLOverride": {
+			reason: "An explicit base URL should take precedence over the region.",
+			spec:   apisv1alpha1.ProviderConfigSpec{Region: str(RegionEU), BaseURL: str("https://harness.example.org/")},
+			want:   want{url: "https://harness.example.org"},
+		},
+		"InvalidBaseURL": {
+			reason: "A base URL without a scheme and host should be rejected.",
+			spec:   apisv1alpha1.ProviderConfigSpec{BaseURL: str("harness.example.org")},
+			want:   want{err: errors.Errorf(errInvalidBaseURL, "harness.example.org")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := BaseURL(tc.spec)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nBaseURL(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.url, got); diff != "" {
+				t.Errorf("\n%s\nBaseURL(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBaseURLForRegion(t *testing.T) {
+	type want struct {
+		url string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		region string
+		want   want
+	}{
+		"Default": {
+			reason: "An empty region should resolve to the default region.",
+			want:   want{url: "https://app.harness.io"},
+		},
+		"Prod1": {
+			reason: "The prod1 region should resolve to the primary SaaS cluster.",
+			region: RegionProd1,
+			want:   want{url: "https://app.harness.io"},
+		},
+		"Prod2": {
+			reason: "The prod2 region should resolve to its path on the primary SaaS host.",
+			region: RegionProd2,
+			want:   want{url: "https://app.harness.io/gratis"},
+		},
+		"Prod3": {
+			reason: "The prod3 region should resolve to its own host.",
+			region: RegionProd3,
+			want:   want{url: "https://app3.harness.io"},
+		},
+		"EU": {
+			reason: "The eu region should resolve to the EU SaaS cluster.",
+			region: RegionEU,
+			want:   want{url: "https://app.eu.harness.io"},
+		},
+		"Unknown": {
+			reason: "An unknown region should return an error listing the known regions.",
+			region: "us-west",
+			want:   want{err: errors.Errorf(errUnknownRegion, "us-west", "eu, prod1, prod2, prod3")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := BaseURLForRegion(tc.region)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nBaseURLForRegion(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.url, got); diff != "" {
+				t.Errorf("\n%s\nBaseURLForRegion(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+// Defaults of the HTTP client used to call the Harness API.
+const (
+	DefaultRetryMax     = 10
+	DefaultRetryWaitMin = 5 * time.Second
+	DefaultRetryWaitMax = 10 * time.Second
+	DefaultTimeout      = 10 * time.Second
+)
+
+const (
+	errNegativeRetryMax = "httpClient.retryMax must not be negative"
+	errNegativeDuration = "httpClient.%s must not be negative"
+	errRetryWait        = "httpClient.retryWaitMin %s must not exceed httpClient.retryWaitMax %s"
+	errInvalidProxyURL  = "httpClient.proxyURL %q must be an http, https or socks5 URL with a host"
+)
+
+// HTTPClientOptions configure how requests to the Harness API are retried
+// and timed out.
+type HTTPClientOptions struct {
+	RetryMax     int
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	Timeout      time.Duration
+
+	// Proxy requests are sent through. Requests are sent through the proxy
+	// configured by the environment if it is nil.
+	Proxy *url.URL
+
+	// TLS configures how the certificate of the Harness API is verified.
+	// The system's CAs are trusted if it is nil.
+	TLS *tls.Config
+}
+
+// HTTPClient returns the HTTP client options a ProviderConfig specifies,
+// using the defaults for any it omits.
+func HTTPClient(spec apisv1alpha1.ProviderConfigSpec) (HTTPClientOptions, error) {
+	o := HTTPClientOptions{
+		RetryMax:     DefaultRetryMax,
+		RetryWaitMin: DefaultRetryWaitMin,
+		RetryWaitMax: DefaultRetryWaitMax,
+		Timeout:      DefaultTimeout,
+	}
+	t, err := TLSClientConfig(spec)
+	if err != nil {
+		return HTTPClientOptions{}, err
+	}
+	o.TLS = t
+	c := spec.HTTPClient
+	if c == nil {
+		return o, nil
+	}
+	if c.RetryMax != nil {
+		if *c.RetryMax < 0 {
+			return HTTPClientOptions{}, errors.New(errNegativeRetryMax)
+		}
+		o.RetryMax = *c.RetryMax
+	}
+	durations := []struct {
+		field string
+		in    *metav1.Duration
+		out   *time.Duration
+	}{
+		{field: "retryWaitMin", in: c.RetryWaitMin, out: &o.RetryWaitMin},
+		{field: "retryWaitMax", in: c.RetryWaitMax, out: &o.RetryWaitMax},
+		{field: "timeout", in: c.Timeout, out: &o.Timeout},
+	}
+	for _, d := range durations {
+		if d.in == nil {
+			continue
+		}
+		if d.in.Duration < 0 {
+			return HTTPClientOptions{}, errors.Errorf(errNegativeDuration, d.field)
+		}
+		*d.out = d.in.Duration
+	}
+	if o.RetryWaitMin > o.RetryWaitMax {
+		return HTTPClientOptions{}, errors.Errorf(errRetryWait, o.RetryWaitMin, o.RetryWaitMax)
+	}
+	if c.ProxyURL != nil {
+		u, err := url.Parse(*c.ProxyURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5") {
+			return HTTPClientOptions{}, errors.Errorf(errInvalidProxyURL, *c.ProxyURL)
+		}
+		o.Proxy = u
+	}
+	return o, nil
+}
+
+// Transport returns the transport requests to the Harness API are sent with.
+// It sends requests through the proxy of the supplied options, or the proxy
+// configured by the environment if they specify none, and verifies the
+// Harness API's certificate as they specify.
+func Transport(o HTTPClientOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = http.ProxyFromEnvironment
+	if o.Proxy != nil {
+		t.Proxy = http.ProxyURL(o.Proxy)
+	}
+	if o.TLS != nil {
+		t.TLSClientConfig = o.TLS
+	}
+	return t
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestHTTPClient(t *testing.T) {
+	retries := 3
+	negative := -1
+	proxy, invalidProxy := "http://proxy.example.org:3128", "proxy.example.org:3128"
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	defaults := HTTPClientOptions{
+		RetryMax:     DefaultRetryMax,
+		RetryWaitMin: DefaultRetryWaitMin,
+		RetryWaitMax: DefaultRetryWaitMax,
+		Timeout:      DefaultTimeout,
+	}
+
+	type want struct {
+		o   HTTPClientOptions
+		err error
+	}
+	cases := map[string]struct {
+		reason string
+		config *apisv1alpha1.HTTPClientConfig
+		want   want
+	}{
+		"Unset": {
+			reason: "The defaults should be used when no HTTP client is configured.",
+			want:   want{o: defaults},
+		},
+		"Configured": {
+			reason: "Configured options should override the defaults.",
+			config: &apisv1alpha1.HTTPClientConfig{
+				RetryMax:     &retries,
+				RetryWaitMin: duration(time.Second),
+				RetryWaitMax: duration(30 * time.Second),
+				Timeout:      duration(time.Minute),
+			},
+			want: want{o: HTTPClientOptions{RetryMax: 3, RetryWaitMin: time.Second, RetryWaitMax: 30 * time.Second, Timeout: time.Minute}},
+		},
+		"Partial": {
+			reason: "Options that are not configured should keep their defaults.",
+			config: &apisv1alpha1.HTTPClientConfig{Timeout: duration(time.Minute)},
+			want: want{o: HTTPClientOptions{
+				RetryMax:     DefaultRetryMax,
+				RetryWaitMin: DefaultRetryWaitMin,
+				RetryWaitMax: DefaultRetryWaitMax,
+				Timeout:      time.Minute,
+			}},
+		},
+		"NegativeRetryMax": {
+			reason: "A negative number of retries should be rejected.",
+			config: &apisv1alpha1.HTTPClientConfig{RetryMax: &negative},
+			want:   want{err: errors.New(errNegativeRetryMax)},
+		},
+		"NegativeTimeout": {
+			reason: "A negative timeout should be rejected.",
+			config: &apisv1alpha1.HTTPClientConfig{Timeout: duration(-time.Second)},
+			want:   want{err: errors.Errorf(errNegativeDuration, "timeout")},
+		},
+		"Proxy": {
+			reason: "A configured proxy URL should be parsed.",
+			config: &apisv1alpha1.HTTPClientConfig{ProxyURL: &proxy},
+			want: want{o: HTTPClientOptions{
+				RetryMax:     DefaultRetryMax,
+				RetryWaitMin: DefaultRetryWaitMin,
+				RetryWaitMax: DefaultRetryWaitMax,
+				Timeout:      DefaultTimeout,
+				Proxy:        &url.URL{Scheme: "http", Host: "proxy.example.org:3128"},
+			}},
+		},
+		"InvalidProxy": {
+			reason: "A proxy URL without a supported scheme should be rejected.",
+			config: &apisv1alpha1.HTTPClientConfig{ProxyURL: &invalidProxy},
+			want:   want{err: errors.Errorf(errInvalidProxyURL, invalidProxy)},
+		},
+		"RetryWaitInverted": {
+			reason: "A minimum retry wait exceeding the maximum should be rejected.",
+			config: &apisv1alpha1.HTTPClientConfig{RetryWaitMin: duration(time.Minute)},
+			want:   want{err: errors.Errorf(errRetryWait, time.Minute, DefaultRetryWaitMax)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := HTTPClient(apisv1alpha1.ProviderConfigSpec{HTTPClient: tc.config})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nHTTPClient(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\nHTTPClient(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestTransportProxy asserts that requests are sent through the configured
+// proxy rather than directly to Harness.
+func TestTransportProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	u, _ := url.Parse(proxy.URL)
+	c := &http.Client{Transport: Transport(HTTPClientOptions{Proxy: u})}
+	rsp, err := c.Get("http://harness.invalid/gitops/api/v1/agents")
+	if err != nil {
+		t.Fatalf("Get(...): %s", err)
+	}
+	_ = rsp.Body.Close()
+	if diff := cmp.Diff("http://harness.invalid/gitops/api/v1/agents", proxied); diff != "" {
+		t.Errorf("Transport(...): -want proxied request, +got proxied request:\n%s", diff)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+// HeaderOnBehalfOf identifies the principal the provider acts on behalf of.
+const HeaderOnBehalfOf = "X-On-Behalf-Of"
+
+const errEmptyImpersonatePrincipal = "impersonatePrincipal must not be empty when set"
+
+// ImpersonatePrincipal returns the principal requests made with the supplied
+// ProviderConfig should be attributed to, or an empty string if requests
+// should not carry one.
+func ImpersonatePrincipal(spec apisv1alpha1.ProviderConfigSpec) (string, error) {
+	if spec.ImpersonatePrincipal == nil {
+		return "", nil
+	}
+	p := strings.TrimSpace(*spec.ImpersonatePrincipal)
+	if p == "" {
+		return "", errors.New(errEmptyImpersonatePrincipal)
+	}
+	return p, nil
+}
+
+// NewImpersonationTransport returns an http.RoundTripper that attributes each
+// request to the supplied principal. Other headers, including the API key,
+// are left untouched. Requests are delegated to base, or to
+// http.DefaultTransport if base is nil.
+func NewImpersonationTransport(base http.RoundTripper, principal string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &impersonationTransport{base: base, principal: principal}
+}
+
+type impersonationTransport struct {
+	base      http.RoundTripper
+	principal string
+}
+
+func (t *impersonationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	req.Header.Set(HeaderOnBehalfOf, t.principal)
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestImpersonatePrincipal(t *testing.T) {
+	empty := " "
+	alice := "alice@example.com"
+
+	type want struct {
+		principal string
+		err       error
+	}
+	cases := map[string]struct {
+		reason string
+		spec   apisv1alpha1.ProviderConfigSpec
+		want   want
+	}{
+		"Unset": {
+			reason: "No principal should be returned when impersonation is not configured.",
+		},
+		"Empty": {
+			reason: "An empty principal should be rejected.",
+			spec:   apisv1alpha1.ProviderConfigSpec{ImpersonatePrincipal: &empty},
+			want:   want{err: errors.New(errEmptyImpersonatePrincipal)},
+		},
+		"Set": {
+			reason: "The configured principal should be returned.",
+			spec:   apisv1alpha1.ProviderConfigSpec{ImpersonatePrincipal: &alice},
+			want:   want{principal: alice},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ImpersonatePrincipal(tc.spec)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nImpersonatePrincipal(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.principal, got); diff != "" {
+				t.Errorf("\n%s\nImpersonatePrincipal(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImpersonationTransport(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+	if err != nil {
+		t.Fatalf("NewRequest(...): %v", err)
+	}
+	req.Header.Set("x-api-key", "secret")
+
+	c := &http.Client{Transport: NewImpersonationTransport(nil, "alice@example.com")}
+	rsp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do(...): %v", err)
+	}
+	_ = rsp.Body.Close()
+
+	if diff := cmp.Diff("alice@example.com", got.Get(HeaderOnBehalfOf)); diff != "" {
+		t.Errorf("RoundTrip(...): -want principal, +got principal:\n%s", diff)
+	}
+	if diff := cmp.Diff("secret", got.Get("x-api-key")); diff != "" {
+		t.Errorf("RoundTrip(...): -want API key, +got API key:\n%s", diff)
+	}
+	if req.Header.Get(HeaderOnBehalfOf) != "" {
+		t.Errorf("RoundTrip(...): modified the caller's request")
+	}
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+)
+
+// Harness modules that managed resources may require a license for.
+const (
+	// ModuleCD is the Continuous Delivery module, which includes GitOps.
+	ModuleCD = "CD"
+)
+
+// licenseStatusActive is the status of a license that is in effect.
+const licenseStatusActive = "ACTIVE"
+
+// DefaultLicenseTTL is how long a LicenseCache remembers whether a module is
+// licensed.
+const DefaultLicenseTTL = 10 * time.Minute
+
+// A LicenseFetcher returns the licenses of a module for an account.
+type LicenseFetcher func(ctx context.Context, account, module string) ([]nextgen.ModuleLicense, error)
+
+// A LicenseCache caches whether accounts hold a license for Harness modules,
+// so that gating operations on a license does not cost an API call each
+// time. It is safe for concurrent use.
+type LicenseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[licenseKey]licenseEntry
+}
+
+type licenseKey struct {
+	account string
+	module  string
+}
+
+type licenseEntry struct {
+	licensed bool
+	expires  time.Time
+}
+
+// NewLicenseCache returns a LicenseCache that remembers whether a module is
+// licensed for the supplied duration.
+func NewLicenseCache(ttl time.Duration) *LicenseCache {
+	return &LicenseCache{ttl: ttl, entries: map[licenseKey]licenseEntry{}}
+}
+
+// Licensed returns whether the supplied account holds an active license for
+// the supplied module, fetching its licenses if they are not cached. Errors
+// are not cached.
+func (c *LicenseCache) Licensed(ctx context.Context, account, module string, fetch LicenseFetcher) (bool, error) {
+	k := licenseKey{account: account, module: module}
+	now := time.Now()
+
+	c.mu.Lock()
+	e, ok := c.entries[k]
+	c.mu.Unlock()
+	if ok && now.Before(e.expires) {
+		return e.licensed, nil
+	}
+
+	ls, err := fetch(ctx, account, module)
+	if err != nil {
+		return false, err
+	}
+	licensed := false
+	for _, l := range ls {
+		if l.Status == licenseStatusActive {
+			licensed = true
+			break
+		}
+	}
+
+	c.mu.Lock()
+	c.entries[k] = licenseEntry{licensed: licensed, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return licensed, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestLicensed(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		licensed bool
+		err      error
+		fetches  int
+	}
+	cases := map[string]struct {
+		reason   string
+		ttl      time.Duration
+		licenses []nextgen.ModuleLicense
+		err      error
+		want     want
+	}{
+		"Active": {
+			reason:   "An account with an active license should be licensed, and the result cached.",
+			ttl:      time.Hour,
+			licenses: []nextgen.ModuleLicense{{Status: "EXPIRED"}, {Status: licenseStatusActive}},
+			want:     want{licensed: true, fetches: 1},
+		},
+		"Expired": {
+			reason:   "An account whose licenses have all expired should not be licensed.",
+			ttl:      time.Hour,
+			licenses: []nextgen.ModuleLicense{{Status: "EXPIRED"}},
+			want:     want{licensed: false, fetches: 1},
+		},
+		"NotCached": {
+			reason:   "Licenses should be fetched again once the cached result expires.",
+			ttl:      0,
+			licenses: []nextgen.ModuleLicense{{Status: licenseStatusActive}},
+			want:     want{licensed: true, fetches: 2},
+		},
+		"Error": {
+			reason: "Errors fetching licenses should be returned and not cached.",
+			ttl:    time.Hour,
+			err:    errBoom,
+			want:   want{err: errBoom, fetches: 2},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetches := 0
+			fetch := func(_ context.Context, _, _ string) ([]nextgen.ModuleLicense, error) {
+				fetches++
+				return tc.licenses, tc.err
+			}
+			c := NewLicenseCache(tc.ttl)
+
+			var licensed bool
+			var err error
+			for i := 0; i < 2; i++ {
+				licensed, err = c.Licensed(context.Background(), "account", ModuleCD, fetch)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nLicensed(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.licensed, licensed); diff != "" {
+				t.Errorf("\n%s\nLicensed(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.fetches, fetches); diff != "" {
+				t.Errorf("\n%s\nLicensed(...): -want fetches, +got fetches:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// codeNoResponse is the code label of requests that got no response, e.g.
+// because they timed out.
+const codeNoResponse = "none"
+
+// API request metrics, labeled by operation, i.e. the request's method and
+// path with identifiers replaced, so that there is a bounded number of series
+// regardless of how many resources are managed.
+var (
+	apiRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "requests_total",
+		Help:      "Number of requests made to the Harness API, by operation and status code.",
+	}, []string{"operation", "code"})
+
+	apiRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of requests made to the Harness API, by operation.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"operation"})
+
+	apiRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "request_errors_total",
+		Help:      "Number of requests made to the Harness API that failed or got an error status, by operation and status code.",
+	}, []string{"operation", "code"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(apiRequests, apiRequestDuration, apiRequestErrors)
+}
+
+// pathSegments are the segments of Harness API paths that name an API or a
+// type of resource rather than identify one.
+var pathSegments = map[string]bool{
+	"gitops": true, "ng": true, "api": true, "v1": true,
+	"agents": true, "applications": true, "clusters": true, "repositories": true,
+	"certificates": true, "gpgkeys": true, "licenses": true, "modules": true, "connectors": true, "projects": true,
+	"deploy.yaml": true, "sync": true, "validate": true,
+}
+
+// NewMetricsTransport returns an http.RoundTripper that records the count,
+// latency and errors of each request made to the Harness API. Requests are
+// delegated to base, or to http.DefaultTransport if base is nil.
+func NewMetricsTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &metricsTransport{base: base}
+}
+
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	op := operation(req)
+	start := time.Now()
+	rsp, err := t.base.RoundTrip(req)
+	apiRequestDuration.WithLabelValues(op).Observe(time.Since(start).Seconds())
+
+	code := codeNoResponse
+	if rsp != nil {
+		code = strconv.Itoa(rsp.StatusCode)
+	}
+	apiRequests.WithLabelValues(op, code).Inc()
+	if err != nil || rsp == nil || rsp.StatusCode >= http.StatusBadRequest {
+		apiRequestErrors.WithLabelValues(op, code).Inc()
+	}
+	return rsp, err
+}
+
+// operation returns the method and path of the supplied request, with
+// segments that identify a resource replaced by {id}. Any path prefix of the
+// base URL, e.g. /gateway, is dropped.
+func operation(req *http.Request) string {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	for i, s := range segments {
+		if s == "gitops" || s == "ng" {
+			segments = segments[i:]
+			break
+		}
+	}
+	for i, s := range segments {
+		if !pathSegments[s] {
+			segments[i] = "{id}"
+		}
+	}
+	return req.Method + " /" + strings.Join(segments, "/")
+}
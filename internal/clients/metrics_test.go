@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestOperation(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		method string
+		url    string
+		want   string
+	}{
+		"Agent": {
+			reason: "The agent identifier should be replaced.",
+			method: http.MethodGet,
+			url:    "https://app.harness.io/gitops/api/v1/agents/agent?accountIdentifier=account",
+			want:   "GET /gitops/api/v1/agents/{id}",
+		},
+		"Repository": {
+			reason: "The identifiers of nested resources should be replaced.",
+			method: http.MethodPut,
+			url:    "https://app.harness.io/gitops/api/v1/agents/agent/repositories/guestbook",
+			want:   "PUT /gitops/api/v1/agents/{id}/repositories/{id}",
+		},
+		"Action": {
+			reason: "Actions on resources should be kept.",
+			method: http.MethodPost,
+			url:    "https://app.harness.io/gitops/api/v1/agents/agent/repositories/validate",
+			want:   "POST /gitops/api/v1/agents/{id}/repositories/validate",
+		},
+		"BaseURLPath": {
+			reason: "The path of the base URL should be dropped.",
+			method: http.MethodGet,
+			url:    "https://harness.example.org/gateway/ng/api/licenses/modules/account",
+			want:   "GET /ng/api/licenses/modules/{id}",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			req, err := http.NewRequest(tc.method, tc.url, nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, operation(req)); diff != "" {
+				t.Errorf("\n%s\noperation(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMetricsTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gitops/api/v1/agents/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	const op = "GET /gitops/api/v1/agents/{id}"
+	before := map[string]float64{
+		"OK":       testutil.ToFloat64(apiRequests.WithLabelValues(op, "200")),
+		"NotFound": testutil.ToFloat64(apiRequests.WithLabelValues(op, "404")),
+		"Errors":   testutil.ToFloat64(apiRequestErrors.WithLabelValues(op, "404")),
+	}
+
+	c := &http.Client{Transport: NewMetricsTransport(nil)}
+	for _, id := range []string{"agent", "other", "missing"} {
+		rsp, err := c.Get(srv.URL + "/gitops/api/v1/agents/" + id)
+		if err != nil {
+			t.Fatalf("Get(...): %v", err)
+		}
+		_ = rsp.Body.Close()
+	}
+
+	cases := map[string]struct {
+		got  float64
+		want float64
+	}{
+		"OK":       {got: testutil.ToFloat64(apiRequests.WithLabelValues(op, "200")) - before["OK"], want: 2},
+		"NotFound": {got: testutil.ToFloat64(apiRequests.WithLabelValues(op, "404")) - before["NotFound"], want: 1},
+		"Errors":   {got: testutil.ToFloat64(apiRequestErrors.WithLabelValues(op, "404")) - before["Errors"], want: 1},
+	}
+	for name, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("RoundTrip(...): %s: want %v, got %v", name, tc.want, tc.got)
+		}
+	}
+	if got := testutil.CollectAndCount(apiRequestDuration, "harness_api_request_duration_seconds"); got == 0 {
+		t.Errorf("RoundTrip(...): want request latency to be observed")
+	}
+}
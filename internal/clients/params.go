@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"github.com/antihax/optional"
+)
+
+// StringValue returns the string the supplied pointer points to, or an empty
+// string if it is nil.
+func StringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// OptionalString returns the string the supplied pointer points to as an
+// optional query parameter. It is omitted if it is nil or empty.
+func OptionalString(s *string) optional.String {
+	if s == nil || *s == "" {
+		return optional.EmptyString()
+	}
+	return optional.NewString(*s)
+}
+
+// ScopeOpts returns the supplied organization and project identifiers as the
+// optional query parameters the Harness API accepts. An identifier that is
+// nil or empty is omitted, scoping requests to the account or organization.
+func ScopeOpts(org, project *string) (optional.String, optional.String) {
+	return OptionalString(org), OptionalString(project)
+}
+
+// SameStrings reports whether the supplied slices hold the same strings, in
+// any order. A nil slice is considered equal to an empty one.
+func SameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[string]int, len(a))
+	for _, s := range a {
+		seen[s]++
+	}
+	for _, s := range b {
+		if seen[s] == 0 {
+			return false
+		}
+		seen[s]--
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"testing"
+
+	"github.com/antihax/optional"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestScopeOpts(t *testing.T) {
+	org, project, empty := "org", "project", ""
+
+	type want struct {
+		org     optional.String
+		project optional.String
+	}
+
+	cases := map[string]struct {
+		reason  string
+		org     *string
+		project *string
+		want    want
+	}{
+		"Account": {
+			reason: "Requests without an organization or project should be scoped to the account.",
+			want:   want{org: optional.EmptyString(), project: optional.EmptyString()},
+		},
+		"Empty": {
+			reason: "Empty identifiers should be omitted.",
+			org:    &empty,
+			want:   want{org: optional.EmptyString(), project: optional.EmptyString()},
+		},
+		"Project": {
+			reason:  "Requests with an organization and project should be scoped to the project.",
+			org:     &org,
+			project: &project,
+			want:    want{org: optional.NewString(org), project: optional.NewString(project)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, p := ScopeOpts(tc.org, tc.project)
+			if diff := cmp.Diff(tc.want, want{org: o, project: p}, cmp.AllowUnexported(want{}, optional.String{})); diff != "" {
+				t.Errorf("\n%s\nScopeOpts(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSameStrings(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a      []string
+		b      []string
+		want   bool
+	}{
+		"NilAndEmpty": {
+			reason: "A nil slice should equal an empty one.",
+			b:      []string{},
+			want:   true,
+		},
+		"AnyOrder": {
+			reason: "The order of the strings should not matter.",
+			a:      []string{"a", "b"},
+			b:      []string{"b", "a"},
+			want:   true,
+		},
+		"Duplicates": {
+			reason: "Each string should be counted as often as it occurs.",
+			a:      []string{"a", "a"},
+			b:      []string{"a", "b"},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, SameStrings(tc.a, tc.b)); diff != "" {
+				t.Errorf("\n%s\nSameStrings(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
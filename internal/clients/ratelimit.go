@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Rate-limit headers returned by the Harness API.
+const (
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+)
+
+// Reset header values below this are treated as seconds until the reset
+// rather than as a Unix timestamp.
+const maxRelativeReset = 1 << 30
+
+// Rate-limit budget metrics, labeled by the ProviderConfig whose credentials
+// the budget belongs to.
+var (
+	rateLimitLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "rate_limit_limit",
+		Help:      "Number of requests allowed in the current Harness rate-limit window.",
+	}, []string{"providerconfig"})
+
+	rateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "rate_limit_remaining",
+		Help:      "Number of requests remaining in the current Harness rate-limit window.",
+	}, []string{"providerconfig"})
+
+	rateLimitReset = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "harness",
+		Subsystem: "api",
+		Name:      "rate_limit_reset_timestamp_seconds",
+		Help:      "Unix time at which the current Harness rate-limit window resets.",
+	}, []string{"providerconfig"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(rateLimitLimit, rateLimitRemaining, rateLimitReset)
+}
+
+// NewRateLimitTransport returns an http.RoundTripper that records the
+// rate-limit budget Harness reports on each response made with the supplied
+// ProviderConfig's credentials. Requests are delegated to base, or to
+// http.DefaultTransport if base is nil.
+func NewRateLimitTransport(base http.RoundTripper, providerConfig string) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{base: base, providerConfig: providerConfig}
+}
+
+type rateLimitTransport struct {
+	base           http.RoundTripper
+	providerConfig string
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rsp, err := t.base.RoundTrip(req)
+	if rsp != nil {
+		t.record(rsp.Header, time.Now())
+	}
+	return rsp, err
+}
+
+func (t *rateLimitTransport) record(h http.Header, now time.Time) {
+	if v, ok := headerFloat(h, HeaderRateLimitLimit); ok {
+		rateLimitLimit.WithLabelValues(t.providerConfig).Set(v)
+	}
+	if v, ok := headerFloat(h, HeaderRateLimitRemaining); ok {
+		rateLimitRemaining.WithLabelValues(t.providerConfig).Set(v)
+	}
+	if v, ok := headerFloat(h, HeaderRateLimitReset); ok {
+		if v < maxRelativeReset {
+			v += float64(now.Unix())
+		}
+		rateLimitReset.WithLabelValues(t.providerConfig).Set(v)
+	}
+}
+
+func headerFloat(h http.Header, key string) (float64, bool) {
+	s := h.Get(key)
+	if s == "" {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set(HeaderRateLimitLimit, "1000")
+		w.Header().Set(HeaderRateLimitRemaining, "42")
+		w.Header().Set(HeaderRateLimitReset, "1700000000")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: NewRateLimitTransport(nil, "example")}
+	rsp, err := c.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+	_ = rsp.Body.Close()
+
+	cases := map[string]struct {
+		got  float64
+		want float64
+	}{
+		"Limit":     {got: testutil.ToFloat64(rateLimitLimit.WithLabelValues("example")), want: 1000},
+		"Remaining": {got: testutil.ToFloat64(rateLimitRemaining.WithLabelValues("example")), want: 42},
+		"Reset":     {got: testutil.ToFloat64(rateLimitReset.WithLabelValues("example")), want: 1700000000},
+	}
+	for name, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("RoundTrip(...): %s: want %v, got %v", name, tc.want, tc.got)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-retryablehttp"
+)
+
+// RetryPolicy returns a retryablehttp.CheckRetry that retries requests as
+// retryablehttp.DefaultRetryPolicy does, but never once the request's context
+// is done, nor when its deadline would pass before the shortest wait between
+// retries. Retrying then would only block the reconcile until the deadline and
+// replace the last response, which explains why the request failed, with a
+// context error.
+func RetryPolicy(minWait time.Duration) retryablehttp.CheckRetry {
+	return func(ctx context.Context, resp *http.Response, err error) (bool, error) {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		retry, cerr := retryablehttp.DefaultRetryPolicy(ctx, resp, err)
+		if !retry || cerr != nil {
+			return retry, cerr
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < minWait {
+			return false, nil
+		}
+		return true, nil
+	}
+}
+
+// Backoff is a retryablehttp.Backoff that waits as long as a rate limited
+// response's Retry-After header asks, capped at max, and backs off
+// exponentially otherwise. Unlike retryablehttp.DefaultBackoff it honors a
+// Retry-After date as well as a number of seconds, and never waits longer
+// than max, so a large Retry-After cannot stall a reconcile indefinitely.
+func Backoff(min, max time.Duration, attemptNum int, resp *http.Response) time.Duration {
+	if resp != nil && (resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable) {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if wait > max {
+				return max
+			}
+			return wait
+		}
+	}
+	// DefaultBackoff honors Retry-After too, so it is not passed the response.
+	return retryablehttp.DefaultBackoff(min, max, attemptNum, nil)
+}
+
+// retryAfter parses the value of a Retry-After header, which is either a
+// number of seconds or an HTTP date, into the duration to wait from now.
+func retryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+	if s, err := strconv.ParseInt(v, 10, 64); err == nil {
+		switch {
+		case s < 0:
+			return 0, false
+		case s > int64(math.MaxInt64/time.Second):
+			return math.MaxInt64, true
+		}
+		return time.Duration(s) * time.Second, true
+	}
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	if wait := t.Sub(now); wait > 0 {
+		return wait, true
+	}
+	return 0, true
+}
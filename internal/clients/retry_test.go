@@ -0,0 +1,250 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	soon, cancelSoon := context.WithTimeout(context.Background(), time.Second)
+	defer cancelSoon()
+	later, cancelLater := context.WithTimeout(context.Background(), time.Hour)
+	defer cancelLater()
+
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable}
+
+	type want struct {
+		retry bool
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		ctx    context.Context
+		resp   *http.Response
+		want   want
+	}{
+		"Success": {
+			reason: "A successful request should not be retried.",
+			ctx:    context.Background(),
+			resp:   &http.Response{StatusCode: http.StatusOK},
+			want:   want{retry: false},
+		},
+		"NoDeadline": {
+			reason: "A failed request without a deadline should be retried.",
+			ctx:    context.Background(),
+			resp:   unavailable,
+			want:   want{retry: true},
+		},
+		"DeadlineLater": {
+			reason: "A failed request whose deadline leaves time to wait should be retried.",
+			ctx:    later,
+			resp:   unavailable,
+			want:   want{retry: true},
+		},
+		"DeadlineSoon": {
+			reason: "A failed request whose deadline would pass while waiting should not be retried.",
+			ctx:    soon,
+			resp:   unavailable,
+			want:   want{retry: false},
+		},
+		"Cancelled": {
+			reason: "A request whose context is done should not be retried, and should return the context's error.",
+			ctx:    cancelled,
+			resp:   unavailable,
+			want:   want{retry: false, err: context.Canceled},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			retry, err := RetryPolicy(time.Minute)(tc.ctx, tc.resp, nil)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRetryPolicy(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.retry, retry); diff != "" {
+				t.Errorf("\n%s\nRetryPolicy(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestServiceCancel asserts that a Service stops retrying a failing request as
+// soon as its context is cancelled, rather than once its retries are spent.
+func TestServiceCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	url, dedup := srv.URL, false
+	pc := &apisv1alpha1.ProviderConfig{Spec: apisv1alpha1.ProviderConfigSpec{BaseURL: &url, DeduplicateReads: &dedup}}
+	svc, err := NewService(pc, []byte(`{"apiKey":"key"}`))
+	if err != nil {
+		t.Fatalf("NewService(...): %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, _, err = svc.AgentApi.AgentServiceForServerGet(svc.Authorize(ctx), "agent", "account", nil)
+	if took := time.Since(start); took > 2*time.Second {
+		t.Errorf("AgentServiceForServerGet(...): want a prompt return once cancelled, took %s", took)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("AgentServiceForServerGet(...): want %q, got %v", context.Canceled, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	limited := func(code int, retryAfter string) *http.Response {
+		r := &http.Response{StatusCode: code, Header: http.Header{}}
+		if retryAfter != "" {
+			r.Header.Set("Retry-After", retryAfter)
+		}
+		return r
+	}
+
+	cases := map[string]struct {
+		reason  string
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		"NoResponse": {
+			reason:  "Without a response the wait should back off exponentially.",
+			attempt: 2,
+			want:    4 * time.Second,
+		},
+		"NoRetryAfter": {
+			reason:  "A rate limited response without a Retry-After should back off exponentially.",
+			attempt: 1,
+			resp:    limited(http.StatusTooManyRequests, ""),
+			want:    2 * time.Second,
+		},
+		"RetryAfterSeconds": {
+			reason: "A Retry-After number of seconds should be honored.",
+			resp:   limited(http.StatusTooManyRequests, "3"),
+			want:   3 * time.Second,
+		},
+		"RetryAfterDate": {
+			reason: "A Retry-After date in the past should be honored by retrying immediately.",
+			resp:   limited(http.StatusTooManyRequests, "Mon, 02 Jan 2006 15:04:05 GMT"),
+			want:   0,
+		},
+		"RetryAfterCapped": {
+			reason: "A Retry-After beyond the maximum wait should be capped at it.",
+			resp:   limited(http.StatusTooManyRequests, "3600"),
+			want:   10 * time.Second,
+		},
+		"RetryAfterOverflow": {
+			reason: "A Retry-After too large to represent should be capped at the maximum wait.",
+			resp:   limited(http.StatusTooManyRequests, "99999999999999"),
+			want:   10 * time.Second,
+		},
+		"RetryAfterInvalid": {
+			reason:  "An unparseable Retry-After should be ignored.",
+			attempt: 1,
+			resp:    limited(http.StatusTooManyRequests, "soon"),
+			want:    2 * time.Second,
+		},
+		"OtherStatus": {
+			reason:  "A Retry-After on a response that is not rate limited should be ignored.",
+			attempt: 1,
+			resp:    limited(http.StatusInternalServerError, "3"),
+			want:    2 * time.Second,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Backoff(time.Second, 10*time.Second, tc.attempt, tc.resp)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nBackoff(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		wait time.Duration
+		ok   bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		v      string
+		want   want
+	}{
+		"Empty": {
+			reason: "An absent header should not be parsed.",
+			want:   want{ok: false},
+		},
+		"Seconds": {
+			reason: "A number of seconds should be parsed.",
+			v:      " 120 ",
+			want:   want{wait: 2 * time.Minute, ok: true},
+		},
+		"Negative": {
+			reason: "A negative number of seconds is invalid.",
+			v:      "-1",
+			want:   want{ok: false},
+		},
+		"FutureDate": {
+			reason: "An HTTP date should be parsed relative to now.",
+			v:      "Sat, 01 Jan 2022 00:00:30 GMT",
+			want:   want{wait: 30 * time.Second, ok: true},
+		},
+		"PastDate": {
+			reason: "An HTTP date in the past should mean not waiting.",
+			v:      "Fri, 31 Dec 2021 23:59:00 GMT",
+			want:   want{wait: 0, ok: true},
+		},
+		"Invalid": {
+			reason: "A value that is neither seconds nor a date is invalid.",
+			v:      "later",
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			wait, ok := retryAfter(tc.v, now)
+			if diff := cmp.Diff(tc.want, want{wait: wait, ok: ok}, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\nretryAfter(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
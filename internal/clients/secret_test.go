@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretValue(t *testing.T) {
+	errBoom := errors.New("boom")
+	ref := &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "creds"}, Key: "token"}
+	withSecret := func(data map[string][]byte) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			if key.Namespace != ref.Namespace || key.Name != ref.Name {
+				return errors.Errorf("unexpected secret %s", key)
+			}
+			obj.(*corev1.Secret).Data = data
+			return nil
+		}
+	}
+
+	type want struct {
+		value string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		ref    *xpv1.SecretKeySelector
+		want   want
+	}{
+		"NoReference": {
+			reason: "No secret should be read if none is referenced.",
+			get:    test.NewMockGetFn(errBoom),
+		},
+		"Found": {
+			reason: "The value of the referenced key should be returned.",
+			get:    withSecret(map[string][]byte{"token": []byte("s3cr3t")}),
+			ref:    ref,
+			want:   want{value: "s3cr3t"},
+		},
+		"MissingKey": {
+			reason: "A secret without the referenced key should return an error.",
+			get:    withSecret(map[string][]byte{}),
+			ref:    ref,
+			want:   want{err: errors.Errorf(errMissingSecret, "token", "crossplane-system", "creds")},
+		},
+		"GetError": {
+			reason: "Errors getting the secret should be returned.",
+			get:    test.NewMockGetFn(errBoom),
+			ref:    ref,
+			want:   want{err: errors.Wrapf(errBoom, errGetSecret, "crossplane-system", "creds")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := SecretValue(context.Background(), &test.MockClient{MockGet: tc.get}, tc.ref)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSecretValue(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.value, got); diff != "" {
+				t.Errorf("\n%s\nSecretValue(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
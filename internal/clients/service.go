@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+const (
+	errGetBaseURL       = "cannot determine Harness base URL"
+	errGetImpersonation = "cannot determine impersonated principal"
+	errGetCredentials   = "cannot get Harness credentials"
+	errGetHTTPClient    = "cannot configure Harness HTTP client"
+
+	errSecretStoresDisabled = "credentials cannot be read from a secret store unless external secret stores are enabled"
+)
+
+// A Service is a client of the Harness API. It is safe for concurrent use by
+// multiple reconcilers: it is never mutated once built, and the API key is
+// supplied per request via the context rather than set on its shared
+// configuration.
+type Service struct {
+	*nextgen.APIClient
+
+	// APIKey authenticates requests. It is extracted from the credentials
+	// of the ProviderConfig the Service was built for.
+	APIKey string
+
+	// DefaultTags are the ProviderConfig's default tags, which managed
+	// resources merge beneath their own using MergeTags.
+	DefaultTags map[string]string
+}
+
+// Authorize returns a context that authenticates Harness API requests.
+func (s *Service) Authorize(ctx context.Context) context.Context {
+	return context.WithValue(ctx, nextgen.ContextAPIKey, nextgen.APIKey{Key: s.APIKey})
+}
+
+// NewService returns a Service configured by the supplied ProviderConfig,
+// authenticated using the supplied credentials. The credentials are a bare
+// API key if the ProviderConfig reads them from an API key file, and a JSON
+// document as parsed by ParseCredentials otherwise.
+func NewService(pc *apisv1alpha1.ProviderConfig, creds []byte) (*Service, error) {
+	c := APICredentials{APIKey: string(creds)}
+	if pc.Spec.Credentials.APIKeyPath == nil {
+		var err error
+		if c, err = ParseCredentials(creds); err != nil {
+			return nil, errors.Wrap(err, errGetCredentials)
+		}
+	}
+
+	baseURL, err := BaseURL(pc.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetBaseURL)
+	}
+
+	principal, err := ImpersonatePrincipal(pc.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetImpersonation)
+	}
+	hc, err := HTTPClient(pc.Spec)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetHTTPClient)
+	}
+
+	// Metrics are recorded beneath deduplication, so that they count the
+	// requests actually sent to Harness.
+	base := NewMetricsTransport(Transport(hc))
+	if pc.Spec.DeduplicateReads == nil || *pc.Spec.DeduplicateReads {
+		base = NewDedupTransport(base)
+	}
+	transport := NewRateLimitTransport(base, pc.GetName())
+	if principal != "" {
+		transport = NewImpersonationTransport(transport, principal)
+	}
+
+	config := nextgen.NewConfiguration()
+	config.BasePath = baseURL
+
+	config.HTTPClient = &retryablehttp.Client{
+		RetryMax:     hc.RetryMax,
+		RetryWaitMin: hc.RetryWaitMin,
+		RetryWaitMax: hc.RetryWaitMax,
+		HTTPClient: &http.Client{
+			Timeout:   hc.Timeout,
+			Transport: transport,
+		},
+		// Honor the Retry-After of rate limited responses, so that reconciles
+		// do not spend their retries before Harness accepts requests again.
+		Backoff: Backoff,
+		// Stop retrying as soon as the reconcile's context is done, so that
+		// a cancelled reconcile or a shutting down manager does not block.
+		CheckRetry: RetryPolicy(hc.RetryWaitMin),
+		// Return the last response once retries are exhausted so callers
+		// can tell why the request failed, e.g. that it was rate limited.
+		ErrorHandler: retryablehttp.PassthroughErrorHandler,
+	}
+
+	return &Service{APIClient: nextgen.NewAPIClient(config), APIKey: c.APIKey, DefaultTags: pc.Spec.DefaultTags}, nil
+}
+
+// Credentials extracts the credentials of a ProviderConfig, preferring an API
+// key file over the configured credentials source. Credentials may only be
+// read from an external secret store if secretStores is true.
+func Credentials(ctx context.Context, kube client.Client, cd apisv1alpha1.ProviderCredentials, secretStores bool) ([]byte, error) {
+	if cd.APIKeyPath != nil {
+		return ReadAPIKeyFile(*cd.APIKeyPath)
+	}
+	if cd.Source == apisv1alpha1.CredentialsSourceSecretStore {
+		if !secretStores {
+			return nil, errors.New(errSecretStoresDisabled)
+		}
+		return SecretStoreCredentials(ctx, kube, cd.SecretStore, connection.RuntimeStoreBuilder)
+	}
+	return resource.CommonCredentialExtractor(ctx, cd.Source, kube, cd.CommonCredentialSelectors)
+}
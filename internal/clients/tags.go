@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Limits Harness enforces on the tags of an entity. Requests that exceed them
+// are rejected with an opaque error, so they are validated up front.
+const (
+	MaxTags           = 128
+	MaxTagKeyLength   = 128
+	MaxTagValueLength = 256
+)
+
+const (
+	errTooManyTags     = "%d tags exceed the limit of %d tags"
+	errTagKeyEmpty     = "tag keys must not be empty"
+	errTagKeyTooLong   = "key of tag %q is %d characters long, exceeding the limit of %d characters"
+	errTagValueTooLong = "value of tag %q is %d characters long, exceeding the limit of %d characters"
+)
+
+// SortedKeys returns the keys of the supplied map in ascending order. Go map
+// iteration order is random, so anything serialized or compared from a map
+// must go through SortedKeys to be deterministic.
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TagsEqual reports whether the supplied tag maps hold the same key/value
+// pairs. A nil map is considered equal to an empty one, since Harness does not
+// distinguish between them.
+func TagsEqual(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+// MergeTags returns the supplied default tags overlaid with the supplied
+// resource tags, which take precedence. It returns nil if neither has any
+// tags.
+func MergeTags(defaults, tags map[string]string) map[string]string {
+	if len(defaults) == 0 && len(tags) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(defaults)+len(tags))
+	for k, v := range defaults {
+		out[k] = v
+	}
+	for k, v := range tags {
+		out[k] = v
+	}
+	return out
+}
+
+// FormatTags serializes tags to the "key:value" form accepted by the Harness
+// APIs, ordered by key.
+func FormatTags(tags map[string]string) []string {
+	out := make([]string, 0, len(tags))
+	for _, k := range SortedKeys(tags) {
+		out = append(out, k+":"+tags[k])
+	}
+	return out
+}
+
+// ValidateTags returns an error describing the first limit the supplied tags
+// violate, if any. Tags are checked in key order so the error is stable.
+func ValidateTags(tags map[string]string) error {
+	if len(tags) > MaxTags {
+		return errors.Errorf(errTooManyTags, len(tags), MaxTags)
+	}
+	for _, k := range SortedKeys(tags) {
+		switch {
+		case k == "":
+			return errors.New(errTagKeyEmpty)
+		case utf8.RuneCountInString(k) > MaxTagKeyLength:
+			return errors.Errorf(errTagKeyTooLong, k, utf8.RuneCountInString(k), MaxTagKeyLength)
+		case utf8.RuneCountInString(tags[k]) > MaxTagValueLength:
+			return errors.Errorf(errTagValueTooLong, k, utf8.RuneCountInString(tags[k]), MaxTagValueLength)
+		}
+	}
+	return nil
+}
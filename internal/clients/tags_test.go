@@ -0,0 +1,179 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestTagsEqual(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		a      map[string]string
+		b      map[string]string
+		want   bool
+	}{
+		"BothNil": {
+			reason: "Two nil maps should be equal.",
+			want:   true,
+		},
+		"NilAndEmpty": {
+			reason: "A nil map should be equal to an empty one.",
+			a:      map[string]string{},
+			want:   true,
+		},
+		"Same": {
+			reason: "Maps with the same pairs should be equal.",
+			a:      map[string]string{"env": "dev", "team": "platform"},
+			b:      map[string]string{"team": "platform", "env": "dev"},
+			want:   true,
+		},
+		"DifferentValue": {
+			reason: "Maps with a differing value should not be equal.",
+			a:      map[string]string{"env": "dev"},
+			b:      map[string]string{"env": "prod"},
+			want:   false,
+		},
+		"DifferentKey": {
+			reason: "Maps with differing keys should not be equal.",
+			a:      map[string]string{"env": "dev"},
+			b:      map[string]string{"stage": "dev"},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, TagsEqual(tc.a, tc.b)); diff != "" {
+				t.Errorf("\n%s\nTagsEqual(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		defaults map[string]string
+		tags     map[string]string
+		want     map[string]string
+	}{
+		"Neither": {
+			reason: "No tags should be returned when there are none to merge.",
+		},
+		"DefaultsOnly": {
+			reason:   "Default tags should apply to resources without tags.",
+			defaults: map[string]string{"managed-by": "crossplane"},
+			want:     map[string]string{"managed-by": "crossplane"},
+		},
+		"Merged": {
+			reason:   "Default and resource tags should be merged.",
+			defaults: map[string]string{"managed-by": "crossplane"},
+			tags:     map[string]string{"team": "platform"},
+			want:     map[string]string{"managed-by": "crossplane", "team": "platform"},
+		},
+		"ResourceTakesPrecedence": {
+			reason:   "A resource tag should override a default tag with the same key.",
+			defaults: map[string]string{"env": "dev", "managed-by": "crossplane"},
+			tags:     map[string]string{"env": "prod"},
+			want:     map[string]string{"env": "prod", "managed-by": "crossplane"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, MergeTags(tc.defaults, tc.tags)); diff != "" {
+				t.Errorf("\n%s\nMergeTags(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestTagsDeterministic repeatedly compares and serializes the same tags to
+// catch any dependency on map iteration order, which would otherwise show up
+// as spurious drift and no-op updates on every reconcile.
+func TestTagsDeterministic(t *testing.T) {
+	desired := map[string]string{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": "6"}
+	observed := map[string]string{"f": "6", "e": "5", "d": "4", "c": "3", "b": "2", "a": "1"}
+	want := []string{"a:1", "b:2", "c:3", "d:4", "e:5", "f:6"}
+
+	for i := 0; i < 100; i++ {
+		if !TagsEqual(desired, observed) {
+			t.Fatalf("TagsEqual(...): iteration %d reported drift for identical tags", i)
+		}
+		if diff := cmp.Diff(want, FormatTags(observed)); diff != "" {
+			t.Fatalf("FormatTags(...): iteration %d: -want, +got:\n%s\n", i, diff)
+		}
+	}
+}
+
+func TestValidateTags(t *testing.T) {
+	many := map[string]string{}
+	for i := 0; i <= MaxTags; i++ {
+		many[fmt.Sprintf("tag-%d", i)] = "v"
+	}
+	long := func(n int) string { return strings.Repeat("é", n) }
+
+	cases := map[string]struct {
+		reason string
+		tags   map[string]string
+		want   error
+	}{
+		"None": {
+			reason: "No tags should be valid.",
+		},
+		"AtLimits": {
+			reason: "Tags exactly at the limits should be valid, counting characters rather than bytes.",
+			tags:   map[string]string{long(MaxTagKeyLength): long(MaxTagValueLength)},
+		},
+		"TooMany": {
+			reason: "More tags than Harness allows should be rejected.",
+			tags:   many,
+			want:   errors.Errorf(errTooManyTags, MaxTags+1, MaxTags),
+		},
+		"EmptyKey": {
+			reason: "A tag with an empty key should be rejected.",
+			tags:   map[string]string{"": "v"},
+			want:   errors.New(errTagKeyEmpty),
+		},
+		"KeyTooLong": {
+			reason: "A tag key longer than Harness allows should be rejected.",
+			tags:   map[string]string{long(MaxTagKeyLength + 1): "v"},
+			want:   errors.Errorf(errTagKeyTooLong, long(MaxTagKeyLength+1), MaxTagKeyLength+1, MaxTagKeyLength),
+		},
+		"ValueTooLong": {
+			reason: "A tag value longer than Harness allows should be rejected.",
+			tags:   map[string]string{"team": long(MaxTagValueLength + 1)},
+			want:   errors.Errorf(errTagValueTooLong, "team", MaxTagValueLength+1, MaxTagValueLength),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, ValidateTags(tc.tags), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateTags(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
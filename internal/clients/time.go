@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The range of seconds a V1Time may represent; 0001-01-01T00:00:00Z to
+// 9999-12-31T23:59:59Z inclusive.
+const (
+	minV1TimeSeconds = -62135596800
+	maxV1TimeSeconds = 253402300799
+)
+
+const (
+	errParseSeconds      = "cannot parse seconds %q"
+	errSecondsOutOfRange = "seconds %d out of range"
+	errNanosOutOfRange   = "nanos %d out of range"
+)
+
+// TimeFromV1 converts a Harness V1Time to a metav1.Time. A nil or zero V1Time
+// converts to nil, since Harness omits timestamps it does not know.
+func TimeFromV1(t *nextgen.V1Time) (*metav1.Time, error) {
+	if t == nil || (t.Seconds == "" && t.Nanos == 0) {
+		return nil, nil
+	}
+	s := t.Seconds
+	if s == "" {
+		s = "0"
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return nil, errors.Wrapf(err, errParseSeconds, t.Seconds)
+	}
+	if sec < minV1TimeSeconds || sec > maxV1TimeSeconds {
+		return nil, errors.Errorf(errSecondsOutOfRange, sec)
+	}
+	if t.Nanos < 0 || t.Nanos > 999999999 {
+		return nil, errors.Errorf(errNanosOutOfRange, t.Nanos)
+	}
+	mt := metav1.NewTime(time.Unix(sec, int64(t.Nanos)).UTC())
+	return &mt, nil
+}
+
+// V1FromTime converts a metav1.Time to a Harness V1Time. A nil or zero time
+// converts to nil.
+func V1FromTime(t *metav1.Time) *nextgen.V1Time {
+	if t == nil || t.IsZero() {
+		return nil
+	}
+	return &nextgen.V1Time{
+		Seconds: strconv.FormatInt(t.Unix(), 10),
+		Nanos:   int32(t.Nanosecond()),
+	}
+}
+
+// FormatV1Time renders a Harness V1Time as an RFC3339 string. A nil or zero
+// V1Time renders as an empty string.
+func FormatV1Time(t *nextgen.V1Time) (string, error) {
+	mt, err := TimeFromV1(t)
+	if err != nil || mt == nil {
+		return "", err
+	}
+	return mt.UTC().Format(time.RFC3339Nano), nil
+}
+
+// TimeFromMillis converts a Unix time in milliseconds, as the Harness
+// platform APIs report times, to a metav1.Time. Zero is treated as unset.
+func TimeFromMillis(ms int64) *metav1.Time {
+	if ms == 0 {
+		return nil
+	}
+	t := metav1.NewTime(time.UnixMilli(ms))
+	return &t
+}
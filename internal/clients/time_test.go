@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestTimeFromV1(t *testing.T) {
+	mt := func(sec, nsec int64) *metav1.Time {
+		t := metav1.NewTime(time.Unix(sec, nsec).UTC())
+		return &t
+	}
+
+	type want struct {
+		t   *metav1.Time
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		in     *nextgen.V1Time
+		want   want
+	}{
+		"Nil": {
+			reason: "A nil V1Time should convert to nil.",
+			in:     nil,
+			want:   want{},
+		},
+		"Zero": {
+			reason: "A zero V1Time should convert to nil.",
+			in:     &nextgen.V1Time{},
+			want:   want{},
+		},
+		"NanosOnly": {
+			reason: "A V1Time with only nanos should be treated as relative to the epoch.",
+			in:     &nextgen.V1Time{Nanos: 5},
+			want:   want{t: mt(0, 5)},
+		},
+		"Valid": {
+			reason: "A valid V1Time should convert to the equivalent time.",
+			in:     &nextgen.V1Time{Seconds: "1681390800", Nanos: 123},
+			want:   want{t: mt(1681390800, 123)},
+		},
+		"LargestSeconds": {
+			reason: "The last second of year 9999 should convert.",
+			in:     &nextgen.V1Time{Seconds: strconv.FormatInt(maxV1TimeSeconds, 10)},
+			want:   want{t: mt(maxV1TimeSeconds, 0)},
+		},
+		"SecondsOutOfRange": {
+			reason: "Seconds past year 9999 should be rejected.",
+			in:     &nextgen.V1Time{Seconds: strconv.FormatInt(maxV1TimeSeconds+1, 10)},
+			want:   want{err: errors.Errorf(errSecondsOutOfRange, int64(maxV1TimeSeconds+1))},
+		},
+		"SecondsOverflow": {
+			reason: "Seconds that overflow an int64 should be rejected.",
+			in:     &nextgen.V1Time{Seconds: "99999999999999999999"},
+			want: want{err: errors.Wrapf(&strconv.NumError{
+				Func: "ParseInt",
+				Num:  "99999999999999999999",
+				Err:  strconv.ErrRange,
+			}, errParseSeconds, "99999999999999999999")},
+		},
+		"NotANumber": {
+			reason: "Non-numeric seconds should be rejected.",
+			in:     &nextgen.V1Time{Seconds: "soon"},
+			want: want{err: errors.Wrapf(&strconv.NumError{
+				Func: "ParseInt",
+				Num:  "soon",
+				Err:  strconv.ErrSyntax,
+			}, errParseSeconds, "soon")},
+		},
+		"NanosOutOfRange": {
+			reason: "Nanos of a full second or more should be rejected.",
+			in:     &nextgen.V1Time{Seconds: "1", Nanos: 1000000000},
+			want:   want{err: errors.Errorf(errNanosOutOfRange, 1000000000)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := TimeFromV1(tc.in)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nTimeFromV1(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.t, got); diff != "" {
+				t.Errorf("\n%s\nTimeFromV1(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestV1FromTimeRoundTrip(t *testing.T) {
+	in := &nextgen.V1Time{Seconds: "1681390800", Nanos: 999999999}
+
+	mt, err := TimeFromV1(in)
+	if err != nil {
+		t.Fatalf("TimeFromV1(...): %v", err)
+	}
+	if diff := cmp.Diff(in, V1FromTime(mt)); diff != "" {
+		t.Errorf("V1FromTime(TimeFromV1(...)): -want, +got:\n%s\n", diff)
+	}
+	if got := V1FromTime(nil); got != nil {
+		t.Errorf("V1FromTime(nil): want nil, got %v", got)
+	}
+
+	s, err := FormatV1Time(in)
+	if err != nil {
+		t.Fatalf("FormatV1Time(...): %v", err)
+	}
+	if diff := cmp.Diff("2023-04-13T13:00:00.999999999Z", s); diff != "" {
+		t.Errorf("FormatV1Time(...): -want, +got:\n%s\n", diff)
+	}
+}
+
+func TestTimeFromMillis(t *testing.T) {
+	at := metav1.NewTime(time.UnixMilli(1700000000123))
+
+	cases := map[string]struct {
+		reason string
+		ms     int64
+		want   *metav1.Time
+	}{
+		"Zero": {
+			reason: "Zero milliseconds should convert to nil.",
+		},
+		"Time": {
+			reason: "Milliseconds should be converted without losing precision.",
+			ms:     1700000000123,
+			want:   &at,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, TimeFromMillis(tc.ms)); diff != "" {
+				t.Errorf("\n%s\nTimeFromMillis(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+const (
+	errGetCABundleSecret = "cannot get CA bundle secret"
+	errNoCABundleKey     = "key %q not found in CA bundle secret %s/%s"
+	errInvalidCABundle   = "tls.caBundle contains no PEM encoded certificates"
+)
+
+// ResolveCABundle reads the CA bundle the supplied ProviderConfig references
+// in a Secret, and sets it as the ProviderConfig's inline CA bundle so that
+// the Service built for it trusts the bundle. It does nothing if the
+// ProviderConfig specifies an inline CA bundle, or references none.
+func ResolveCABundle(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig) error {
+	t := pc.Spec.TLS
+	if t == nil || t.CABundle != nil || t.CABundleSecretRef == nil {
+		return nil
+	}
+	ref := t.CABundleSecretRef
+	s := &corev1.Secret{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return errors.Wrap(err, errGetCABundleSecret)
+	}
+	b, ok := s.Data[ref.Key]
+	if !ok {
+		return errors.Errorf(errNoCABundleKey, ref.Key, ref.Namespace, ref.Name)
+	}
+	bundle := string(b)
+	t.CABundle = &bundle
+	return nil
+}
+
+// TLSClientConfig returns the TLS configuration of requests to the Harness
+// API a ProviderConfig specifies, or nil if it specifies none. Any CA bundle
+// the ProviderConfig references in a Secret must have been resolved by
+// ResolveCABundle.
+func TLSClientConfig(spec apisv1alpha1.ProviderConfigSpec) (*tls.Config, error) {
+	t := spec.TLS
+	if t == nil {
+		return nil, nil
+	}
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.InsecureSkipVerify != nil {
+		c.InsecureSkipVerify = *t.InsecureSkipVerify //nolint:gosec // Explicitly requested by the ProviderConfig.
+	}
+	if t.CABundle != nil {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM([]byte(*t.CABundle)) {
+			return nil, errors.New(errInvalidCABundle)
+		}
+		c.RootCAs = pool
+	}
+	return c, nil
+}
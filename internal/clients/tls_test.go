@@ -0,0 +1,169 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clients
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+)
+
+func TestResolveCABundle(t *testing.T) {
+	errBoom := errors.New("boom")
+	inline := "inline"
+	ref := &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "harness-ca"}, Key: "ca.crt"}
+	withSecret := func(data map[string][]byte) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			obj.(*corev1.Secret).Data = data
+			return nil
+		}
+	}
+
+	type want struct {
+		bundle *string
+		err    error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		tls    *apisv1alpha1.TLSConfig
+		want   want
+	}{
+		"NoTLS": {
+			reason: "A ProviderConfig without TLS configuration should be left alone.",
+		},
+		"Inline": {
+			reason: "An inline CA bundle should take precedence over a Secret.",
+			get:    test.NewMockGetFn(errBoom),
+			tls:    &apisv1alpha1.TLSConfig{CABundle: &inline, CABundleSecretRef: ref},
+			want:   want{bundle: &inline},
+		},
+		"Secret": {
+			reason: "A CA bundle should be read from the referenced Secret key.",
+			get:    withSecret(map[string][]byte{"ca.crt": []byte("from secret")}),
+			tls:    &apisv1alpha1.TLSConfig{CABundleSecretRef: ref},
+			want:   want{bundle: func() *string { s := "from secret"; return &s }()},
+		},
+		"MissingKey": {
+			reason: "A Secret without the referenced key should return an error.",
+			get:    withSecret(map[string][]byte{}),
+			tls:    &apisv1alpha1.TLSConfig{CABundleSecretRef: ref},
+			want:   want{err: errors.Errorf(errNoCABundleKey, "ca.crt", "crossplane-system", "harness-ca")},
+		},
+		"GetError": {
+			reason: "Errors getting the Secret should be returned.",
+			get:    test.NewMockGetFn(errBoom),
+			tls:    &apisv1alpha1.TLSConfig{CABundleSecretRef: ref},
+			want:   want{err: errors.Wrap(errBoom, errGetCABundleSecret)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &apisv1alpha1.ProviderConfig{Spec: apisv1alpha1.ProviderConfigSpec{TLS: tc.tls}}
+			err := ResolveCABundle(context.Background(), &test.MockClient{MockGet: tc.get}, pc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveCABundle(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			var got *string
+			if pc.Spec.TLS != nil {
+				got = pc.Spec.TLS.CABundle
+			}
+			if diff := cmp.Diff(tc.want.bundle, got); diff != "" {
+				t.Errorf("\n%s\nResolveCABundle(...): -want bundle, +got bundle:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestTLSClientConfig asserts that requests to a Harness API served with a
+// certificate of a private CA succeed only when the CA is trusted, or
+// verification is skipped.
+func TestTLSClientConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	invalid := "not a certificate"
+	insecure := true
+
+	type want struct {
+		reachable bool
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		tls    *apisv1alpha1.TLSConfig
+		want   want
+	}{
+		"SystemRoots": {
+			reason: "A server whose CA is not trusted should not be reachable.",
+			want:   want{reachable: false},
+		},
+		"CABundle": {
+			reason: "A server whose CA is in the CA bundle should be reachable.",
+			tls:    &apisv1alpha1.TLSConfig{CABundle: &ca},
+			want:   want{reachable: true},
+		},
+		"InsecureSkipVerify": {
+			reason: "Any server should be reachable when verification is skipped.",
+			tls:    &apisv1alpha1.TLSConfig{InsecureSkipVerify: &insecure},
+			want:   want{reachable: true},
+		},
+		"InvalidCABundle": {
+			reason: "A CA bundle without certificates should be rejected.",
+			tls:    &apisv1alpha1.TLSConfig{CABundle: &invalid},
+			want:   want{err: errors.New(errInvalidCABundle)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			o, err := HTTPClient(apisv1alpha1.ProviderConfigSpec{TLS: tc.tls})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nHTTPClient(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			c := &http.Client{Transport: Transport(o)}
+			rsp, err := c.Get(srv.URL)
+			if err == nil {
+				_ = rsp.Body.Close()
+			}
+			if diff := cmp.Diff(tc.want.reachable, err == nil); diff != "" {
+				t.Errorf("\n%s\nTransport(...): -want reachable, +got reachable:\n%s\nerror: %v", tc.reason, diff, err)
+			}
+		})
+	}
+}
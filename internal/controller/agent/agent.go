@@ -19,29 +19,39 @@ package agent
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"os"
+	"strings"
 	"time"
 
+	"github.com/antihax/optional"
 	"github.com/harness/harness-go-sdk/harness/nextgen"
-	"github.com/hashicorp/go-retryablehttp"
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection"
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
 	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/dependents"
+	"github.com/crossplane/provider-harness/internal/details"
 	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+	"github.com/crossplane/provider-harness/internal/version"
 )
 
 const (
@@ -49,56 +59,112 @@ const (
 	errTrackPCUsage = "cannot track ProviderConfig usage"
 	errGetPC        = "cannot get ProviderConfig"
 	errGetCreds     = "cannot get credentials"
+	errGetCABundle  = "cannot get CA bundle"
+
+	errUnauthenticated = "Harness rejected the ProviderConfig's credentials: %s"
+	errRateLimited     = "Harness rate limit exceeded: %s"
+
+	errNoAccount   = "accountIdentifier is required"
+	errGetAgent    = "cannot get agent"
+	errUpdateAgent = "cannot update agent"
+
+	errProjectWithoutOrg = "projectIdentifier %q requires an orgIdentifier; a project scoped agent must specify the organization the project belongs to"
+
+	errIncompleteAgent = "Harness returned an incomplete agent missing %s"
+	errInvalidTags     = "invalid tags"
+	errInvalidType     = "type %q is invalid: it must be MANAGED_ARGO or CONNECTED_ARGO"
+
+	errAgentNotInAccount = "agent %q does not exist in account %q; it was previously observed in account %q"
+
+	errDeleteAgent     = "cannot delete agent"
+	errDeletionBlocked = "Harness refused to delete the agent because it is still referenced: %s"
+
+	errModuleNotLicensed = "Harness module %s is not licensed for account %s"
+
+	errGetDeployYAML = "cannot get agent install manifests"
+
+	errImmutableFields = "cannot change immutable fields of an existing agent: %s"
+	errRecreateAgent   = "cannot delete agent to recreate it"
+
+	errNamespaceNotAllowed = "namespace %q is not allowed to use ProviderConfig %q"
+
+	errGetDescription        = "cannot get description ConfigMap"
+	errMissingDescriptionKey = "key %q not found in ConfigMap %s/%s"
 
 	errNewClient = "cannot create new Service"
+
+	errDeleteDependents  = "cannot delete dependents"
+	errDependentsPending = "waiting for %d dependent resources to be deleted"
 )
 
-// A HarnessService does nothing.
-type HarnessService struct {
-	*nextgen.APIClient
-}
+const (
+	msgAgentStatus         = "Harness reports the GitOps agent as %s"
+	msgAgentError          = "Harness reports the GitOps agent as %s: %s"
+	msgHealthTransition    = "GitOps agent health changed from %s to %s"
+	msgInClusterState      = "Harness reports the GitOps agent as healthy, but its in-cluster Deployment is %s"
+	msgAgentDegraded       = "Harness reports the GitOps agent as healthy, but these components as unhealthy: %s"
+	msgComponentsUnhealthy = "Harness reports %s as unhealthy"
+	msgAccountChanged      = "agent moved from account %q to account %q; re-observed it there"
+	msgRecreating          = "deleted the agent to change immutable fields %s; recreating it"
+	msgDeletionPending     = "waiting for Harness to delete agent %q from %s"
+)
 
-var newHarnessService = func(creds []byte) (*HarnessService, error) {
-	config := nextgen.NewConfiguration()
-	config.BasePath = "https://app.harness.io"
+// reasonHealthTransition is the reason of events emitted when the health of
+// an agent changes.
+const reasonHealthTransition event.Reason = "HealthTransition"
 
-	config.HTTPClient = &retryablehttp.Client{
-		RetryMax:     10,
-		RetryWaitMin: 5 * time.Second,
-		RetryWaitMax: 10 * time.Second,
-		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		Backoff:    retryablehttp.DefaultBackoff,
-		CheckRetry: retryablehttp.DefaultRetryPolicy,
-	}
+// lastSyncedResolution is how stale an Agent's last synced time may become
+// before it is refreshed.
+const lastSyncedResolution = 5 * time.Minute
 
-	client := nextgen.NewAPIClient(config)
+// deleteRetryInterval is how long Harness may take to delete an agent before
+// the delete is requested again.
+const deleteRetryInterval = 5 * time.Minute
 
-	return &HarnessService{
-		client,
-	}, nil
-}
+// defaultAgentNamespace is the namespace an agent is installed in unless its
+// Agent specifies one.
+const defaultAgentNamespace = "harness"
+
+// Keys of the connection details published when an agent is created, or when
+// Observe finds its credentials were rotated.
+const (
+	ConnectionDetailIdentifier  = "identifier"
+	ConnectionDetailPrivateKey  = "privateKey"
+	ConnectionDetailPublicKey   = "publicKey"
+	ConnectionDetailInstallYAML = "install.yaml"
+)
 
 // Setup adds a controller that reconciles Agent managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	name := managed.ControllerName(v1alpha1.AgentGroupKind)
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.AgentGroupKind))
 
-	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	cps := []managed.ConnectionPublisher{details.NewPublisher(managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme()))}
 	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
-		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+		cps = append(cps, details.NewPublisher(connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind)))
 	}
 
-	r := managed.NewReconciler(mgr,
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
 		resource.ManagedKind(v1alpha1.AgentGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
 			kube:         mgr.GetClient(),
+			recorder:     recorder,
 			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: newHarnessService,
-		}),
+			newServiceFn: clients.NewServiceCache(clients.NewService).NewService,
+			secretStores: o.Features.Enabled(features.EnableAlphaExternalSecretStores),
+			dependentGC:  o.Features.Enabled(features.EnableAlphaAgentDependentGC),
+			inCluster:    inClusterReader(mgr, o),
+			licenses:     clients.NewLicenseCache(clients.DefaultLicenseTTL),
+			logger:       o.Logger.WithValues("controller", name),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
-		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.AgentKind)),
+		o.ManagementPolicies(),
+		// Harness assigns agent identifiers, so the external name is set
+		// when the agent is created rather than defaulted to the name.
+		managed.WithInitializers(),
+		managed.WithRecorder(recorder),
 		managed.WithConnectionPublishers(cps...))
 
 	return ctrl.NewControllerManagedBy(mgr).
@@ -109,12 +175,28 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
 }
 
+// inClusterReader returns a reader for in-cluster agent Deployments, or nil
+// if in-cluster agent health is disabled. Deployments are read uncached to
+// avoid watching every Deployment in the cluster.
+func inClusterReader(mgr ctrl.Manager, o options.Options) client.Reader {
+	if !o.Features.Enabled(features.EnableAlphaInClusterAgentHealth) {
+		return nil
+	}
+	return mgr.GetAPIReader()
+}
+
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
 	kube         client.Client
+	recorder     event.Recorder
 	usage        resource.Tracker
-	newServiceFn func(creds []byte) (*HarnessService, error)
+	newServiceFn func(pc *apisv1alpha1.ProviderConfig, creds []byte) (*clients.Service, error)
+	secretStores bool
+	dependentGC  bool
+	inCluster    client.Reader
+	licenses     *clients.LicenseCache
+	logger       logging.Logger
 }
 
 // Connect typically produces an ExternalClient by:
@@ -123,32 +205,83 @@ type connector struct {
 // 3. Getting the credentials specified by the ProviderConfig.
 // 4. Using the credentials to form a client.
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Agent)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Agent); !ok {
 		return nil, errors.New(errNotAgent)
 	}
+	return c.connect(ctx, mg)
+}
 
+func (c *connector) connect(ctx context.Context, mg resource.Managed) (*external, error) {
 	if err := c.usage.Track(ctx, mg); err != nil {
 		return nil, errors.Wrap(err, errTrackPCUsage)
 	}
 
 	pc := &apisv1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
+	if err := c.kube.Get(ctx, types.NamespacedName{Name: mg.GetProviderConfigReference().Name}, pc); err != nil {
 		return nil, errors.Wrap(err, errGetPC)
 	}
 
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	if ns := mg.GetNamespace(); ns != "" && !namespaceAllowed(pc, ns) {
+		err := errors.Errorf(errNamespaceNotAllowed, ns, pc.GetName())
+		mg.SetConditions(v1alpha1.ScopeMismatch(err.Error()))
+		return nil, err
+	}
+
+	data, err := clients.Credentials(ctx, c.kube, pc.Spec.Credentials, c.secretStores)
 	if err != nil {
 		return nil, errors.Wrap(err, errGetCreds)
 	}
 
-	svc, err := c.newServiceFn(data)
+	if err := clients.ResolveCABundle(ctx, c.kube, pc); err != nil {
+		return nil, errors.Wrap(err, errGetCABundle)
+	}
+
+	svc, err := c.newServiceFn(pc, data)
 	if err != nil {
 		return nil, errors.Wrap(err, errNewClient)
 	}
 
-	return &external{service: svc}, nil
+	return &external{service: svc, kube: c.kube, recorder: c.recorder, dependentGC: c.dependentGC, inCluster: c.inCluster, licenses: c.licenses, defaultTags: pc.Spec.DefaultTags, scopeDefaulted: defaultScope(mg, pc), logger: resourceLogger(c.logger, mg)}, nil
+}
+
+// defaultScope sets the scope of the supplied Agent or NamespacedAgent to the
+// ProviderConfig's default scope where the Agent does not specify its own. It
+// returns true if it changed the Agent's scope.
+func defaultScope(mg resource.Managed, pc *apisv1alpha1.ProviderConfig) bool {
+	d := pc.Spec.DefaultScope
+	if d == nil {
+		return false
+	}
+	switch cr := mg.(type) {
+	case *v1alpha1.Agent:
+		return cr.Spec.ForProvider.DefaultScope(d.AccountIdentifier, d.OrgIdentifier, d.ProjectIdentifier)
+	case *v1alpha1.NamespacedAgent:
+		return cr.Spec.ForProvider.DefaultScope(d.AccountIdentifier, d.OrgIdentifier, d.ProjectIdentifier)
+	}
+	return false
+}
+
+// resourceLogger returns a logger that identifies the supplied managed
+// resource in everything it logs.
+func resourceLogger(l logging.Logger, mg resource.Managed) logging.Logger {
+	if l == nil {
+		return nil
+	}
+	if ns := mg.GetNamespace(); ns != "" {
+		return l.WithValues("name", mg.GetName(), "namespace", ns)
+	}
+	return l.WithValues("name", mg.GetName())
+}
+
+// namespaceAllowed returns true if namespaced managed resources in the
+// supplied namespace may use the supplied ProviderConfig.
+func namespaceAllowed(pc *apisv1alpha1.ProviderConfig, namespace string) bool {
+	for _, ns := range pc.Spec.AllowedNamespaces {
+		if ns == namespace {
+			return true
+		}
+	}
+	return false
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
@@ -156,7 +289,30 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 type external struct {
 	// A 'client' used to connect to the external resource API. In practice this
 	// would be something like an AWS SDK client.
-	service *HarnessService
+	service *clients.Service
+
+	kube        client.Client
+	recorder    event.Recorder
+	dependentGC bool
+
+	// inCluster reads in-cluster agent Deployments. It is nil unless
+	// in-cluster agent health is enabled.
+	inCluster client.Reader
+
+	// licenses caches which Harness modules accounts are licensed for. No
+	// license check is made if it is nil.
+	licenses *clients.LicenseCache
+
+	// defaultTags are the ProviderConfig's default tags.
+	defaultTags map[string]string
+
+	// scopeDefaulted is true if the Agent's scope was defaulted from the
+	// ProviderConfig's default scope, and must be persisted.
+	scopeDefaulted bool
+
+	// logger logs anomalies that do not fail a reconcile. Nothing is logged
+	// if it is nil.
+	logger logging.Logger
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -165,43 +321,206 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotAgent)
 	}
 
-	identifier := ""
-	if cr.Spec.ForProvider.Identifier != nil {
-		identifier = *cr.Spec.ForProvider.Identifier
-	}
+	cr.Status.AtProvider.ManagedByVersion = version.Version
+
+	identifier := agentIdentifier(cr)
 
 	if cr.Spec.ForProvider.AccountIdentifier == nil {
-		log.Fatalln("AccountIndentifier is required")
+		return managed.ExternalObservation{}, errors.New(errNoAccount)
+	}
+	if err := validateScope(cr.Spec.ForProvider); err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	// An Agent that neither requests an identifier nor records one as its
+	// external name has no agent yet. Setting the external name to the
+	// identifier of an existing agent imports that agent instead.
+	if identifier == "" {
+		explain(cr, explainAbsent)
+		settleDryRun(cr, false)
+		return managed.ExternalObservation{ResourceExists: false}, nil
 	}
 
-	ctx = context.WithValue(ctx, nextgen.ContextAPIKey, nextgen.APIKey{Key: os.Getenv("HARNESS_API_KEY")})
+	ctx = c.service.Authorize(ctx)
+
+	// A deleting Agent must be able to observe that its agent is gone even if
+	// its account is no longer licensed, or it would keep its finalizer.
+	if !meta.WasDeleted(cr) {
+		if err := c.checkLicense(ctx, cr, *cr.Spec.ForProvider.AccountIdentifier, clients.ModuleCD); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
 
+	org, project := clients.ScopeOpts(cr.Spec.ForProvider.OrgIdentifier, cr.Spec.ForProvider.ProjectIdentifier)
 	agent, response, err := c.service.AgentApi.AgentServiceForServerGet(
 		ctx,
 		identifier,
-		*cr.Spec.ForProvider.AccountIdentifier, nil)
-	defer func() {
-		if response != nil {
-			err = response.Body.Close()
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-	}()
+		*cr.Spec.ForProvider.AccountIdentifier,
+		&nextgen.AgentsApiAgentServiceForServerGetOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
 
-	// log.Printf("%v\n", err)
-	// log.Printf("%+v", response)
+	if response != nil {
+		switch response.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			cr.Status.SetConditions(v1alpha1.Unauthenticated(response.Status))
+			return managed.ExternalObservation{}, errors.Errorf(errUnauthenticated, response.Status)
+		case http.StatusTooManyRequests:
+			cr.Status.SetConditions(v1alpha1.RateLimited(response.Status))
+			return managed.ExternalObservation{}, errors.Errorf(errRateLimited, response.Status)
+		}
+	}
 
-	// if response == nil || response.StatusCode == http.StatusNotFound {
-	if err != nil || (response != nil && response.StatusCode == http.StatusNotFound) {
+	// Only a 404 means the agent does not exist. Treating any other error as
+	// such would have a transient failure recreate the agent.
+	if err != nil && !clients.IsNotFound(response) {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAgent)
+	}
+	if clients.IsNotFound(response) {
+		// Creating an agent in the account an existing one was moved to
+		// would silently leave the original behind.
+		if account, prev := *cr.Spec.ForProvider.AccountIdentifier, cr.Status.AtProvider.AccountIdentifier; prev != "" && prev != account && !meta.WasDeleted(cr) {
+			err := errors.Errorf(errAgentNotInAccount, identifier, account, prev)
+			cr.Status.SetConditions(v1alpha1.ProviderConfigChanged(err.Error()))
+			return managed.ExternalObservation{}, err
+		}
+		forgetHealth(identifier, scopeOf(cr.Spec.ForProvider))
+		explain(cr, explainAbsent)
+		settleDryRun(cr, false)
 		//nolint:nilerr
 		return managed.ExternalObservation{
 			ResourceExists: false,
 		}, nil
 	}
 
-	if *agent.Health.HarnessGitopsAgent.Status == nextgen.HEALTHY_Servicev1HealthStatus {
+	// A deleting Agent only needs to know that its agent still exists. Any
+	// other check that could fail the observation, like immutable field
+	// drift, would leave it stuck with its finalizer. Harness may take a
+	// while to delete an agent, so one it still returns after the delete was
+	// requested is reported as being deleted until it is gone.
+	if meta.WasDeleted(cr) {
+		if cr.Status.AtProvider.DeletionRequestedAt != nil {
+			cr.Status.SetConditions(xpv1.Deleting().WithMessage(fmt.Sprintf(msgDeletionPending, identifier, scopeOf(cr.Spec.ForProvider))))
+		}
+		explain(cr, explainDeleting)
+		settleDryRun(cr, false)
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+	}
+
+	// Harness occasionally returns a truncated or partially populated body,
+	// e.g. while it is being rolled out. The SDK does not report bodies it
+	// cannot decode, so check for the fields any agent must have.
+	if missing := missingFields(agent); len(missing) > 0 {
+		c.log().Debug("Harness returned an incomplete agent", "identifier", identifier, "missing", missing)
+		return managed.ExternalObservation{}, errors.Errorf(errIncompleteAgent, strings.Join(missing, ", "))
+	}
+
+	cd, err := c.rotatedCredentials(ctx, cr, agent)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	observeAccount(cr)
+	cr.Status.AtProvider.Identifier = agent.Identifier
+	recordHealth(identifier, scopeOf(cr.Spec.ForProvider), agent.Health, time.Now())
+
+	// Timestamps are informational only, so a malformed one is not worth
+	// failing the observation over.
+	if t, err := clients.TimeFromV1(agent.CreatedAt); err == nil {
+		cr.Status.AtProvider.CreatedAt = t
+	}
+	if t, err := clients.TimeFromV1(agent.LastModifiedAt); err == nil {
+		cr.Status.AtProvider.LastModifiedAt = t
+	}
+
+	c.recordTransition(cr, gitopsAgentStatus(agent.Health))
+	observeHealth(cr, agent.Health)
+
+	inCluster, err := c.observeInCluster(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	cr.Status.AtProvider.InClusterState = inCluster
+
+	unhealthy := unhealthyComponents(agent.Health)
+	if agent.Health != nil {
+		cr.Status.SetConditions(v1alpha1.NotDegraded())
+		if len(unhealthy) > 0 {
+			cr.Status.SetConditions(v1alpha1.Degraded(fmt.Sprintf(msgComponentsUnhealthy, strings.Join(unhealthy, ", "))))
+		}
+	}
+
+	switch st := gitopsAgentStatus(agent.Health); st {
+	case nextgen.HEALTHY_Servicev1HealthStatus:
+		if inCluster != "" && inCluster != inClusterReady {
+			cr.Status.SetConditions(v1alpha1.NotRunning(fmt.Sprintf(msgInClusterState, inCluster)))
+			break
+		}
+		// The GitOps agent reporting healthy does not make the agent
+		// available if the components it drives, or its connection, are not.
+		if len(unhealthy) > 0 {
+			cr.Status.AtProvider.LastError = lastError(agent.Health)
+			cr.Status.SetConditions(v1alpha1.PartiallyHealthy(fmt.Sprintf(msgAgentDegraded, strings.Join(unhealthy, ", "))))
+			break
+		}
+		cr.Status.AtProvider.LastError = ""
 		cr.Status.SetConditions(xpv1.Available())
+	case nextgen.UNHEALTHY_Servicev1HealthStatus:
+		cr.Status.AtProvider.LastError = lastError(agent.Health)
+		if cr.Status.AtProvider.LastError == "" {
+			cr.Status.SetConditions(v1alpha1.Unhealthy(fmt.Sprintf(msgAgentStatus, st)))
+			break
+		}
+		cr.Status.SetConditions(v1alpha1.Unhealthy(fmt.Sprintf(msgAgentError, st, cr.Status.AtProvider.LastError)))
+	}
+
+	cr.Status.AtProvider.ServerVersion = serverVersion(agent)
+	cr.Status.AtProvider.ClientVersion = clientVersion(agent)
+	if cond, ok := versionSkew(cr.Status.AtProvider.ServerVersion, cr.Status.AtProvider.ClientVersion); ok {
+		cr.Status.SetConditions(cond)
+	}
+	cr.Status.AtProvider.UpgradeAvailable = agent.UpgradeAvailable
+	cr.Status.SetConditions(installOutdated(agent))
+
+	c.observeCounts(ctx, cr, identifier, time.Now())
+
+	// Refreshing the sync time on every poll would make every status update
+	// a real write, so it is only refreshed once it is stale.
+	if t := cr.Status.AtProvider.LastSyncedTime; t == nil || time.Since(t.Time) >= lastSyncedResolution {
+		now := metav1.Now()
+		cr.Status.AtProvider.LastSyncedTime = &now
+	}
+
+	lateInitialized := lateInitialize(&cr.Spec.ForProvider, agent, c.defaultTags)
+
+	desired, err := c.agent(ctx, cr)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	d := diffAgent(desired, agent)
+	drift := explainDiff(desired, agent, d)
+	explain(cr, drift)
+	// An observe only Agent reports its agent as out of date, but the agent
+	// is neither recreated nor updated.
+	if len(d.immutable) > 0 && !mayUpdate(cr) {
+		return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false, ResourceLateInitialized: lateInitialized || c.scopeDefaulted, ConnectionDetails: cd}, nil
+	}
+	if len(d.immutable) > 0 && cr.Spec.OnImmutableChange == v1alpha1.ImmutableChangeRecreate {
+		if dryRun(cr) {
+			c.preview(cr, msgDryRunRecreate, identifier, scopeOf(cr.Spec.ForProvider), strings.Join(d.immutable, ", "))
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+		}
+		return c.recreate(ctx, cr, agent, d.immutable)
+	}
+	if len(d.immutable) > 0 {
+		return managed.ExternalObservation{}, errors.Errorf(errImmutableFields, strings.Join(d.immutable, ", "))
+	}
+	settleDryRun(cr, d.upToDate())
+	// Only Observe knows how the agent differs, so it previews the update a
+	// dry run of Update would otherwise make.
+	if dryRun(cr) && !d.upToDate() && mayUpdate(cr) {
+		c.preview(cr, msgDryRunUpdate, identifier, scopeOf(cr.Spec.ForProvider), drift)
 	}
 
 	return managed.ExternalObservation{
@@ -213,11 +532,16 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		// Return false when the external resource exists, but it not up to date
 		// with the desired managed resource state. This lets the managed
 		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: true,
+		ResourceUpToDate: d.upToDate(),
 
-		// Return any details that may be required to connect to the external
-		// resource. These will be stored as the connection secret.
-		// ConnectionDetails: managed.ConnectionDetails{},
+		// Return true when optional parameters were filled from the agent
+		// observed in Harness, or from the ProviderConfig's default scope,
+		// so that the reconciler persists them.
+		ResourceLateInitialized: lateInitialized || c.scopeDefaulted,
+
+		// Return the agent's connection details only when its credentials
+		// were rotated, so that its connection secret is rewritten.
+		ConnectionDetails: cd,
 	}, nil
 }
 
@@ -227,102 +551,498 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotAgent)
 	}
 
+	body, err := c.agent(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	body.Identifier = agentIdentifier(cr)
+
+	if dryRun(cr) {
+		// Harness would assign an identifier to an agent created without
+		// one, so it is previewed by name.
+		name := body.Identifier
+		if name == "" {
+			name = body.Name
+		}
+		c.preview(cr, msgDryRunCreate, name, scopeOf(cr.Spec.ForProvider))
+		return managed.ExternalCreation{}, nil
+	}
+
+	ctx = c.service.Authorize(ctx)
+	agent, response, err := c.service.AgentApi.AgentServiceForServerCreate(ctx, body)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+
+	if err != nil {
+		c.recordAPIError(cr, reasonCannotCreateAgent, body.Identifier, err)
+		return managed.ExternalCreation{}, err
+	}
+	c.recordChanged(cr, reasonCreatedAgent, msgCreatedAgent, agent.Identifier)
+
+	// Harness assigns an identifier to agents created without one. It is
+	// recorded as the external name so the agent can be observed again.
+	if agent.Identifier != "" {
+		meta.SetExternalName(cr, agent.Identifier)
+	}
+	cr.Status.AtProvider.Identifier = agent.Identifier
+
+	// Harness registers agents asynchronously, so a freshly created agent
+	// usually reports no health yet. Leave it to subsequent observations to
+	// report the agent as available.
+	cr.SetConditions(xpv1.Creating())
+	cr.Status.AtProvider.State = string(gitopsAgentStatus(agent.Health))
+	if cr.GetCondition(v1alpha1.TypeRecreating).Status == corev1.ConditionTrue {
+		cr.SetConditions(v1alpha1.Recreated())
+	}
+	explain(cr, fmt.Sprintf(explainCreated, cr.Status.AtProvider.Explanation))
+
+	return managed.ExternalCreation{ConnectionDetails: c.connectionDetails(ctx, cr, agent)}, nil
+}
+
+// connectionDetails returns what is needed to install a newly created agent.
+// Harness returns the agent's credentials when it is created, and when they
+// are regenerated, so they are published from Create and again from Observe
+// once they are rotated. The install manifests are published on a best
+// effort basis; failing to fetch them does not fail the create.
+func (c *external) connectionDetails(ctx context.Context, cr *v1alpha1.Agent, agent nextgen.V1Agent) managed.ConnectionDetails {
+	cd := managed.ConnectionDetails{}
+	if agent.Identifier != "" {
+		cd[ConnectionDetailIdentifier] = []byte(agent.Identifier)
+	}
+	if agent.Credentials != nil && agent.Credentials.PrivateKey != "" {
+		cd[ConnectionDetailPrivateKey] = []byte(agent.Credentials.PrivateKey)
+	}
+	if agent.Credentials != nil && agent.Credentials.PublicKey != "" {
+		cd[ConnectionDetailPublicKey] = []byte(agent.Credentials.PublicKey)
+	}
+	if agent.Identifier == "" {
+		return cd
+	}
+
+	org, project := clients.ScopeOpts(cr.Spec.ForProvider.OrgIdentifier, cr.Spec.ForProvider.ProjectIdentifier)
+	opts := &nextgen.AgentsApiAgentServiceForServerGetDeployYamlOpts{
+		OrgIdentifier:     org,
+		ProjectIdentifier: project,
+		Namespace:         optional.NewString(agentNamespace(cr.Spec.ForProvider)),
+	}
+	yaml, response, err := c.service.AgentApi.AgentServiceForServerGetDeployYaml(ctx, agent.Identifier, agent.AccountIdentifier, opts)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		c.log().Debug(errGetDeployYAML, "identifier", agent.Identifier, "error", err)
+		return cd
+	}
+	if yaml != "" {
+		cd[ConnectionDetailInstallYAML] = []byte(yaml)
+	}
+	return cd
+}
+
+// recreate deletes the observed agent, whose supplied immutable fields differ
+// from the desired agent, so that the managed reconciler creates it again
+// with the desired fields. The agent is deleted from the scope it was
+// observed in, which may no longer be the desired scope.
+func (c *external) recreate(ctx context.Context, cr *v1alpha1.Agent, observed nextgen.V1Agent, immutable []string) (managed.ExternalObservation, error) {
+	org, project := clients.ScopeOpts(&observed.OrgIdentifier, &observed.ProjectIdentifier)
+	response, err := c.deleteAgent(ctx, observed.Identifier, observed.AccountIdentifier, org, project)
+	if err != nil && !clients.IsNotFound(response) {
+		return managed.ExternalObservation{}, errors.Wrap(err, errRecreateAgent)
+	}
+	forgetHealth(observed.Identifier, scopeOf(cr.Spec.ForProvider))
+	cr.Status.SetConditions(v1alpha1.Recreating(fmt.Sprintf(msgRecreating, strings.Join(immutable, ", "))))
+	return managed.ExternalObservation{ResourceExists: false}, nil
+}
+
+// deleteAgent deletes the identified agent from the supplied scope.
+func (c *external) deleteAgent(ctx context.Context, identifier, account string, org, project optional.String) (*http.Response, error) {
+	_, response, err := c.service.AgentApi.AgentServiceForServerDelete(c.service.Authorize(ctx), identifier, &nextgen.AgentsApiAgentServiceForServerDeleteOpts{
+		AccountIdentifier: optional.NewString(account),
+		OrgIdentifier:     org,
+		ProjectIdentifier: project,
+	})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return response, err
+}
+
+// checkLicense returns an error if the supplied account is not licensed for
+// the supplied module. Failing to determine whether it is licensed is not an
+// error: the API key may not be allowed to read licenses, and the operation
+// itself will fail if the module is not licensed.
+func (c *external) checkLicense(ctx context.Context, cr *v1alpha1.Agent, account, module string) error {
+	if c.licenses == nil {
+		return nil
+	}
+	licensed, err := c.licenses.Licensed(ctx, account, module, c.fetchLicenses)
+	if err != nil || licensed {
+		return nil //nolint:nilerr // See above.
+	}
+	err = errors.Errorf(errModuleNotLicensed, module, account)
+	cr.Status.SetConditions(v1alpha1.ModuleNotLicensed(err.Error()))
+	return err
+}
+
+func (c *external) fetchLicenses(ctx context.Context, account, module string) ([]nextgen.ModuleLicense, error) {
+	rsp, response, err := c.service.LicensesApi.GetModuleLicensesByAccountAndModuleType(ctx, account, module)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return rsp.Data, err
+}
+
+// agent returns the Harness representation of the supplied Agent.
+func (c *external) agent(ctx context.Context, cr *v1alpha1.Agent) (nextgen.V1Agent, error) {
 	accountIdentifier := ""
 	if cr.Spec.ForProvider.AccountIdentifier != nil {
 		accountIdentifier = *cr.Spec.ForProvider.AccountIdentifier
-		log.Printf("%s\n", accountIdentifier)
 	}
 
 	projectIndentifier := ""
 	if cr.Spec.ForProvider.ProjectIdentifier != nil {
 		projectIndentifier = *cr.Spec.ForProvider.ProjectIdentifier
-		log.Printf("%s\n", projectIndentifier)
 	}
 
 	orgIdentifier := ""
 	if cr.Spec.ForProvider.OrgIdentifier != nil {
 		orgIdentifier = *cr.Spec.ForProvider.OrgIdentifier
-		log.Printf("%s\n", orgIdentifier)
 	}
 
-	description := ""
-	if cr.Spec.ForProvider.Description != nil {
-		description = *cr.Spec.ForProvider.Description
-		log.Printf("%s\n", description)
+	description, err := c.description(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return nextgen.V1Agent{}, err
 	}
 
-	name := cr.GetObjectMeta().GetName()
-	ctx = context.WithValue(ctx, nextgen.ContextAPIKey, nextgen.APIKey{Key: os.Getenv("HARNESS_API_KEY")})
-	agent, response, err := c.service.AgentApi.AgentServiceForServerCreate(
-		ctx,
-		nextgen.V1Agent{
-			AccountIdentifier: accountIdentifier,
-			ProjectIdentifier: projectIndentifier,
-			OrgIdentifier:     orgIdentifier,
-			Identifier:        "",
-			Name:              name,
-			Metadata: &nextgen.V1AgentMetadata{
-				Namespace:        "harness",
-				HighAvailability: true,
-				// DeployedApplicationCount: 0,
-				// ExistingInstallation:     false,
-				MappedProjects: &nextgen.Servicev1AppProjectMapping{},
-			},
-			Description: description,
-			// Type_:       &nextgen.MANAGED_ARGO_PROVIDER_V1AgentType,
-			// CreatedAt:         &nextgen.V1Time{
-			// 	Seconds: "",
-			// 	Nanos:   0,
-			// },
-			// LastModifiedAt:    &nextgen.V1Time{},
-			// Tags: map[string]string{},
-			// Health:            &nextgen.V1AgentHealth{},
-			// Credentials:       &nextgen.V1AgentCredentials{},
-			// Version:           &nextgen.V1SemanticVersion{},
-			// UpgradeAvailable:  false,
-			// Scope:             &"",
-		})
-	defer func() {
-		if response != nil {
-			err := response.Body.Close()
-			if err != nil {
-				log.Fatal(err)
-			}
-		}
-	}()
+	tags := c.tags(cr.Spec.ForProvider)
+	if err := clients.ValidateTags(tags); err != nil {
+		return nextgen.V1Agent{}, errors.Wrap(err, errInvalidTags)
+	}
 
+	typ, err := desiredType(cr.Spec.ForProvider)
 	if err != nil {
-		return managed.ExternalCreation{}, err
+		return nextgen.V1Agent{}, err
 	}
-	// if response.StatusCode != http.StatusCreated {
-	// 	return managed.ExternalCreation{}, errors.Errorf("Agent could not be created status: %s, status code %d", response.Status, response.StatusCode)
-	// }
-
-	cr.Status.AtProvider.State = string(*agent.Health.HarnessGitopsAgent.Status)
 
-	return managed.ExternalCreation{
-		// Optionally return any details that may be required to connect to the
-		// external resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
+	return nextgen.V1Agent{
+		AccountIdentifier: accountIdentifier,
+		ProjectIdentifier: projectIndentifier,
+		OrgIdentifier:     orgIdentifier,
+		Identifier:        "",
+		Name:              agentName(cr),
+		Metadata: &nextgen.V1AgentMetadata{
+			Namespace:        agentNamespace(cr.Spec.ForProvider),
+			HighAvailability: cr.Spec.ForProvider.HighAvailability == nil || *cr.Spec.ForProvider.HighAvailability,
+			// DeployedApplicationCount: 0,
+			// ExistingInstallation:     false,
+			MappedProjects: mappedProjects(cr.Spec.ForProvider),
+		},
+		Description: description,
+		Tags:        tags,
+		Type_:       typ,
 	}, nil
 }
 
+// mappedProjects returns the Harness representation of the supplied agent's
+// mappings of Argo CD projects to Harness projects.
+func mappedProjects(p v1alpha1.AgentParameters) *nextgen.Servicev1AppProjectMapping {
+	m := &nextgen.Servicev1AppProjectMapping{}
+	if len(p.MappedProjects) == 0 {
+		return m
+	}
+	m.AppProjMap = make(map[string]nextgen.Servicev1Project, len(p.MappedProjects))
+	for _, pm := range p.MappedProjects {
+		m.AppProjMap[pm.ArgoProject] = nextgen.Servicev1Project{OrgIdentifier: pm.OrgIdentifier, ProjectIdentifier: pm.ProjectIdentifier}
+	}
+	return m
+}
+
+// desiredType returns the Harness type of the supplied agent, or nil if it
+// does not specify one and Harness's default should be used.
+func desiredType(p v1alpha1.AgentParameters) (*nextgen.V1AgentType, error) {
+	if p.Type == nil {
+		return nil, nil
+	}
+	var t nextgen.V1AgentType
+	switch *p.Type {
+	case v1alpha1.AgentTypeManagedArgo:
+		t = nextgen.MANAGED_ARGO_PROVIDER_V1AgentType
+	case v1alpha1.AgentTypeConnectedArgo:
+		t = nextgen.CONNECTED_ARGO_PROVIDER_V1AgentType
+	default:
+		return nil, errors.Errorf(errInvalidType, *p.Type)
+	}
+	return &t, nil
+}
+
+// agentName returns the name of the supplied Agent's agent: the name its spec
+// requests, or else the Agent's own name.
+func agentName(cr *v1alpha1.Agent) string {
+	if n := cr.Spec.ForProvider.Name; n != nil && *n != "" {
+		return *n
+	}
+	return cr.GetName()
+}
+
+// agentIdentifier returns the identifier of the supplied Agent's agent: the
+// identifier its spec requests, or else the one Harness assigned when it
+// created the agent, recorded as the Agent's external name.
+func agentIdentifier(cr *v1alpha1.Agent) string {
+	if id := cr.Spec.ForProvider.Identifier; id != nil && *id != "" {
+		return *id
+	}
+	return meta.GetExternalName(cr)
+}
+
+// validateScope returns an error if the supplied agent is scoped to a project
+// without the organization the project belongs to.
+func validateScope(p v1alpha1.AgentParameters) error {
+	if p.ProjectIdentifier == nil || *p.ProjectIdentifier == "" {
+		return nil
+	}
+	if p.OrgIdentifier == nil || *p.OrgIdentifier == "" {
+		return errors.Errorf(errProjectWithoutOrg, *p.ProjectIdentifier)
+	}
+	return nil
+}
+
+// agentNamespace returns the namespace the agent is installed in.
+func agentNamespace(p v1alpha1.AgentParameters) string {
+	if p.Namespace == nil || *p.Namespace == "" {
+		return defaultAgentNamespace
+	}
+	return *p.Namespace
+}
+
+// tags returns the desired tags of an agent, including the ProviderConfig's
+// default tags.
+func (c *external) tags(p v1alpha1.AgentParameters) map[string]string {
+	var tags map[string]string
+	if p.Tags != nil {
+		tags = *p.Tags
+	}
+	return clients.MergeTags(c.defaultTags, tags)
+}
+
+// recordTransition records the health state of the supplied agent, emitting
+// an event if it differs from the previously observed state. No event is
+// emitted for the first observed state, or when the state is unknown.
+func (c *external) recordTransition(cr *v1alpha1.Agent, st nextgen.Servicev1HealthStatus) {
+	prev := cr.Status.AtProvider.State
+	if st == "" || string(st) == prev {
+		return
+	}
+	cr.Status.AtProvider.State = string(st)
+	if prev == "" {
+		return
+	}
+	msg := fmt.Sprintf(msgHealthTransition, prev, st)
+	if st == nextgen.HEALTHY_Servicev1HealthStatus {
+		c.events().Event(cr, event.Normal(reasonHealthTransition, msg))
+		return
+	}
+	c.events().Event(cr, event.Warning(reasonHealthTransition, errors.New(msg)))
+}
+
+// observeAccount records the account the supplied Agent's agent was observed
+// in, reporting whether it differs from the account it was last observed in.
+func observeAccount(cr *v1alpha1.Agent) {
+	account, prev := *cr.Spec.ForProvider.AccountIdentifier, cr.Status.AtProvider.AccountIdentifier
+	switch {
+	case prev != "" && prev != account:
+		cr.Status.SetConditions(v1alpha1.ProviderConfigChanged(fmt.Sprintf(msgAccountChanged, prev, account)))
+	case cr.GetCondition(v1alpha1.TypeProviderConfigChanged).Status == corev1.ConditionTrue:
+		cr.Status.SetConditions(v1alpha1.ProviderConfigSettled())
+	}
+	cr.Status.AtProvider.AccountIdentifier = account
+}
+
+// lastError returns the errors Harness reports for the components of an
+// agent, or an empty string if it reports none. Kubernetes errors are
+// preferred over other messages, since they are usually the more actionable.
+func lastError(h *nextgen.V1AgentHealth) string {
+	if h == nil {
+		return ""
+	}
+	var errs []string
+	for _, c := range components(h) {
+		if c.health == nil {
+			continue
+		}
+		switch {
+		case c.health.K8sError != "":
+			errs = append(errs, c.name+": "+c.health.K8sError)
+		case c.health.Message != "":
+			errs = append(errs, c.name+": "+c.health.Message)
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+// A component is one of the components of an agent Harness reports the health
+// of.
+type component struct {
+	name   string
+	health *nextgen.V1AgentComponentHealth
+}
+
+func components(h *nextgen.V1AgentHealth) []component {
+	return []component{
+		{name: "gitops-agent", health: h.HarnessGitopsAgent},
+		{name: "application-controller", health: h.ArgoAppController},
+		{name: "repo-server", health: h.ArgoRepoServer},
+		{name: "redis", health: h.ArgoRedisServer},
+	}
+}
+
+// unhealthyComponents returns the components Harness reports as unhealthy,
+// including the agent's connection to Harness if it reports it disconnected.
+// Components Harness reports no health for are not considered unhealthy,
+// since not every agent runs all of them.
+func unhealthyComponents(h *nextgen.V1AgentHealth) []string {
+	if h == nil {
+		return nil
+	}
+	var unhealthy []string
+	for _, c := range components(h) {
+		if c.health != nil && c.health.Status != nil && *c.health.Status == nextgen.UNHEALTHY_Servicev1HealthStatus {
+			unhealthy = append(unhealthy, c.name)
+		}
+	}
+	if h.ConnectionStatus != nil && *h.ConnectionStatus == nextgen.DISCONNECTED_V1ConnectedStatus {
+		unhealthy = append(unhealthy, "connectivity")
+	}
+	return unhealthy
+}
+
+// observeHealth records the health Harness reports for each of the supplied
+// Agent's components. Agents report a heartbeat far more often than they are
+// polled, so the heartbeat is only refreshed once it is stale; otherwise
+// every status update would be a real write.
+func observeHealth(cr *v1alpha1.Agent, h *nextgen.V1AgentHealth) {
+	if h == nil {
+		cr.Status.AtProvider.Health = nil
+		return
+	}
+	prev := cr.Status.AtProvider.Health
+	out := &v1alpha1.AgentHealth{
+		GitOpsAgent:           componentHealth(h.HarnessGitopsAgent),
+		ApplicationController: componentHealth(h.ArgoAppController),
+		RepoServer:            componentHealth(h.ArgoRepoServer),
+		Redis:                 componentHealth(h.ArgoRedisServer),
+	}
+	if h.ConnectionStatus != nil {
+		out.ConnectionStatus = string(*h.ConnectionStatus)
+	}
+	if !h.LastHeartbeat.IsZero() {
+		t := metav1.NewTime(h.LastHeartbeat)
+		out.LastHeartbeat = &t
+		if prev != nil && prev.LastHeartbeat != nil && h.LastHeartbeat.Sub(prev.LastHeartbeat.Time) < lastSyncedResolution {
+			out.LastHeartbeat = prev.LastHeartbeat
+		}
+	}
+	cr.Status.AtProvider.Health = out
+}
+
+func componentHealth(h *nextgen.V1AgentComponentHealth) *v1alpha1.AgentComponentHealth {
+	if h == nil {
+		return nil
+	}
+	out := &v1alpha1.AgentComponentHealth{Message: h.Message, Version: h.Version}
+	if h.Status != nil {
+		out.Status = string(*h.Status)
+	}
+	if h.K8sError != "" {
+		out.Message = h.K8sError
+	}
+	return out
+}
+
+// missingFields returns the fields an agent returned by Harness must have but
+// does not. Health is not required: Harness reports none until the agent has
+// registered, which is treated as the agent not yet being available.
+func missingFields(a nextgen.V1Agent) []string {
+	var missing []string
+	if a.Identifier == "" {
+		missing = append(missing, "identifier")
+	}
+	return missing
+}
+
+func (c *external) log() logging.Logger {
+	if c.logger == nil {
+		return logging.NewNopLogger()
+	}
+	return c.logger
+}
+
+// gitopsAgentStatus returns the health Harness reports for the GitOps agent
+// component, or an empty status if it has not reported any yet.
+func gitopsAgentStatus(h *nextgen.V1AgentHealth) nextgen.Servicev1HealthStatus {
+	if h == nil || h.HarnessGitopsAgent == nil || h.HarnessGitopsAgent.Status == nil {
+		return ""
+	}
+	return *h.HarnessGitopsAgent.Status
+}
+
+// description resolves the description of an agent, preferring the inline
+// description over one sourced from a ConfigMap.
+func (c *external) description(ctx context.Context, p v1alpha1.AgentParameters) (string, error) {
+	if p.Description != nil {
+		return *p.Description, nil
+	}
+	ref := p.DescriptionFrom
+	if ref == nil {
+		return "", nil
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, cm); err != nil {
+		return "", errors.Wrap(err, errGetDescription)
+	}
+	d, ok := cm.Data[ref.Key]
+	if !ok {
+		return "", errors.Errorf(errMissingDescriptionKey, ref.Key, ref.Namespace, ref.Name)
+	}
+	return d, nil
+}
+
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
-	// No update required?
-	return managed.ExternalUpdate{}, nil
+	cr, ok := mg.(*v1alpha1.Agent)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAgent)
+	}
 
-	// cr, ok := mg.(*v1alpha1.Agent)
-	// if !ok {
-	// 	return managed.ExternalUpdate{}, errors.New(errNotAgent)
-	// }
+	if !mayUpdate(cr) {
+		return managed.ExternalUpdate{}, nil
+	}
 
-	// fmt.Printf("Updating: %+v", cr)
+	body, err := c.agent(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
 
-	// return managed.ExternalUpdate{
-	// 	// Optionally return any details that may be required to connect to the
-	// 	// external resource. These will be stored as the connection secret.
-	// 	ConnectionDetails: managed.ConnectionDetails{},
-	// }, nil
+	identifier := agentIdentifier(cr)
+	body.Identifier = identifier
+
+	// Observe previewed the update of an Agent in dry run mode.
+	if dryRun(cr) {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	ctx = c.service.Authorize(ctx)
+	_, response, err := c.service.AgentApi.AgentServiceForServerUpdate(ctx, body, identifier)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		c.recordAPIError(cr, reasonCannotUpdateAgent, identifier, err)
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateAgent)
+	}
+	c.recordChanged(cr, reasonUpdatedAgent, msgUpdatedAgent, identifier)
+	explain(cr, fmt.Sprintf(explainUpdated, cr.Status.AtProvider.Explanation))
+	return managed.ExternalUpdate{}, nil
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -331,7 +1051,72 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 		return errors.New(errNotAgent)
 	}
 
-	fmt.Printf("Deleting: %+v", cr)
+	// The agent is left alone, but no longer observed, so its health
+	// metrics would otherwise be reported forever.
+	if !mayDelete(cr) {
+		forgetHealth(agentIdentifier(cr), scopeOf(cr.Spec.ForProvider))
+		return nil
+	}
+
+	// Deleting dependents is itself a change, so nothing is deleted in a dry
+	// run.
+	if dryRun(cr) {
+		c.preview(cr, msgDryRunDelete, agentIdentifier(cr), scopeOf(cr.Spec.ForProvider))
+		return nil
+	}
+
+	if c.dependentGC {
+		n, err := dependents.Delete(ctx, c.kube, cr)
+		if err != nil {
+			return errors.Wrap(err, errDeleteDependents)
+		}
+		if n > 0 {
+			return errors.Errorf(errDependentsPending, n)
+		}
+	}
+
+	if cr.Spec.ForProvider.AccountIdentifier == nil {
+		return errors.New(errNoAccount)
+	}
+
+	identifier := agentIdentifier(cr)
+
+	// Observe reports an agent Harness is still deleting as existing, so the
+	// delete would otherwise be requested again on every reconcile.
+	if deletionPending(cr, time.Now()) {
+		return nil
+	}
+
+	org, project := clients.ScopeOpts(cr.Spec.ForProvider.OrgIdentifier, cr.Spec.ForProvider.ProjectIdentifier)
+	response, err := c.deleteAgent(ctx, identifier, *cr.Spec.ForProvider.AccountIdentifier, org, project)
+	// An agent that is already gone has been deleted.
+	if clients.IsNotFound(response) {
+		err = nil
+	}
+	// Harness refuses to delete an agent other entities still reference.
+	// Returning an error requeues the delete until they are removed.
+	if response != nil && response.StatusCode == http.StatusConflict {
+		msg := clients.ErrorMessage(err)
+		cr.Status.SetConditions(v1alpha1.DeletionBlocked(msg))
+		c.recordAPIError(cr, reasonCannotDeleteAgent, identifier, err)
+		return errors.Errorf(errDeletionBlocked, msg)
+	}
+	if err != nil {
+		c.recordAPIError(cr, reasonCannotDeleteAgent, identifier, err)
+		return errors.Wrap(err, errDeleteAgent)
+	}
+
+	now := metav1.Now()
+	cr.Status.AtProvider.DeletionRequestedAt = &now
+	forgetHealth(identifier, scopeOf(cr.Spec.ForProvider))
+	c.recordChanged(cr, reasonDeletedAgent, msgDeletedAgent, identifier)
 
 	return nil
 }
+
+// deletionPending returns true if Harness was asked to delete the supplied
+// Agent's agent within the last deleteRetryInterval.
+func deletionPending(cr *v1alpha1.Agent, now time.Time) bool {
+	t := cr.Status.AtProvider.DeletionRequestedAt
+	return t != nil && now.Sub(t.Time) < deleteRetryInterval
+}
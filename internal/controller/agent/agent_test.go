@@ -18,13 +18,38 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+	"github.com/crossplane/provider-harness/internal/version"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -36,8 +61,31 @@ import (
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
 func TestObserve(t *testing.T) {
+	account := "account"
+	tagged := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{},"tags":{"managed-by":"crossplane","team":"platform"}}`))
+	}
+	agent := func(tags map[string]string) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+			AccountIdentifier: &account,
+			Tags:              &tags,
+		}}}
+		meta.SetExternalName(cr, "agent")
+		return cr
+	}
+
+	deleting := func(cr *v1alpha1.Agent) *v1alpha1.Agent {
+		now := metav1.Now()
+		cr.SetDeletionTimestamp(&now)
+		return cr
+	}
+
 	type fields struct {
-		service *HarnessService
+		handler        http.HandlerFunc
+		defaultTags    map[string]string
+		scopeDefaulted bool
+		licenses       *clients.LicenseCache
 	}
 
 	type args struct {
@@ -56,12 +104,125 @@ func TestObserve(t *testing.T) {
 		args   args
 		want   want
 	}{
-		// TODO: Add test cases.
+		"NoAccount": {
+			reason: "An Agent without an account should fail to reconcile rather than stop the provider.",
+			fields: fields{handler: tagged},
+			args:   args{ctx: context.Background(), mg: &v1alpha1.Agent{}},
+			want:   want{err: errors.New(errNoAccount)},
+		},
+		"ProjectWithoutOrg": {
+			reason: "A project scoped Agent without an organization should be rejected before calling Harness.",
+			fields: fields{handler: tagged},
+			args: args{ctx: context.Background(), mg: func() resource.Managed {
+				cr := agent(nil)
+				project := "project"
+				cr.Spec.ForProvider.ProjectIdentifier = &project
+				return cr
+			}()},
+			want: want{err: errors.Errorf(errProjectWithoutOrg, "project")},
+		},
+		"UpToDateWithDefaultTags": {
+			reason: "An agent carrying the merged default and resource tags should be up to date.",
+			fields: fields{handler: tagged, defaultTags: map[string]string{"managed-by": "crossplane"}},
+			args:   args{ctx: context.Background(), mg: agent(map[string]string{"team": "platform"})},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"ScopeDefaulted": {
+			reason: "An agent whose scope was defaulted from the ProviderConfig should be late initialized, so the default scope is persisted.",
+			fields: fields{handler: tagged, defaultTags: map[string]string{"managed-by": "crossplane"}, scopeDefaulted: true},
+			args:   args{ctx: context.Background(), mg: agent(map[string]string{"team": "platform"})},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: true}},
+		},
+		"DefaultTagRemoved": {
+			reason: "An agent missing a default tag should not be up to date.",
+			fields: fields{handler: tagged, defaultTags: map[string]string{"managed-by": "crossplane", "env": "dev"}},
+			args:   args{ctx: context.Background(), mg: agent(map[string]string{"team": "platform"})},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ResourceTagTakesPrecedence": {
+			reason: "A resource tag should override the default tag with the same key.",
+			fields: fields{handler: tagged, defaultTags: map[string]string{"managed-by": "someone-else"}},
+			args:   args{ctx: context.Background(), mg: agent(map[string]string{"managed-by": "crossplane", "team": "platform"})},
+			want:   want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"MergedTagsInvalid": {
+			reason: "Default tags that push an agent's tags past Harness's limits should be rejected before calling Harness.",
+			fields: fields{handler: tagged, defaultTags: map[string]string{"managed-by": strings.Repeat("x", clients.MaxTagValueLength+1)}},
+			args:   args{ctx: context.Background(), mg: agent(map[string]string{"team": "platform"})},
+			want: want{err: errors.Wrap(
+				errors.Errorf("value of tag %q is %d characters long, exceeding the limit of %d characters", "managed-by", clients.MaxTagValueLength+1, clients.MaxTagValueLength),
+				errInvalidTags)},
+		},
+		"ImmutableFieldChanged": {
+			reason: "Changing the account of an existing agent should be reported as an immutable change.",
+			fields: fields{handler: tagged},
+			args: args{ctx: context.Background(), mg: func() resource.Managed {
+				cr := agent(map[string]string{"managed-by": "crossplane", "team": "platform"})
+				other := "other"
+				cr.Spec.ForProvider.AccountIdentifier = &other
+				return cr
+			}()},
+			want: want{err: errors.Errorf(errImmutableFields, "accountIdentifier")},
+		},
+		"GetError": {
+			reason: "Errors other than not found should be returned rather than reported as absent, so the agent is not recreated.",
+			fields: fields{handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}},
+			args: args{ctx: context.Background(), mg: agent(nil)},
+			want: want{err: errors.Wrap(errors.New("500 Internal Server Error"), errGetAgent)},
+		},
+		"TruncatedResponse": {
+			reason: "A truncated agent should be a transient error, not a missing agent.",
+			fields: fields{handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdent`))
+			}},
+			args: args{ctx: context.Background(), mg: agent(nil)},
+			want: want{err: errors.Errorf(errIncompleteAgent, "identifier")},
+		},
+		"IncompleteResponse": {
+			reason: "An agent missing required fields should be a transient error.",
+			fields: fields{handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"accountIdentifier":"account"}`))
+			}},
+			args: args{ctx: context.Background(), mg: agent(nil)},
+			want: want{err: errors.Errorf(errIncompleteAgent, "identifier")},
+		},
+		"DeletingAgentGone": {
+			reason: "A deleting Agent whose agent is gone should be observed as absent, even if its account is no longer licensed.",
+			fields: fields{
+				handler: func(w http.ResponseWriter, r *http.Request) {
+					if strings.HasPrefix(r.URL.Path, "/ng/api/licenses/") {
+						w.Header().Set("Content-Type", "application/json")
+						_, _ = w.Write([]byte(`{"data":[{"status":"EXPIRED"}]}`))
+						return
+					}
+					w.WriteHeader(http.StatusNotFound)
+				},
+				licenses: clients.NewLicenseCache(time.Minute),
+			},
+			args: args{ctx: context.Background(), mg: deleting(agent(nil))},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"DeletingWithImmutableChange": {
+			reason: "Immutable field drift should not stop a deleting Agent from being deleted.",
+			fields: fields{handler: tagged},
+			args: args{ctx: context.Background(), mg: func() resource.Managed {
+				cr := deleting(agent(nil))
+				other := "other"
+				cr.Spec.ForProvider.AccountIdentifier = &other
+				return cr
+			}()},
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
+			e := external{service: clientstest.NewService(t, tc.fields.handler), defaultTags: tc.fields.defaultTags, scopeDefaulted: tc.fields.scopeDefaulted, licenses: tc.fields.licenses}
+			t.Cleanup(func() { forgetHealth("agent", scopeOf(tc.args.mg.(*v1alpha1.Agent).Spec.ForProvider)) })
 			got, err := e.Observe(tc.args.ctx, tc.args.mg)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
@@ -72,3 +233,1431 @@ func TestObserve(t *testing.T) {
 		})
 	}
 }
+
+func TestHealthMetrics(t *testing.T) {
+	healthyStatus := nextgen.HEALTHY_Servicev1HealthStatus
+	now := time.Now()
+
+	recordHealth("agent", "acct/org", &nextgen.V1AgentHealth{
+		LastHeartbeat:      now.Add(-30 * time.Second),
+		HarnessGitopsAgent: &nextgen.V1AgentComponentHealth{Status: &healthyStatus},
+	}, now)
+
+	if got := testutil.ToFloat64(healthy.WithLabelValues("agent", "acct/org")); got != 1 {
+		t.Errorf("recordHealth(...): healthy: want 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(heartbeatAge.WithLabelValues("agent", "acct/org")); got != 30 {
+		t.Errorf("recordHealth(...): heartbeat age: want 30, got %v", got)
+	}
+
+	forgetHealth("agent", "acct/org")
+	if got := testutil.CollectAndCount(healthy) + testutil.CollectAndCount(heartbeatAge); got != 0 {
+		t.Errorf("forgetHealth(...): want no series left, got %d", got)
+	}
+}
+
+func TestDescription(t *testing.T) {
+	errBoom := errors.New("boom")
+	str := func(s string) *string { return &s }
+	ref := &v1alpha1.ConfigMapKeySelector{Namespace: "ns", Name: "descriptions", Key: "agent"}
+
+	type want struct {
+		description string
+		err         error
+	}
+
+	cases := map[string]struct {
+		reason string
+		kube   client.Client
+		params v1alpha1.AgentParameters
+		want   want
+	}{
+		"Inline": {
+			reason: "An inline description should take precedence over a ConfigMap.",
+			params: v1alpha1.AgentParameters{Description: str("inline"), DescriptionFrom: ref},
+			want:   want{description: "inline"},
+		},
+		"Unset": {
+			reason: "No description should resolve to an empty string.",
+			want:   want{description: ""},
+		},
+		"FromConfigMap": {
+			reason: "The description should be read from the referenced ConfigMap key.",
+			kube: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				obj.(*corev1.ConfigMap).Data = map[string]string{"agent": "from configmap"}
+				return nil
+			}},
+			params: v1alpha1.AgentParameters{DescriptionFrom: ref},
+			want:   want{description: "from configmap"},
+		},
+		"MissingKey": {
+			reason: "A missing ConfigMap key should return an error.",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(nil)},
+			params: v1alpha1.AgentParameters{DescriptionFrom: ref},
+			want:   want{err: errors.Errorf(errMissingDescriptionKey, "agent", "ns", "descriptions")},
+		},
+		"GetError": {
+			reason: "Errors getting the ConfigMap should be returned.",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			params: v1alpha1.AgentParameters{DescriptionFrom: ref},
+			want:   want{err: errors.Wrap(errBoom, errGetDescription)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{kube: tc.kube}
+			got, err := e.description(context.Background(), tc.params)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.description(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.description, got); diff != "" {
+				t.Errorf("\n%s\ne.description(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// newTestService returns a Service backed by a test server that
+// serves the supplied handler.
+func TestObserveConditions(t *testing.T) {
+	account := "account"
+	agent := func(c ...xpv1.Condition) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		meta.SetExternalName(cr, "agent")
+		cr.SetConditions(c...)
+		return cr
+	}
+	status := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(code)
+		}
+	}
+
+	type want struct {
+		c         []xpv1.Condition
+		lastError string
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"Unauthenticated": {
+			reason:  "Rejected credentials should be reported with the Unauthenticated reason.",
+			handler: status(http.StatusUnauthorized),
+			want: want{
+				c:   []xpv1.Condition{v1alpha1.Unauthenticated("401 Unauthorized")},
+				err: errors.Errorf(errUnauthenticated, "401 Unauthorized"),
+			},
+		},
+		"RateLimited": {
+			reason:  "Rate limited requests should be reported with the RateLimited reason.",
+			handler: status(http.StatusTooManyRequests),
+			want: want{
+				c:   []xpv1.Condition{v1alpha1.RateLimited("429 Too Many Requests")},
+				err: errors.Errorf(errRateLimited, "429 Too Many Requests"),
+			},
+		},
+		"Unhealthy": {
+			reason: "An unhealthy agent should be reported with the AgentUnhealthy reason.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{"harnessGitopsAgent":{"status":"UNHEALTHY"}}}`))
+			},
+			want: want{
+				c: []xpv1.Condition{v1alpha1.Unhealthy("Harness reports the GitOps agent as UNHEALTHY"), v1alpha1.Degraded("Harness reports gitops-agent as unhealthy"), v1alpha1.InstallCurrent()},
+			},
+		},
+		"HealthNotReported": {
+			reason: "An agent that has not reported its health yet should not be reported as available.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account"}`))
+			},
+			want: want{
+				c: []xpv1.Condition{v1alpha1.InstallCurrent()},
+			},
+		},
+		"UnhealthyWithError": {
+			reason: "The error Harness reports for an unhealthy agent should be recorded and included in its condition.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{"harnessGitopsAgent":{"status":"UNHEALTHY","k8sError":"image pull failed"}}}`))
+			},
+			want: want{
+				c:         []xpv1.Condition{v1alpha1.Unhealthy("Harness reports the GitOps agent as UNHEALTHY: gitops-agent: image pull failed"), v1alpha1.Degraded("Harness reports gitops-agent as unhealthy"), v1alpha1.InstallCurrent()},
+				lastError: "gitops-agent: image pull failed",
+			},
+		},
+		"Healthy": {
+			reason: "An agent whose components are all healthy should be reported as available and not degraded.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{"harnessGitopsAgent":{"status":"HEALTHY"},"argoRepoServer":{"status":"HEALTHY"},"connectionStatus":"CONNECTED"}}`))
+			},
+			want: want{
+				c: []xpv1.Condition{xpv1.Available(), v1alpha1.NotDegraded(), v1alpha1.InstallCurrent()},
+			},
+		},
+		"Degraded": {
+			reason: "An agent reported healthy with unhealthy components should not be available, and should name the failing components.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{"harnessGitopsAgent":{"status":"HEALTHY"},"argoRepoServer":{"status":"UNHEALTHY","message":"ImagePullBackOff"},"connectionStatus":"DISCONNECTED"}}`))
+			},
+			want: want{
+				c: []xpv1.Condition{
+					v1alpha1.PartiallyHealthy("Harness reports the GitOps agent as healthy, but these components as unhealthy: repo-server, connectivity"),
+					v1alpha1.Degraded("Harness reports repo-server, connectivity as unhealthy"),
+					v1alpha1.InstallCurrent(),
+				},
+				lastError: "repo-server: ImagePullBackOff",
+			},
+		},
+		"UpgradeAvailable": {
+			reason: "An agent with a newer version available should be reported as outdated.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","upgradeAvailable":true,"health":{"harnessGitopsAgent":{"status":"UNHEALTHY","version":"0.55.0"}}}`))
+			},
+			want: want{
+				c: []xpv1.Condition{
+					v1alpha1.Unhealthy("Harness reports the GitOps agent as UNHEALTHY"),
+					v1alpha1.Degraded("Harness reports gitops-agent as unhealthy"),
+					v1alpha1.InstallOutdated("agent version 0.55.0 is installed, but Harness offers a newer version; reinstall the agent to upgrade it"),
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := agent()
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			_, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			want := agent(tc.want.c...)
+			want.Status.AtProvider.ManagedByVersion = version.Version
+			want.Status.AtProvider.LastError = tc.want.lastError
+			if tc.want.err == nil {
+				want.Status.AtProvider.Identifier = "agent"
+			}
+			if diff := cmp.Diff(want, cr, test.EquateConditions(), cmpopts.IgnoreFields(v1alpha1.AgentObservation{}, "State", "LastSyncedTime", "ClientVersion", "UpgradeAvailable", "RepoCount", "ClusterCount", "CountsObservedAt", "AccountIdentifier", "Health")); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCheckLicense(t *testing.T) {
+	licenses := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(body))
+		}
+	}
+
+	type want struct {
+		cr  *v1alpha1.Agent
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"Licensed": {
+			reason:  "No error should be returned for a licensed module.",
+			handler: licenses(`{"data":[{"moduleType":"CD","status":"ACTIVE"}]}`),
+			want:    want{cr: &v1alpha1.Agent{}},
+		},
+		"NotLicensed": {
+			reason:  "An unlicensed module should be reported with the ModuleNotLicensed reason.",
+			handler: licenses(`{"data":[{"moduleType":"CD","status":"EXPIRED"}]}`),
+			want: want{
+				cr: func() *v1alpha1.Agent {
+					cr := &v1alpha1.Agent{}
+					cr.SetConditions(v1alpha1.ModuleNotLicensed("Harness module CD is not licensed for account account"))
+					return cr
+				}(),
+				err: errors.Errorf(errModuleNotLicensed, clients.ModuleCD, "account"),
+			},
+		},
+		"Unknown": {
+			reason: "Failing to read licenses should not block the operation.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			},
+			want: want{cr: &v1alpha1.Agent{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Agent{}
+			e := external{service: clientstest.NewService(t, tc.handler), licenses: clients.NewLicenseCache(time.Hour)}
+			err := e.checkLicense(context.Background(), cr, "account", clients.ModuleCD)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.checkLicense(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, cr, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.checkLicense(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// eventRecorder records the events it is asked to record.
+type eventRecorder struct {
+	events []event.Event
+}
+
+func (r *eventRecorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *eventRecorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestRecordTransition(t *testing.T) {
+	type args struct {
+		prev string
+		st   nextgen.Servicev1HealthStatus
+	}
+	type want struct {
+		state  string
+		events []event.Event
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"FirstObservation": {
+			reason: "The first observed state is not a transition.",
+			args:   args{st: nextgen.HEALTHY_Servicev1HealthStatus},
+			want:   want{state: "HEALTHY"},
+		},
+		"Unchanged": {
+			reason: "No event should be emitted when the state is unchanged.",
+			args:   args{prev: "HEALTHY", st: nextgen.HEALTHY_Servicev1HealthStatus},
+			want:   want{state: "HEALTHY"},
+		},
+		"Unknown": {
+			reason: "An unknown state should not replace the last observed state.",
+			args:   args{prev: "HEALTHY"},
+			want:   want{state: "HEALTHY"},
+		},
+		"BecameUnhealthy": {
+			reason: "A warning should be emitted when the agent becomes unhealthy.",
+			args:   args{prev: "HEALTHY", st: nextgen.UNHEALTHY_Servicev1HealthStatus},
+			want: want{
+				state:  "UNHEALTHY",
+				events: []event.Event{event.Warning(reasonHealthTransition, errors.New("GitOps agent health changed from HEALTHY to UNHEALTHY"))},
+			},
+		},
+		"BecameHealthy": {
+			reason: "A normal event should be emitted when the agent becomes healthy.",
+			args:   args{prev: "UNHEALTHY", st: nextgen.HEALTHY_Servicev1HealthStatus},
+			want: want{
+				state:  "HEALTHY",
+				events: []event.Event{event.Normal(reasonHealthTransition, "GitOps agent health changed from UNHEALTHY to HEALTHY")},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &eventRecorder{}
+			cr := &v1alpha1.Agent{}
+			cr.Status.AtProvider.State = tc.args.prev
+			e := external{recorder: r}
+			e.recordTransition(cr, tc.args.st)
+			if diff := cmp.Diff(tc.want.state, cr.Status.AtProvider.State); diff != "" {
+				t.Errorf("\n%s\ne.recordTransition(...): -want state, +got state:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, r.events); diff != "" {
+				t.Errorf("\n%s\ne.recordTransition(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	// created returns a handler that creates the supplied agent and serves
+	// the supplied install manifests.
+	created := func(agent, manifests string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if strings.HasSuffix(r.URL.Path, "/deploy.yaml") {
+				if manifests == "" {
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				w.Header().Set("Content-Type", "application/x-yml")
+				_, _ = w.Write([]byte(manifests))
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(agent))
+		}
+	}
+
+	type want struct {
+		cr  *v1alpha1.Agent
+		o   managed.ExternalCreation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		cr      *v1alpha1.Agent
+		want    want
+	}{
+		"CreatedWithoutHealth": {
+			reason:  "An agent created without health should be reported as creating, not panic.",
+			handler: created(`{"identifier":"agent","name":"agent"}`, "kind: Deployment"),
+			cr:      &v1alpha1.Agent{},
+			want: want{
+				cr: func() *v1alpha1.Agent {
+					cr := &v1alpha1.Agent{}
+					meta.SetExternalName(cr, "agent")
+					cr.Status.AtProvider.Identifier = "agent"
+					cr.SetConditions(xpv1.Creating())
+					return cr
+				}(),
+				o: managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+					ConnectionDetailIdentifier:  []byte("agent"),
+					ConnectionDetailInstallYAML: []byte("kind: Deployment"),
+				}},
+			},
+		},
+		"Recreated": {
+			reason:  "Creating an agent that was deleted to change its immutable fields should complete the recreate.",
+			handler: created(`{"identifier":"agent","name":"agent"}`, "kind: Deployment"),
+			cr: func() *v1alpha1.Agent {
+				cr := &v1alpha1.Agent{}
+				cr.SetConditions(v1alpha1.Recreating(fmt.Sprintf(msgRecreating, "orgIdentifier")))
+				return cr
+			}(),
+			want: want{
+				cr: func() *v1alpha1.Agent {
+					cr := &v1alpha1.Agent{}
+					meta.SetExternalName(cr, "agent")
+					cr.Status.AtProvider.Identifier = "agent"
+					cr.SetConditions(xpv1.Creating(), v1alpha1.Recreated())
+					return cr
+				}(),
+				o: managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+					ConnectionDetailIdentifier:  []byte("agent"),
+					ConnectionDetailInstallYAML: []byte("kind: Deployment"),
+				}},
+			},
+		},
+		"CredentialsWithoutManifests": {
+			reason:  "The credentials of a created agent should be published even if its install manifests cannot be fetched.",
+			handler: created(`{"identifier":"agent","name":"agent","credentials":{"privateKey":"private","publicKey":"public"}}`, ""),
+			cr:      &v1alpha1.Agent{},
+			want: want{
+				cr: func() *v1alpha1.Agent {
+					cr := &v1alpha1.Agent{}
+					meta.SetExternalName(cr, "agent")
+					cr.Status.AtProvider.Identifier = "agent"
+					cr.SetConditions(xpv1.Creating())
+					return cr
+				}(),
+				o: managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{
+					ConnectionDetailIdentifier: []byte("agent"),
+					ConnectionDetailPrivateKey: []byte("private"),
+					ConnectionDetailPublicKey:  []byte("public"),
+				}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Create(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, tc.cr, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAgentNamespace(t *testing.T) {
+	custom := "gitops"
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   string
+	}{
+		"Default": {
+			reason: "An agent should be installed in the harness namespace unless one is specified.",
+			want:   defaultAgentNamespace,
+		},
+		"Specified": {
+			reason: "An agent should be installed in the namespace its Agent specifies.",
+			params: v1alpha1.AgentParameters{Namespace: &custom},
+			want:   custom,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{}
+			got, err := e.agent(context.Background(), &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: tc.params}})
+			if err != nil {
+				t.Fatalf("\n%s\ne.agent(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got.Metadata.Namespace); diff != "" {
+				t.Errorf("\n%s\ne.agent(...): -want namespace, +got namespace:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAgentHighAvailability(t *testing.T) {
+	disabled := false
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   bool
+	}{
+		"Default": {
+			reason: "An agent should be highly available unless its Agent disables it.",
+			want:   true,
+		},
+		"Disabled": {
+			reason: "An agent should not be highly available if its Agent disables it.",
+			params: v1alpha1.AgentParameters{HighAvailability: &disabled},
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{}
+			got, err := e.agent(context.Background(), &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: tc.params}})
+			if err != nil {
+				t.Fatalf("\n%s\ne.agent(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got.Metadata.HighAvailability); diff != "" {
+				t.Errorf("\n%s\ne.agent(...): -want high availability, +got high availability:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAgentMappedProjects(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   *nextgen.Servicev1AppProjectMapping
+	}{
+		"None": {
+			reason: "An agent without project mappings should be created with an empty mapping.",
+			want:   &nextgen.Servicev1AppProjectMapping{},
+		},
+		"Mapped": {
+			reason: "Each Argo CD project should be mapped to the Harness project its Agent specifies.",
+			params: v1alpha1.AgentParameters{MappedProjects: []v1alpha1.ProjectMapping{
+				{ArgoProject: "default", OrgIdentifier: "default", ProjectIdentifier: "platform"},
+				{ArgoProject: "payments", OrgIdentifier: "finance", ProjectIdentifier: "payments"},
+			}},
+			want: &nextgen.Servicev1AppProjectMapping{AppProjMap: map[string]nextgen.Servicev1Project{
+				"default":  {OrgIdentifier: "default", ProjectIdentifier: "platform"},
+				"payments": {OrgIdentifier: "finance", ProjectIdentifier: "payments"},
+			}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{}
+			got, err := e.agent(context.Background(), &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: tc.params}})
+			if err != nil {
+				t.Fatalf("\n%s\ne.agent(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got.Metadata.MappedProjects); diff != "" {
+				t.Errorf("\n%s\ne.agent(...): -want mapped projects, +got mapped projects:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	account := "account"
+	agent := func() *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		cr.SetName("renamed")
+		meta.SetExternalName(cr, "agent")
+		return cr
+	}
+
+	type want struct {
+		path string
+		name string
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		status int
+		want   want
+	}{
+		"Updated": {
+			reason: "The desired agent should be sent to Harness under its identifier.",
+			status: http.StatusOK,
+			want:   want{path: "/gitops/api/v1/agents/agent", name: "renamed"},
+		},
+		"Error": {
+			reason: "Errors updating the agent should be returned.",
+			status: http.StatusInternalServerError,
+			want: want{
+				path: "/gitops/api/v1/agents/agent",
+				name: "renamed",
+				err:  errors.Wrap(errors.New("500 Internal Server Error"), errUpdateAgent),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var path string
+			var sent nextgen.V1Agent
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				_ = json.NewDecoder(r.Body).Decode(&sent)
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(`{"identifier":"agent"}`))
+			}
+			e := external{service: clientstest.NewService(t, handler)}
+			_, err := e.Update(context.Background(), agent())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.path, path); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want path, +got path:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.name, sent.Name); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want name, +got name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	account := "account"
+	agent := func(c ...xpv1.Condition) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		meta.SetExternalName(cr, "agent")
+		cr.SetConditions(c...)
+		return cr
+	}
+
+	recently := metav1.NewTime(time.Now().Add(-time.Minute))
+	long := metav1.NewTime(time.Now().Add(-2 * deleteRetryInterval))
+
+	type want struct {
+		cr        *v1alpha1.Agent
+		requested bool
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		handler   http.HandlerFunc
+		requested *metav1.Time
+		want      want
+	}{
+		"Deleted": {
+			reason: "A successfully deleted agent should not be reported as blocked, and the time of the delete should be recorded.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent"}`))
+			},
+			want: want{cr: agent(), requested: true},
+		},
+		"AlreadyDeleted": {
+			reason: "An agent Harness no longer knows should be treated as deleted.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			want: want{cr: agent(), requested: true},
+		},
+		"Pending": {
+			reason: "An agent Harness was recently asked to delete should not be deleted again.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				t.Errorf("unexpected request to delete a pending agent")
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			requested: &recently,
+			want:      want{cr: agent(), requested: true},
+		},
+		"PendingTooLong": {
+			reason: "An agent Harness has not deleted within the retry interval should be deleted again.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"agent"}`))
+			},
+			requested: &long,
+			want:      want{cr: agent(), requested: true},
+		},
+		"Conflict": {
+			reason: "An agent Harness refuses to delete because it is referenced should be reported as blocked.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusConflict)
+				_, _ = w.Write([]byte(`{"code":9,"message":"agent is referenced by application checkout"}`))
+			},
+			want: want{
+				cr:  agent(v1alpha1.DeletionBlocked("agent is referenced by application checkout")),
+				err: errors.Errorf(errDeletionBlocked, "agent is referenced by application checkout"),
+			},
+		},
+		"Error": {
+			reason: "Other errors deleting the agent should be returned.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			},
+			want: want{
+				cr:  agent(),
+				err: errors.Wrap(errors.New("400 Bad Request"), errDeleteAgent),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := agent()
+			cr.Status.AtProvider.DeletionRequestedAt = tc.requested
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cr, cr, test.EquateConditions(), cmpopts.IgnoreFields(v1alpha1.AgentObservation{}, "DeletionRequestedAt")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.requested, cr.Status.AtProvider.DeletionRequestedAt != nil); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want deletion requested, +got deletion requested:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveDeletionPending(t *testing.T) {
+	account := "account"
+	requested := metav1.NewTime(time.Now().Add(-time.Minute))
+	found := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account"}`))
+	}
+	gone := func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}
+
+	type want struct {
+		o managed.ExternalObservation
+		c xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason    string
+		handler   http.HandlerFunc
+		requested *metav1.Time
+		want      want
+	}{
+		"NotRequested": {
+			reason:  "A deleting Agent whose delete was not yet requested should not report it as pending.",
+			handler: found,
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c: xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown},
+			},
+		},
+		"Pending": {
+			reason:    "An agent Harness still returns after its delete was requested should be reported as being deleted.",
+			handler:   found,
+			requested: &requested,
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c: xpv1.Deleting().WithMessage(fmt.Sprintf(msgDeletionPending, "agent", account)),
+			},
+		},
+		"Confirmed": {
+			reason:    "An agent Harness no longer returns after its delete was requested should be reported as absent.",
+			handler:   gone,
+			requested: &requested,
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+				c: xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(func() { forgetHealth("agent", account) })
+			cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+			meta.SetExternalName(cr, "agent")
+			now := metav1.Now()
+			cr.SetDeletionTimestamp(&now)
+			cr.Status.AtProvider.DeletionRequestedAt = tc.requested
+
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestObserveConcurrently observes many Agents concurrently with a shared
+// Service, as multiple reconcile workers do. Run it with -race to
+// detect unsynchronized access to shared state.
+func TestObserveConcurrently(t *testing.T) {
+	const workers = 16
+	account := "account"
+
+	h := func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("x-api-key"); got != "key" {
+			t.Errorf("request to %s: want API key %q, got %q", r.URL.Path, "key", got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/ng/api/licenses/"):
+			_, _ = w.Write([]byte(`{"data":[{"status":"ACTIVE"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/repositories"), strings.HasSuffix(r.URL.Path, "/clusters"):
+			_, _ = w.Write([]byte(`{"items":[]}`))
+		default:
+			id := path.Base(r.URL.Path)
+			_, _ = fmt.Fprintf(w, `{"identifier":%q,"accountIdentifier":"account","health":{}}`, id)
+		}
+	}
+	svc := clientstest.NewService(t, h)
+	svc.APIKey = "key"
+	e := external{service: svc, licenses: clients.NewLicenseCache(time.Minute)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		id := fmt.Sprintf("agent-%d", i)
+		t.Cleanup(func() { forgetHealth(id, account) })
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				AccountIdentifier: &account,
+				Identifier:        &id,
+			}}}
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Errorf("e.Observe(%s): %v", id, err)
+				return
+			}
+			if !o.ResourceExists {
+				t.Errorf("e.Observe(%s): want resource to exist", id)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestObserveAccountChange(t *testing.T) {
+	account := "account"
+	agent := func(prev string, c ...xpv1.Condition) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		meta.SetExternalName(cr, "agent")
+		cr.Status.AtProvider.AccountIdentifier = prev
+		cr.SetConditions(c...)
+		return cr
+	}
+	found := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","health":{}}`))
+	}
+
+	type want struct {
+		o       managed.ExternalObservation
+		c       xpv1.Condition
+		account string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		cr      *v1alpha1.Agent
+		want    want
+	}{
+		"MovedToAccountWithoutAgent": {
+			reason:  "An Agent moved to an account its agent does not exist in should error rather than create a new agent.",
+			handler: func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) },
+			cr:      agent("old"),
+			want: want{
+				c:       v1alpha1.ProviderConfigChanged(errors.Errorf(errAgentNotInAccount, "agent", "account", "old").Error()),
+				account: "old",
+				err:     errors.Errorf(errAgentNotInAccount, "agent", "account", "old"),
+			},
+		},
+		"MovedToAccountWithAgent": {
+			reason:  "An Agent moved to an account its agent exists in should adopt it there.",
+			handler: found,
+			cr:      agent("old"),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c:       v1alpha1.ProviderConfigChanged(fmt.Sprintf(msgAccountChanged, "old", "account")),
+				account: "account",
+			},
+		},
+		"Settled": {
+			reason:  "An adopted Agent should no longer be reported as changed once re-observed.",
+			handler: found,
+			cr:      agent("account", v1alpha1.ProviderConfigChanged(fmt.Sprintf(msgAccountChanged, "old", "account"))),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c:       v1alpha1.ProviderConfigSettled(),
+				account: "account",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			t.Cleanup(func() { forgetHealth("agent", account) })
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, tc.cr.GetCondition(v1alpha1.TypeProviderConfigChanged), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.account, tc.cr.Status.AtProvider.AccountIdentifier); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want account, +got account:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLastError(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		h      *nextgen.V1AgentHealth
+		want   string
+	}{
+		"NoHealth": {
+			reason: "An agent that reports no health should have no error.",
+		},
+		"NoMessages": {
+			reason: "An agent whose components report no messages should have no error.",
+			h:      &nextgen.V1AgentHealth{HarnessGitopsAgent: &nextgen.V1AgentComponentHealth{}},
+		},
+		"PreferKubernetesError": {
+			reason: "Kubernetes errors should be preferred over other messages, and every component's error reported.",
+			h: &nextgen.V1AgentHealth{
+				HarnessGitopsAgent: &nextgen.V1AgentComponentHealth{K8sError: "unauthorized to cluster", Message: "disconnected"},
+				ArgoRepoServer:     &nextgen.V1AgentComponentHealth{Message: "image pull failed"},
+			},
+			want: "gitops-agent: unauthorized to cluster; repo-server: image pull failed",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, lastError(tc.h)); diff != "" {
+				t.Errorf("\n%s\nlastError(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRecreate(t *testing.T) {
+	account, org := "account", "other"
+	agent := func() *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{
+			ForProvider:       v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org},
+			OnImmutableChange: v1alpha1.ImmutableChangeRecreate,
+		}}
+		meta.SetExternalName(cr, "agent")
+		return cr
+	}
+	harness := func(deleteStatus int, deleted *url.Values) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method == http.MethodDelete {
+				*deleted = r.URL.Query()
+				w.WriteHeader(deleteStatus)
+				_, _ = w.Write([]byte(`{}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","orgIdentifier":"platform","health":{}}`))
+		}
+	}
+
+	observedScope := url.Values{"accountIdentifier": {"account"}, "orgIdentifier": {"platform"}, "routingId": {"account"}}
+
+	type want struct {
+		o       managed.ExternalObservation
+		c       xpv1.Condition
+		deleted url.Values
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason       string
+		policy       xpv1.ManagementPolicy
+		deleteStatus int
+		want         want
+	}{
+		"ObserveOnly": {
+			reason: "An observe only agent whose immutable fields changed should be reported as out of date, but not deleted.",
+			policy: xpv1.ManagementObserveOnly,
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				c: xpv1.Condition{Type: v1alpha1.TypeRecreating, Status: corev1.ConditionUnknown},
+			},
+		},
+		"Recreate": {
+			reason:       "An agent whose immutable fields changed should be deleted from the scope it was observed in, and reported as absent so it is recreated.",
+			deleteStatus: http.StatusOK,
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: false},
+				c:       v1alpha1.Recreating(fmt.Sprintf(msgRecreating, "orgIdentifier")),
+				deleted: observedScope,
+			},
+		},
+		"AlreadyDeleted": {
+			reason:       "An agent that is already gone should be recreated.",
+			deleteStatus: http.StatusNotFound,
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: false},
+				c:       v1alpha1.Recreating(fmt.Sprintf(msgRecreating, "orgIdentifier")),
+				deleted: observedScope,
+			},
+		},
+		"DeleteFailed": {
+			reason:       "Failing to delete the agent should be returned without reporting it as recreating.",
+			deleteStatus: http.StatusInternalServerError,
+			want: want{
+				c:       xpv1.Condition{Type: v1alpha1.TypeRecreating, Status: corev1.ConditionUnknown},
+				deleted: observedScope,
+				err:     errors.Wrap(errors.New("500 Internal Server Error"), errRecreateAgent),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var deleted url.Values
+			cr := agent()
+			cr.SetManagementPolicy(tc.policy)
+			e := external{service: clientstest.NewService(t, harness(tc.deleteStatus, &deleted))}
+			t.Cleanup(func() { forgetHealth("agent", scopeOf(cr.Spec.ForProvider)) })
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, cr.GetCondition(v1alpha1.TypeRecreating), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want delete query, +got delete query:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveHealth(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	heartbeat := func(t time.Time) *metav1.Time {
+		mt := metav1.NewTime(t)
+		return &mt
+	}
+	healthy, unhealthy := nextgen.HEALTHY_Servicev1HealthStatus, nextgen.UNHEALTHY_Servicev1HealthStatus
+	connected := nextgen.CONNECTED_V1ConnectedStatus
+
+	cases := map[string]struct {
+		reason string
+		prev   *v1alpha1.AgentHealth
+		health *nextgen.V1AgentHealth
+		want   *v1alpha1.AgentHealth
+	}{
+		"NoHealth": {
+			reason: "No health should be recorded when Harness reports none.",
+			prev:   &v1alpha1.AgentHealth{ConnectionStatus: "CONNECTED"},
+		},
+		"Components": {
+			reason: "The health of each component should be recorded, preferring Kubernetes errors over other messages.",
+			health: &nextgen.V1AgentHealth{
+				LastHeartbeat:      now,
+				ConnectionStatus:   &connected,
+				HarnessGitopsAgent: &nextgen.V1AgentComponentHealth{Status: &healthy, Version: "0.55.0"},
+				ArgoRepoServer:     &nextgen.V1AgentComponentHealth{Status: &unhealthy, Message: "restarting", K8sError: "ImagePullBackOff"},
+			},
+			want: &v1alpha1.AgentHealth{
+				ConnectionStatus: "CONNECTED",
+				LastHeartbeat:    heartbeat(now),
+				GitOpsAgent:      &v1alpha1.AgentComponentHealth{Status: "HEALTHY", Version: "0.55.0"},
+				RepoServer:       &v1alpha1.AgentComponentHealth{Status: "UNHEALTHY", Message: "ImagePullBackOff"},
+			},
+		},
+		"RecentHeartbeat": {
+			reason: "A heartbeat shortly after the recorded one should not be recorded.",
+			prev:   &v1alpha1.AgentHealth{LastHeartbeat: heartbeat(now.Add(-time.Minute))},
+			health: &nextgen.V1AgentHealth{LastHeartbeat: now},
+			want:   &v1alpha1.AgentHealth{LastHeartbeat: heartbeat(now.Add(-time.Minute))},
+		},
+		"StaleHeartbeat": {
+			reason: "A heartbeat long after the recorded one should be recorded.",
+			prev:   &v1alpha1.AgentHealth{LastHeartbeat: heartbeat(now.Add(-time.Hour))},
+			health: &nextgen.V1AgentHealth{LastHeartbeat: now},
+			want:   &v1alpha1.AgentHealth{LastHeartbeat: heartbeat(now)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Agent{}
+			cr.Status.AtProvider.Health = tc.prev
+			observeHealth(cr, tc.health)
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider.Health); diff != "" {
+				t.Errorf("\n%s\nobserveHealth(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAgentIdentifier(t *testing.T) {
+	requested := "requested"
+	agent := func(identifier *string, externalName string) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{Identifier: identifier}}}
+		if externalName != "" {
+			meta.SetExternalName(cr, externalName)
+		}
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		want   string
+	}{
+		"Requested": {
+			reason: "The identifier the spec requests should take precedence.",
+			cr:     agent(&requested, "assigned"),
+			want:   requested,
+		},
+		"Assigned": {
+			reason: "The identifier Harness assigned should be read from the external name.",
+			cr:     agent(nil, "assigned"),
+			want:   "assigned",
+		},
+		"NotCreated": {
+			reason: "An Agent whose agent was never created should have no identifier.",
+			cr:     agent(nil, ""),
+			want:   "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, agentIdentifier(tc.cr)); diff != "" {
+				t.Errorf("\n%s\nagentIdentifier(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAgentName(t *testing.T) {
+	requested, empty := "requested", ""
+	agent := func(name *string) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{Name: name}}}
+		cr.SetName("example")
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		want   string
+	}{
+		"Requested": {
+			reason: "The name the spec requests should take precedence.",
+			cr:     agent(&requested),
+			want:   requested,
+		},
+		"Unspecified": {
+			reason: "The Agent's own name should be used if the spec requests none.",
+			cr:     agent(nil),
+			want:   "example",
+		},
+		"Empty": {
+			reason: "An empty name should be treated as unspecified.",
+			cr:     agent(&empty),
+			want:   "example",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, agentName(tc.cr)); diff != "" {
+				t.Errorf("\n%s\nagentName(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	account := "account"
+	agent := func(externalName string) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		cr.SetName("existing")
+		if externalName != "" {
+			meta.SetExternalName(cr, externalName)
+		}
+		return cr
+	}
+
+	type want struct {
+		o          managed.ExternalObservation
+		identifier string
+		gets       bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		want   want
+	}{
+		"Adopt": {
+			reason: "An Agent whose external name is the identifier of an existing agent should adopt that agent.",
+			cr:     agent("existing"),
+			want: want{
+				o:          managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				identifier: "existing",
+				gets:       true,
+			},
+		},
+		"NoIdentifier": {
+			reason: "An Agent without an identifier should be reported as absent without asking Harness.",
+			cr:     agent(""),
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			gets := false
+			harness := func(w http.ResponseWriter, r *http.Request) {
+				// Adopting an agent must not create or change it.
+				if r.Method != http.MethodGet {
+					t.Errorf("unexpected %s request for %s", r.Method, r.URL.Path)
+				}
+				gets = true
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"identifier":"existing","name":"existing","accountIdentifier":"account","health":{}}`))
+			}
+			e := external{service: clientstest.NewService(t, harness)}
+			t.Cleanup(func() { forgetHealth("existing", account) })
+			got, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.identifier, tc.cr.Status.AtProvider.Identifier); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want identifier, +got identifier:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.gets, gets); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want Harness asked, +got Harness asked:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultScope(t *testing.T) {
+	account, org := "account", "org"
+	pc := func(d *apisv1alpha1.Scope) *apisv1alpha1.ProviderConfig {
+		return &apisv1alpha1.ProviderConfig{Spec: apisv1alpha1.ProviderConfigSpec{DefaultScope: d}}
+	}
+	defaults := &apisv1alpha1.Scope{AccountIdentifier: "default", OrgIdentifier: "platform"}
+
+	type want struct {
+		changed bool
+		params  v1alpha1.AgentParameters
+	}
+
+	cases := map[string]struct {
+		reason string
+		mg     resource.Managed
+		pc     *apisv1alpha1.ProviderConfig
+		want   want
+	}{
+		"NoDefaults": {
+			reason: "An Agent should be unchanged if its ProviderConfig has no default scope.",
+			mg:     &v1alpha1.Agent{},
+			pc:     pc(nil),
+		},
+		"Agent": {
+			reason: "An Agent that specifies no scope should be defaulted to the ProviderConfig's.",
+			mg:     &v1alpha1.Agent{},
+			pc:     pc(defaults),
+			want: want{
+				changed: true,
+				params:  v1alpha1.AgentParameters{AccountIdentifier: &defaults.AccountIdentifier, OrgIdentifier: &defaults.OrgIdentifier},
+			},
+		},
+		"NamespacedAgent": {
+			reason: "A NamespacedAgent that specifies no scope should be defaulted to the ProviderConfig's.",
+			mg:     &v1alpha1.NamespacedAgent{},
+			pc:     pc(defaults),
+			want: want{
+				changed: true,
+				params:  v1alpha1.AgentParameters{AccountIdentifier: &defaults.AccountIdentifier, OrgIdentifier: &defaults.OrgIdentifier},
+			},
+		},
+		"Specified": {
+			reason: "An Agent that specifies its scope should keep it.",
+			mg:     &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org}}},
+			pc:     pc(defaults),
+			want:   want{params: v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			changed := defaultScope(tc.mg, tc.pc)
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\ndefaultScope(...): -want changed, +got changed:\n%s\n", tc.reason, diff)
+			}
+			var got v1alpha1.AgentParameters
+			switch cr := tc.mg.(type) {
+			case *v1alpha1.Agent:
+				got = cr.Spec.ForProvider
+			case *v1alpha1.NamespacedAgent:
+				got = cr.Spec.ForProvider
+			}
+			if diff := cmp.Diff(tc.want.params, got); diff != "" {
+				t.Errorf("\n%s\ndefaultScope(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateScope(t *testing.T) {
+	org, project, empty := "org", "project", ""
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   error
+	}{
+		"Account": {
+			reason: "An account scoped agent should be valid.",
+		},
+		"AccountWithEmptyIdentifiers": {
+			reason: "An agent with empty organization and project identifiers should be treated as account scoped.",
+			params: v1alpha1.AgentParameters{OrgIdentifier: &empty, ProjectIdentifier: &empty},
+		},
+		"Org": {
+			reason: "An organization scoped agent should be valid.",
+			params: v1alpha1.AgentParameters{OrgIdentifier: &org},
+		},
+		"Project": {
+			reason: "A project scoped agent with its organization should be valid.",
+			params: v1alpha1.AgentParameters{OrgIdentifier: &org, ProjectIdentifier: &project},
+		},
+		"ProjectWithoutOrg": {
+			reason: "A project scoped agent without its organization should be invalid.",
+			params: v1alpha1.AgentParameters{OrgIdentifier: &empty, ProjectIdentifier: &project},
+			want:   errors.Errorf(errProjectWithoutOrg, project),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validateScope(tc.params)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateScope(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDesiredType(t *testing.T) {
+	str := func(s string) *string { return &s }
+	managedType := nextgen.MANAGED_ARGO_PROVIDER_V1AgentType
+	connected := nextgen.CONNECTED_ARGO_PROVIDER_V1AgentType
+
+	type want struct {
+		t   *nextgen.V1AgentType
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   want
+	}{
+		"Unspecified": {
+			reason: "An agent without a type should be created with Harness's default.",
+		},
+		"ManagedArgo": {
+			reason: "A MANAGED_ARGO agent should be created as a managed Argo provider.",
+			params: v1alpha1.AgentParameters{Type: str(v1alpha1.AgentTypeManagedArgo)},
+			want:   want{t: &managedType},
+		},
+		"ConnectedArgo": {
+			reason: "A CONNECTED_ARGO agent should be created as a connected Argo provider.",
+			params: v1alpha1.AgentParameters{Type: str(v1alpha1.AgentTypeConnectedArgo)},
+			want:   want{t: &connected},
+		},
+		"Invalid": {
+			reason: "An agent of an unknown type should be invalid.",
+			params: v1alpha1.AgentParameters{Type: str("ARGO")},
+			want:   want{err: errors.Errorf(errInvalidType, "ARGO")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := desiredType(tc.params)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ndesiredType(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.t, got); diff != "" {
+				t.Errorf("\n%s\ndesiredType(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
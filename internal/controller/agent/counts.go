@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"time"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+// countsResolution is how stale an Agent's repository and cluster counts may
+// become before they are refreshed. Counting requires listing every
+// repository and cluster, so it is done far less often than polling.
+const countsResolution = 30 * time.Minute
+
+// observeCounts refreshes the number of repositories and clusters the
+// supplied agent manages once they are stale. Counts are informational only,
+// so failing to list them leaves the previous counts in place and is retried
+// on the next observation.
+func (c *external) observeCounts(ctx context.Context, cr *v1alpha1.Agent, identifier string, now time.Time) {
+	o := &cr.Status.AtProvider
+	if t := o.CountsObservedAt; t != nil && now.Sub(t.Time) < countsResolution {
+		return
+	}
+
+	rc, err := c.countRepos(ctx, cr.Spec.ForProvider, identifier)
+	if err != nil {
+		return
+	}
+	cc, err := c.countClusters(ctx, cr.Spec.ForProvider, identifier)
+	if err != nil {
+		return
+	}
+
+	o.RepoCount, o.ClusterCount = &rc, &cc
+	t := metav1.NewTime(now)
+	o.CountsObservedAt = &t
+}
+
+func (c *external) countRepos(ctx context.Context, p v1alpha1.AgentParameters, identifier string) (int32, error) {
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceListRepositories(ctx, identifier, *p.AccountIdentifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceListRepositoriesOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return int32(len(rsp.Items)), err
+}
+
+func (c *external) countClusters(ctx context.Context, p v1alpha1.AgentParameters, identifier string) (int32, error) {
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.ClustersApi.AgentClusterServiceList(ctx, identifier, *p.AccountIdentifier,
+		&nextgen.ClustersApiAgentClusterServiceListOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return int32(len(rsp.Items)), err
+}
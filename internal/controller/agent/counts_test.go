@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func TestObserveCounts(t *testing.T) {
+	account := "account"
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	counted := func(repos, clusters int32, at time.Time) v1alpha1.AgentObservation {
+		t := metav1.NewTime(at)
+		return v1alpha1.AgentObservation{RepoCount: &repos, ClusterCount: &clusters, CountsObservedAt: &t}
+	}
+	lists := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/repositories"):
+			_, _ = w.Write([]byte(`{"items":[{},{},{}]}`))
+		case strings.HasSuffix(r.URL.Path, "/clusters"):
+			_, _ = w.Write([]byte(`{"items":[{}]}`))
+		}
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		o       v1alpha1.AgentObservation
+		want    v1alpha1.AgentObservation
+	}{
+		"Unobserved": {
+			reason:  "Counts should be observed if they never have been.",
+			handler: lists,
+			want:    counted(3, 1, now),
+		},
+		"Stale": {
+			reason:  "Counts should be refreshed once they are stale.",
+			handler: lists,
+			o:       counted(0, 0, now.Add(-countsResolution)),
+			want:    counted(3, 1, now),
+		},
+		"Fresh": {
+			reason: "Fresh counts should not be refreshed.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				t.Error("unexpected request for fresh counts")
+			},
+			o:    counted(0, 0, now.Add(-time.Minute)),
+			want: counted(0, 0, now.Add(-time.Minute)),
+		},
+		"ListError": {
+			reason: "Failing to list should keep the previous counts.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			o:    counted(2, 2, now.Add(-countsResolution)),
+			want: counted(2, 2, now.Add(-countsResolution)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+			cr.Status.AtProvider = tc.o
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			e.observeCounts(context.Background(), cr, "agent", now)
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.observeCounts(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
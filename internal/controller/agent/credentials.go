@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+const errGetConnectionSecret = "cannot get connection secret"
+
+// rotatedCredentials returns the connection details of the supplied agent if
+// Harness returned credentials that differ from those in the Agent's
+// connection secret, e.g. because they were regenerated. It returns no
+// connection details if the credentials are unchanged, so that the install
+// manifests are only fetched again when they must be republished.
+//
+// Only the connection secret is checked. Credentials published to an
+// external secret store cannot be read back, and are republished only when
+// the agent is created.
+func (c *external) rotatedCredentials(ctx context.Context, cr *v1alpha1.Agent, agent nextgen.V1Agent) (managed.ConnectionDetails, error) {
+	creds := agent.Credentials
+	if creds == nil || creds.PrivateKey == "" {
+		return nil, nil
+	}
+	ref := cr.GetWriteConnectionSecretToReference()
+	if ref == nil {
+		return nil, nil
+	}
+	s := &corev1.Secret{}
+	err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s)
+	if err != nil && !kerrors.IsNotFound(err) {
+		return nil, errors.Wrap(err, errGetConnectionSecret)
+	}
+	if string(s.Data[ConnectionDetailPrivateKey]) == creds.PrivateKey && string(s.Data[ConnectionDetailPublicKey]) == creds.PublicKey {
+		return nil, nil
+	}
+	c.recordChanged(cr, reasonRotatedCredentials, msgRotatedCredentials, agent.Identifier)
+	return c.connectionDetails(ctx, cr, agent), nil
+}
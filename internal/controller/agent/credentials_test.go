@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func TestRotatedCredentials(t *testing.T) {
+	errBoom := errors.New("boom")
+	rotated := nextgen.V1Agent{Identifier: "agent", AccountIdentifier: "account", Credentials: &nextgen.V1AgentCredentials{PrivateKey: "private", PublicKey: "public"}}
+	withSecret := func(data map[string][]byte) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			obj.(*corev1.Secret).Data = data
+			return nil
+		}
+	}
+	agent := func(ref *xpv1.SecretReference) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{}
+		cr.SetWriteConnectionSecretToReference(ref)
+		return cr
+	}
+	ref := &xpv1.SecretReference{Namespace: "crossplane-system", Name: "agent"}
+	deployYAML := func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/x-yml")
+		_, _ = w.Write([]byte("kind: Deployment"))
+	}
+	published := managed.ConnectionDetails{
+		ConnectionDetailIdentifier:  []byte("agent"),
+		ConnectionDetailPrivateKey:  []byte("private"),
+		ConnectionDetailPublicKey:   []byte("public"),
+		ConnectionDetailInstallYAML: []byte("kind: Deployment"),
+	}
+
+	type want struct {
+		cd  managed.ConnectionDetails
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		get    test.MockGetFn
+		cr     *v1alpha1.Agent
+		agent  nextgen.V1Agent
+		want   want
+	}{
+		"NoCredentials": {
+			reason: "Nothing should be published if Harness returned no credentials.",
+			cr:     agent(ref),
+			agent:  nextgen.V1Agent{Identifier: "agent"},
+		},
+		"NoConnectionSecret": {
+			reason: "Nothing should be published if the Agent does not write a connection secret.",
+			cr:     agent(nil),
+			agent:  rotated,
+		},
+		"Unchanged": {
+			reason: "Nothing should be published if the connection secret holds the returned credentials.",
+			get:    withSecret(map[string][]byte{ConnectionDetailPrivateKey: []byte("private"), ConnectionDetailPublicKey: []byte("public")}),
+			cr:     agent(ref),
+			agent:  rotated,
+		},
+		"Rotated": {
+			reason: "The connection details should be republished if the connection secret holds other credentials.",
+			get:    withSecret(map[string][]byte{ConnectionDetailPrivateKey: []byte("old"), ConnectionDetailPublicKey: []byte("old")}),
+			cr:     agent(ref),
+			agent:  rotated,
+			want:   want{cd: published},
+		},
+		"SecretNotFound": {
+			reason: "The connection details should be republished if the connection secret does not exist.",
+			get:    test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, "agent")),
+			cr:     agent(ref),
+			agent:  rotated,
+			want:   want{cd: published},
+		},
+		"GetError": {
+			reason: "Errors reading the connection secret should be returned.",
+			get:    test.NewMockGetFn(errBoom),
+			cr:     agent(ref),
+			agent:  rotated,
+			want:   want{err: errors.Wrap(errBoom, errGetConnectionSecret)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, deployYAML), kube: &test.MockClient{MockGet: tc.get}}
+			got, err := e.rotatedCredentials(context.Background(), tc.cr, tc.agent)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.rotatedCredentials(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, got); diff != "" {
+				t.Errorf("\n%s\ne.rotatedCredentials(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+// An agentDiff lists the fields in which an observed agent differs from the
+// desired one, classified by whether Harness allows them to be updated.
+type agentDiff struct {
+	// mutable fields may be changed by updating the agent.
+	mutable []string
+
+	// immutable fields cannot be changed once the agent exists.
+	immutable []string
+}
+
+// diffAgent compares a desired agent with an observed one. Fields the
+// desired agent does not specify are not compared.
+func diffAgent(desired, observed nextgen.V1Agent) agentDiff {
+	d := agentDiff{}
+
+	immutable := func(field string, changed bool) {
+		if changed {
+			d.immutable = append(d.immutable, field)
+		}
+	}
+	immutable("accountIdentifier", desired.AccountIdentifier != observed.AccountIdentifier)
+	immutable("orgIdentifier", desired.OrgIdentifier != observed.OrgIdentifier)
+	immutable("projectIdentifier", desired.ProjectIdentifier != observed.ProjectIdentifier)
+	immutable("type", desired.Type_ != nil && (observed.Type_ == nil || *desired.Type_ != *observed.Type_))
+
+	mutable := func(field string, changed bool) {
+		if changed {
+			d.mutable = append(d.mutable, field)
+		}
+	}
+	mutable("name", desired.Name != observed.Name)
+	mutable("description", desired.Description != observed.Description)
+	mutable("tags", !clients.TagsEqual(desired.Tags, observed.Tags))
+
+	return d
+}
+
+// upToDate returns true if the observed agent matches the desired one.
+func (d agentDiff) upToDate() bool {
+	return len(d.mutable) == 0 && len(d.immutable) == 0
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+)
+
+func TestDiffAgent(t *testing.T) {
+	connected := nextgen.CONNECTED_ARGO_PROVIDER_V1AgentType
+	managedType := nextgen.MANAGED_ARGO_PROVIDER_V1AgentType
+
+	base := func(mod ...func(a *nextgen.V1Agent)) nextgen.V1Agent {
+		a := nextgen.V1Agent{
+			AccountIdentifier: "account",
+			OrgIdentifier:     "org",
+			ProjectIdentifier: "project",
+			Name:              "agent",
+			Description:       "An agent.",
+			Tags:              map[string]string{"team": "platform"},
+			Type_:             &managedType,
+		}
+		for _, m := range mod {
+			m(&a)
+		}
+		return a
+	}
+
+	cases := map[string]struct {
+		reason   string
+		desired  nextgen.V1Agent
+		observed nextgen.V1Agent
+		want     agentDiff
+	}{
+		"UpToDate": {
+			reason:   "Identical agents should have no differences.",
+			desired:  base(),
+			observed: base(),
+			want:     agentDiff{},
+		},
+		"Unspecified": {
+			reason:   "Fields the desired agent does not specify should not be compared.",
+			desired:  base(func(a *nextgen.V1Agent) { a.Type_ = nil }),
+			observed: base(),
+			want:     agentDiff{},
+		},
+		"MutableOnly": {
+			reason: "Tag, description and name changes should be classified as mutable.",
+			desired: base(func(a *nextgen.V1Agent) {
+				a.Tags = map[string]string{"team": "payments"}
+				a.Description = "Changed."
+				a.Name = "renamed"
+			}),
+			observed: base(),
+			want:     agentDiff{mutable: []string{"name", "description", "tags"}},
+		},
+		"TagsOnly": {
+			reason:   "Tag-only drift should never be classified as immutable.",
+			desired:  base(),
+			observed: base(func(a *nextgen.V1Agent) { a.Tags = nil }),
+			want:     agentDiff{mutable: []string{"tags"}},
+		},
+		"ImmutableOnly": {
+			reason: "Account, scope and type changes should be classified as immutable.",
+			desired: base(func(a *nextgen.V1Agent) {
+				a.AccountIdentifier = "other"
+				a.OrgIdentifier = ""
+				a.ProjectIdentifier = ""
+			}),
+			observed: base(func(a *nextgen.V1Agent) { a.Type_ = &connected }),
+			want:     agentDiff{immutable: []string{"accountIdentifier", "orgIdentifier", "projectIdentifier", "type"}},
+		},
+		"Mixed": {
+			reason:   "Mutable and immutable changes should be classified separately.",
+			desired:  base(func(a *nextgen.V1Agent) { a.ProjectIdentifier = "other"; a.Tags = nil }),
+			observed: base(),
+			want:     agentDiff{mutable: []string{"tags"}, immutable: []string{"projectIdentifier"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := diffAgent(tc.desired, tc.observed)
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(agentDiff{})); diff != "" {
+				t.Errorf("\n%s\ndiffAgent(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// AnnotationKeyDryRun puts an Agent in dry run mode. The agent is observed as
+// usual, but the changes the provider would make to it are recorded in the
+// Agent's DryRun condition and logged rather than made.
+const AnnotationKeyDryRun = "harness.crossplane.io/dry-run"
+
+const (
+	msgDryRunCreate   = "dry run: would create agent %q in %s"
+	msgDryRunUpdate   = "dry run: would update agent %q in %s because %s"
+	msgDryRunDelete   = "dry run: would delete agent %q from %s"
+	msgDryRunRecreate = "dry run: would delete agent %q from %s and create it again to change immutable fields %s"
+)
+
+// dryRun returns true if the supplied Agent is in dry run mode.
+func dryRun(cr *v1alpha1.Agent) bool {
+	return cr.GetAnnotations()[AnnotationKeyDryRun] == "true"
+}
+
+// preview records a change the provider would have made to the supplied
+// Agent's agent were it not in dry run mode.
+func (c *external) preview(cr *v1alpha1.Agent, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	c.log().Info(msg, "name", cr.GetName())
+	cr.Status.SetConditions(v1alpha1.ChangePending(msg))
+}
+
+// settleDryRun reports that the supplied Agent has no pending change once its
+// agent is up to date, or once the Agent is no longer in dry run mode.
+func settleDryRun(cr *v1alpha1.Agent, upToDate bool) {
+	pending := cr.GetCondition(v1alpha1.TypeDryRun).Status == corev1.ConditionTrue
+	switch {
+	case dryRun(cr) && upToDate, !dryRun(cr) && pending:
+		cr.Status.SetConditions(v1alpha1.NoChangePending())
+	}
+}
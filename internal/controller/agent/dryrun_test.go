@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func TestDryRun(t *testing.T) {
+	account, org := "account", "org"
+	agent := func() *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org}}}
+		cr.SetName("example")
+		cr.SetAnnotations(map[string]string{AnnotationKeyDryRun: "true"})
+		return cr
+	}
+	// A dry run must not change anything in Harness. The agent it reads
+	// differs from the desired one in name.
+	readOnly := func(t *testing.T) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet {
+				t.Errorf("unexpected %s request for %s", r.Method, r.URL.Path)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if strings.HasSuffix(r.URL.Path, "/repositories") || strings.HasSuffix(r.URL.Path, "/clusters") {
+				_, _ = w.Write([]byte(`{"items":[]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"identifier":"agent","accountIdentifier":"account","orgIdentifier":"org","name":"other","metadata":{"highAvailability":true},"health":{}}`))
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		op     func(e *external, cr *v1alpha1.Agent) error
+		want   string
+	}{
+		"Create": {
+			reason: "A dry run of Create should report the agent it would create without creating it.",
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				_, err := e.Create(context.Background(), cr)
+				return err
+			},
+			want: `dry run: would create agent "example" in account/org`,
+		},
+		"Update": {
+			reason: "A dry run should report how the agent differs, and Update should not update it.",
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				meta.SetExternalName(cr, "agent")
+				if _, err := e.Observe(context.Background(), cr); err != nil {
+					return err
+				}
+				_, err := e.Update(context.Background(), cr)
+				return err
+			},
+			want: `dry run: would update agent "agent" in account/org because the agent in Harness differs from the desired state in name (desired "example", observed "other")`,
+		},
+		"Delete": {
+			reason: "A dry run of Delete should report the agent it would delete without deleting it or its dependents.",
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				meta.SetExternalName(cr, "agent")
+				e.dependentGC = true
+				return e.Delete(context.Background(), cr)
+			},
+			want: `dry run: would delete agent "agent" from account/org`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{MockDeleteAllOf: test.NewMockDeleteAllOfFn(errors.New("unexpected delete"))}
+			e := &external{service: clientstest.NewService(t, readOnly(t)), kube: kube}
+			cr := agent()
+			if err := tc.op(e, cr); err != nil {
+				t.Fatalf("\n%s\n%s: %v", tc.reason, name, err)
+			}
+			if diff := cmp.Diff(v1alpha1.ChangePending(tc.want), cr.GetCondition(v1alpha1.TypeDryRun), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\n%s: -want condition, +got condition:\n%s\n", tc.reason, name, diff)
+			}
+		})
+	}
+}
+
+func TestSettleDryRun(t *testing.T) {
+	agent := func(dryRun bool, c ...xpv1.Condition) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{}
+		if dryRun {
+			cr.SetAnnotations(map[string]string{AnnotationKeyDryRun: "true"})
+		}
+		cr.SetConditions(c...)
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason   string
+		cr       *v1alpha1.Agent
+		upToDate bool
+		want     xpv1.Condition
+	}{
+		"NotDryRun": {
+			reason: "An Agent that never was in dry run mode should not report a DryRun condition.",
+			cr:     agent(false),
+			want:   xpv1.Condition{Type: v1alpha1.TypeDryRun, Status: corev1.ConditionUnknown},
+		},
+		"UpToDate": {
+			reason:   "An up to date Agent in dry run mode should report no pending change.",
+			cr:       agent(true, v1alpha1.ChangePending("dry run: would update")),
+			upToDate: true,
+			want:     v1alpha1.NoChangePending(),
+		},
+		"StillPending": {
+			reason: "An out of date Agent in dry run mode should keep reporting its pending change.",
+			cr:     agent(true, v1alpha1.ChangePending("dry run: would update")),
+			want:   v1alpha1.ChangePending("dry run: would update"),
+		},
+		"DryRunEnded": {
+			reason: "An Agent no longer in dry run mode should no longer report a pending change.",
+			cr:     agent(false, v1alpha1.ChangePending("dry run: would update")),
+			want:   v1alpha1.NoChangePending(),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			settleDryRun(tc.cr, tc.upToDate)
+			if diff := cmp.Diff(tc.want, tc.cr.GetCondition(v1alpha1.TypeDryRun), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nsettleDryRun(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+// Reasons of the events emitted when an agent is changed in Harness. The
+// managed reconciler emits generic events for the same operations; these
+// add the agent's identifier and scope, and the error Harness reported.
+const (
+	reasonCreatedAgent      event.Reason = "CreatedAgent"
+	reasonUpdatedAgent      event.Reason = "UpdatedAgent"
+	reasonDeletedAgent      event.Reason = "DeletedAgent"
+	reasonCannotCreateAgent event.Reason = "CannotCreateAgent"
+	reasonCannotUpdateAgent event.Reason = "CannotUpdateAgent"
+	reasonCannotDeleteAgent event.Reason = "CannotDeleteAgent"
+
+	reasonRotatedCredentials event.Reason = "RotatedCredentials"
+)
+
+const (
+	msgCreatedAgent       = "created agent %q in %s"
+	msgUpdatedAgent       = "updated agent %q in %s"
+	msgDeletedAgent       = "deleted agent %q from %s"
+	msgRotatedCredentials = "published rotated credentials of agent %q in %s"
+	msgCannotChangeAgent  = "Harness rejected the request for agent %q in %s: %s"
+)
+
+// recordChanged emits a Normal event recording that the supplied agent was
+// changed in Harness.
+func (c *external) recordChanged(cr *v1alpha1.Agent, reason event.Reason, format, identifier string) {
+	c.events().Event(cr, event.Normal(reason, fmt.Sprintf(format, identifier, scopeOf(cr.Spec.ForProvider))))
+}
+
+// recordAPIError emits a Warning event recording the error Harness returned
+// for a request to change the supplied agent.
+func (c *external) recordAPIError(cr *v1alpha1.Agent, reason event.Reason, identifier string, err error) {
+	c.events().Event(cr, event.Warning(reason, errors.Errorf(msgCannotChangeAgent, identifier, scopeOf(cr.Spec.ForProvider), clients.ErrorMessage(err))))
+}
+
+func (c *external) events() event.Recorder {
+	if c.recorder == nil {
+		return event.NewNopRecorder()
+	}
+	return c.recorder
+}
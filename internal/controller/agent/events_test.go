@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func TestEvents(t *testing.T) {
+	account, org := "account", "org"
+	agent := func() *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org}}}
+		meta.SetExternalName(cr, "agent")
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		op      func(e *external, cr *v1alpha1.Agent) error
+		want    []event.Event
+	}{
+		"Created": {
+			reason:  "Creating an agent should emit a Normal event naming the agent and its scope.",
+			handler: clientstest.Respond(http.StatusOK, `{"identifier":"agent"}`),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				_, err := e.Create(context.Background(), cr)
+				return err
+			},
+			want: []event.Event{event.Normal(reasonCreatedAgent, `created agent "agent" in account/org`)},
+		},
+		"CannotCreate": {
+			reason:  "An error Harness returns when creating an agent should be emitted as a Warning event.",
+			handler: clientstest.Respond(http.StatusBadRequest, `{"message":"invalid agent"}`),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				_, err := e.Create(context.Background(), cr)
+				return err
+			},
+			want: []event.Event{event.Warning(reasonCannotCreateAgent, errors.New(`Harness rejected the request for agent "agent" in account/org: invalid agent`))},
+		},
+		"Updated": {
+			reason:  "Updating an agent should emit a Normal event.",
+			handler: clientstest.Respond(http.StatusOK, `{"identifier":"agent"}`),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				_, err := e.Update(context.Background(), cr)
+				return err
+			},
+			want: []event.Event{event.Normal(reasonUpdatedAgent, `updated agent "agent" in account/org`)},
+		},
+		"Deleted": {
+			reason:  "Deleting an agent should emit a Normal event.",
+			handler: clientstest.Respond(http.StatusOK, `{}`),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				return e.Delete(context.Background(), cr)
+			},
+			want: []event.Event{event.Normal(reasonDeletedAgent, `deleted agent "agent" from account/org`)},
+		},
+		"CannotDelete": {
+			reason:  "An error Harness returns when deleting an agent should be emitted as a Warning event.",
+			handler: clientstest.Respond(http.StatusInternalServerError, `{"message":"try again"}`),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				return e.Delete(context.Background(), cr)
+			},
+			want: []event.Event{event.Warning(reasonCannotDeleteAgent, errors.New(`Harness rejected the request for agent "agent" in account/org: try again`))},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := &eventRecorder{}
+			e := &external{service: clientstest.NewService(t, tc.handler), recorder: r}
+			_ = tc.op(e, agent())
+			if diff := cmp.Diff(tc.want, r.events); diff != "" {
+				t.Errorf("\n%s\nevents: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
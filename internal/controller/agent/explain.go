@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// AnnotationKeyExplain opts an Agent in to having the controller record a
+// summary of its last reconcile decision in status.atProvider.explanation.
+const AnnotationKeyExplain = "harness.crossplane.io/explain"
+
+// maxExplanationLength bounds the length of a recorded explanation, so that a
+// large diff cannot bloat the Agent's status.
+const maxExplanationLength = 1024
+
+const (
+	explainAbsent     = "the agent does not exist in Harness"
+	explainUpToDate   = "the agent in Harness matches the desired state"
+	explainOutOfDate  = "the agent in Harness differs from the desired state in %s"
+	explainImmutable  = "the agent in Harness differs from the desired state in %s, which cannot be updated"
+	explainDeleting   = "the Agent is being deleted and its agent still exists in Harness"
+	explainCreated    = "created the agent in Harness because %s"
+	explainUpdated    = "updated the agent in Harness because %s"
+	explainRedacted   = "%s (values redacted)"
+	explainFieldValue = "%s (desired %q, observed %q)"
+)
+
+// explain records the supplied explanation if the Agent opted in to it, and
+// clears any previously recorded explanation if it did not.
+func explain(cr *v1alpha1.Agent, msg string) {
+	if cr.GetAnnotations()[AnnotationKeyExplain] != "true" {
+		cr.Status.AtProvider.Explanation = ""
+		return
+	}
+	if r := []rune(msg); len(r) > maxExplanationLength {
+		msg = string(r[:maxExplanationLength-1]) + "…"
+	}
+	cr.Status.AtProvider.Explanation = msg
+}
+
+// explainDiff summarizes how an observed agent differs from the desired one.
+// Descriptions and tags may hold sensitive values, so only the fact that they
+// differ is reported.
+func explainDiff(desired, observed nextgen.V1Agent, d agentDiff) string {
+	switch {
+	case len(d.immutable) > 0:
+		return fmt.Sprintf(explainImmutable, describeFields(desired, observed, d.immutable))
+	case len(d.mutable) > 0:
+		return fmt.Sprintf(explainOutOfDate, describeFields(desired, observed, d.mutable))
+	}
+	return explainUpToDate
+}
+
+func describeFields(desired, observed nextgen.V1Agent, fields []string) string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		switch f {
+		case "accountIdentifier":
+			out = append(out, fmt.Sprintf(explainFieldValue, f, desired.AccountIdentifier, observed.AccountIdentifier))
+		case "orgIdentifier":
+			out = append(out, fmt.Sprintf(explainFieldValue, f, desired.OrgIdentifier, observed.OrgIdentifier))
+		case "projectIdentifier":
+			out = append(out, fmt.Sprintf(explainFieldValue, f, desired.ProjectIdentifier, observed.ProjectIdentifier))
+		case "name":
+			out = append(out, fmt.Sprintf(explainFieldValue, f, desired.Name, observed.Name))
+		case "type":
+			out = append(out, fmt.Sprintf(explainFieldValue, f, agentType(desired.Type_), agentType(observed.Type_)))
+		default:
+			out = append(out, fmt.Sprintf(explainRedacted, f))
+		}
+	}
+	return strings.Join(out, ", ")
+}
+
+func agentType(t *nextgen.V1AgentType) string {
+	if t == nil {
+		return ""
+	}
+	return string(*t)
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestExplainDiff(t *testing.T) {
+	desired := nextgen.V1Agent{
+		AccountIdentifier: "account",
+		Name:              "agent",
+		Description:       "secret description",
+		Tags:              map[string]string{"token": "secret"},
+	}
+
+	cases := map[string]struct {
+		reason   string
+		observed nextgen.V1Agent
+		want     string
+	}{
+		"UpToDate": {
+			reason:   "An up to date agent should be explained as such.",
+			observed: desired,
+			want:     explainUpToDate,
+		},
+		"OutOfDate": {
+			reason: "Differing names should be shown, while descriptions and tags should be redacted.",
+			observed: nextgen.V1Agent{
+				AccountIdentifier: "account",
+				Name:              "renamed",
+				Description:       "other description",
+			},
+			want: `the agent in Harness differs from the desired state in name (desired "agent", observed "renamed"), description (values redacted), tags (values redacted)`,
+		},
+		"Immutable": {
+			reason: "Immutable differences should be explained as not updatable.",
+			observed: nextgen.V1Agent{
+				AccountIdentifier: "other",
+				Name:              "agent",
+				Description:       "secret description",
+				Tags:              map[string]string{"token": "secret"},
+			},
+			want: `the agent in Harness differs from the desired state in accountIdentifier (desired "account", observed "other"), which cannot be updated`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := explainDiff(desired, tc.observed, diffAgent(desired, tc.observed))
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nexplainDiff(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExplain(t *testing.T) {
+	agent := func(annotation, explanation string) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{}
+		if annotation != "" {
+			cr.SetAnnotations(map[string]string{AnnotationKeyExplain: annotation})
+		}
+		cr.Status.AtProvider.Explanation = explanation
+		return cr
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		msg    string
+		want   string
+	}{
+		"OptedIn": {
+			reason: "An explanation should be recorded for Agents that opted in.",
+			cr:     agent("true", ""),
+			msg:    explainUpToDate,
+			want:   explainUpToDate,
+		},
+		"OptedOut": {
+			reason: "A previously recorded explanation should be cleared once an Agent opts out.",
+			cr:     agent("", explainAbsent),
+			msg:    explainUpToDate,
+			want:   "",
+		},
+		"Bounded": {
+			reason: "A long explanation should be truncated.",
+			cr:     agent("true", ""),
+			msg:    strings.Repeat("x", maxExplanationLength+1),
+			want:   strings.Repeat("x", maxExplanationLength-1) + "…",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			explain(tc.cr, tc.msg)
+			if diff := cmp.Diff(tc.want, tc.cr.Status.AtProvider.Explanation); diff != "" {
+				t.Errorf("\n%s\nexplain(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
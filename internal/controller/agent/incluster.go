@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// In-cluster states of an agent's Deployment.
+const (
+	inClusterReady    = "Ready"
+	inClusterNotReady = "NotReady"
+	inClusterNotFound = "NotFound"
+)
+
+const (
+	defaultAgentDeployment = "gitops-agent"
+
+	errGetDeployment = "cannot get in-cluster agent Deployment"
+)
+
+// observeInCluster returns the readiness of the supplied agent's in-cluster
+// Deployment, or an empty string if it is not checked.
+func (c *external) observeInCluster(ctx context.Context, p v1alpha1.AgentParameters) (string, error) {
+	ref := p.InClusterDeployment
+	if c.inCluster == nil || ref == nil {
+		return "", nil
+	}
+	name := ref.Name
+	if name == "" {
+		name = defaultAgentDeployment
+	}
+
+	d := &appsv1.Deployment{}
+	if err := c.inCluster.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: name}, d); err != nil {
+		if kerrors.IsNotFound(err) {
+			return inClusterNotFound, nil
+		}
+		return "", errors.Wrap(err, errGetDeployment)
+	}
+	if d.Status.ReadyReplicas < 1 {
+		return inClusterNotReady, nil
+	}
+	return inClusterReady, nil
+}
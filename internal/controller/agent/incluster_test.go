@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestObserveInCluster(t *testing.T) {
+	errBoom := errors.New("boom")
+	ref := &v1alpha1.DeploymentReference{Namespace: "argocd"}
+	withReady := func(replicas int32) test.MockGetFn {
+		return func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			if key.Name != defaultAgentDeployment || key.Namespace != "argocd" {
+				return errors.Errorf("unexpected key %s", key)
+			}
+			obj.(*appsv1.Deployment).Status.ReadyReplicas = replicas
+			return nil
+		}
+	}
+
+	type args struct {
+		reader client.Reader
+		params v1alpha1.AgentParameters
+	}
+	type want struct {
+		state string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Disabled": {
+			reason: "Nothing should be checked when in-cluster agent health is disabled.",
+			args:   args{params: v1alpha1.AgentParameters{InClusterDeployment: ref}},
+		},
+		"NoDeployment": {
+			reason: "Nothing should be checked for agents without an in-cluster Deployment.",
+			args:   args{reader: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)}},
+		},
+		"Ready": {
+			reason: "A Deployment with ready replicas should be reported as ready.",
+			args: args{
+				reader: &test.MockClient{MockGet: withReady(1)},
+				params: v1alpha1.AgentParameters{InClusterDeployment: ref},
+			},
+			want: want{state: inClusterReady},
+		},
+		"NotReady": {
+			reason: "A Deployment without ready replicas should be reported as not ready.",
+			args: args{
+				reader: &test.MockClient{MockGet: withReady(0)},
+				params: v1alpha1.AgentParameters{InClusterDeployment: ref},
+			},
+			want: want{state: inClusterNotReady},
+		},
+		"NotFound": {
+			reason: "A missing Deployment should be reported as not found.",
+			args: args{
+				reader: &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, defaultAgentDeployment))},
+				params: v1alpha1.AgentParameters{InClusterDeployment: ref},
+			},
+			want: want{state: inClusterNotFound},
+		},
+		"GetError": {
+			reason: "Errors getting the Deployment should be returned.",
+			args: args{
+				reader: &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+				params: v1alpha1.AgentParameters{InClusterDeployment: ref},
+			},
+			want: want{err: errors.Wrap(errBoom, errGetDeployment)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{inCluster: tc.args.reader}
+			got, err := e.observeInCluster(context.Background(), tc.args.params)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.observeInCluster(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.state, got); diff != "" {
+				t.Errorf("\n%s\ne.observeInCluster(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// lateInitialize fills the supplied optional parameters that are unset from
+// the observed agent, so that values Harness defaults are not blanked out by
+// updates. Tags the observed agent has only because they are default tags of
+// the ProviderConfig are not late initialized. It returns true if it changed
+// any parameter.
+func lateInitialize(p *v1alpha1.AgentParameters, observed nextgen.V1Agent, defaultTags map[string]string) bool {
+	changed := false
+	if p.Description == nil && p.DescriptionFrom == nil && observed.Description != "" {
+		d := observed.Description
+		p.Description = &d
+		changed = true
+	}
+	if p.Tags == nil {
+		tags := map[string]string{}
+		for k, v := range observed.Tags {
+			if dv, ok := defaultTags[k]; ok && dv == v {
+				continue
+			}
+			tags[k] = v
+		}
+		if len(tags) > 0 {
+			p.Tags = &tags
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestLateInitialize(t *testing.T) {
+	desc, other := "observed", "desired"
+	tags := map[string]string{"team": "platform"}
+	observed := nextgen.V1Agent{
+		Description: desc,
+		Tags:        map[string]string{"team": "platform", "managed-by": "crossplane"},
+	}
+
+	type want struct {
+		p       v1alpha1.AgentParameters
+		changed bool
+	}
+	cases := map[string]struct {
+		reason   string
+		p        v1alpha1.AgentParameters
+		observed nextgen.V1Agent
+		want     want
+	}{
+		"Unset": {
+			reason:   "Unset optional parameters should be filled from the observed agent, except for default tags.",
+			observed: observed,
+			want: want{
+				p:       v1alpha1.AgentParameters{Description: &desc, Tags: &tags},
+				changed: true,
+			},
+		},
+		"Set": {
+			reason:   "Parameters that are set should not be changed.",
+			p:        v1alpha1.AgentParameters{Description: &other, Tags: &map[string]string{}},
+			observed: observed,
+			want:     want{p: v1alpha1.AgentParameters{Description: &other, Tags: &map[string]string{}}},
+		},
+		"DescriptionFrom": {
+			reason:   "The description should not be late initialized if it is sourced from a ConfigMap.",
+			p:        v1alpha1.AgentParameters{DescriptionFrom: &v1alpha1.ConfigMapKeySelector{}, Tags: &tags},
+			observed: observed,
+			want:     want{p: v1alpha1.AgentParameters{DescriptionFrom: &v1alpha1.ConfigMapKeySelector{}, Tags: &tags}},
+		},
+		"NothingObserved": {
+			reason: "Nothing should be late initialized from an agent without a description or tags.",
+			want:   want{p: v1alpha1.AgentParameters{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			changed := lateInitialize(&tc.p, tc.observed, map[string]string{"managed-by": "crossplane"})
+			if diff := cmp.Diff(tc.want.changed, changed); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want changed, +got changed:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.p, tc.p); diff != "" {
+				t.Errorf("\n%s\nlateInitialize(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
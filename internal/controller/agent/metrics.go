@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"strings"
+	"time"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// Agent health metrics. Each series is labeled by the agent identifier and its
+// account/org/project scope, so there is exactly one series per Agent and it
+// must be deleted along with the Agent to avoid leaking label values.
+var (
+	heartbeatAge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "harness",
+		Subsystem: "gitops_agent",
+		Name:      "heartbeat_age_seconds",
+		Help:      "Seconds since the Harness GitOps agent last sent a heartbeat.",
+	}, []string{"identifier", "scope"})
+
+	healthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "harness",
+		Subsystem: "gitops_agent",
+		Name:      "healthy",
+		Help:      "Whether Harness reports the GitOps agent as healthy (1) or not (0).",
+	}, []string{"identifier", "scope"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(heartbeatAge, healthy)
+}
+
+// scopeOf returns the account/org/project path an Agent is scoped to.
+func scopeOf(p v1alpha1.AgentParameters) string {
+	parts := make([]string, 0, 3)
+	for _, s := range []*string{p.AccountIdentifier, p.OrgIdentifier, p.ProjectIdentifier} {
+		if s != nil && *s != "" {
+			parts = append(parts, *s)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// recordHealth updates the health metrics of the supplied agent.
+func recordHealth(identifier, scope string, h *nextgen.V1AgentHealth, now time.Time) {
+	up := 0.0
+	if h != nil && h.HarnessGitopsAgent != nil && h.HarnessGitopsAgent.Status != nil &&
+		*h.HarnessGitopsAgent.Status == nextgen.HEALTHY_Servicev1HealthStatus {
+		up = 1
+	}
+	healthy.WithLabelValues(identifier, scope).Set(up)
+
+	if h == nil || h.LastHeartbeat.IsZero() {
+		heartbeatAge.DeleteLabelValues(identifier, scope)
+		return
+	}
+	heartbeatAge.WithLabelValues(identifier, scope).Set(now.Sub(h.LastHeartbeat).Seconds())
+}
+
+// forgetHealth removes the health metrics of the supplied agent.
+func forgetHealth(identifier, scope string) {
+	healthy.DeleteLabelValues(identifier, scope)
+	heartbeatAge.DeleteLabelValues(identifier, scope)
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/details"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotNamespacedAgent = "managed resource is not a NamespacedAgent custom resource"
+	errForeignReferences  = "%s must reference objects in the NamespacedAgent's own namespace %q"
+)
+
+// SetupNamespaced adds a controller that reconciles NamespacedAgent managed
+// resources.
+func SetupNamespaced(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.NamespacedAgentGroupKind))
+
+	cps := []managed.ConnectionPublisher{details.NewPublisher(managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme()))}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, details.NewPublisher(connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind)))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.NamespacedAgentGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&namespacedConnector{connector: &connector{
+			kube:         mgr.GetClient(),
+			recorder:     recorder,
+			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newServiceFn: clients.NewServiceCache(clients.NewService).NewService,
+			secretStores: o.Features.Enabled(features.EnableAlphaExternalSecretStores),
+			inCluster:    inClusterReader(mgr, o),
+			licenses:     clients.NewLicenseCache(clients.DefaultLicenseTTL),
+			logger:       o.Logger.WithValues("controller", name),
+		}}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.NamespacedAgentKind)),
+		o.ManagementPolicies(),
+		managed.WithInitializers(),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.NamespacedAgent{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A namespacedConnector connects to Harness on behalf of a NamespacedAgent,
+// refusing to use a ProviderConfig that does not allow its namespace, or to
+// reference objects in other namespaces.
+type namespacedConnector struct {
+	*connector
+}
+
+func (c *namespacedConnector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	na, ok := mg.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return nil, errors.New(errNotNamespacedAgent)
+	}
+	if f := na.ForeignReferences(); len(f) > 0 {
+		return nil, errors.Errorf(errForeignReferences, strings.Join(f, ", "), na.GetNamespace())
+	}
+	e, err := c.connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	// The Agent external client records events against the Agent it is
+	// presented with; they belong to the NamespacedAgent.
+	e.recorder = &redirectRecorder{Recorder: e.recorder, obj: na}
+	return &namespacedExternal{external: e}, nil
+}
+
+// A namespacedExternal manages the Harness agent of a NamespacedAgent by
+// presenting it to the Agent external client as an Agent, then copying any
+// changes back.
+type namespacedExternal struct {
+	external *external
+}
+
+// A redirectRecorder records all events against a single object.
+type redirectRecorder struct {
+	event.Recorder
+	obj runtime.Object
+}
+
+func (r *redirectRecorder) Event(_ runtime.Object, e event.Event) {
+	r.Recorder.Event(r.obj, e)
+}
+
+// asAgent presents the supplied NamespacedAgent as an Agent. The objects the
+// Agent references are resolved against the NamespacedAgent's namespace where
+// they name none, without changing the NamespacedAgent's own references.
+func asAgent(na *v1alpha1.NamespacedAgent) *v1alpha1.Agent {
+	a := &v1alpha1.Agent{ObjectMeta: na.ObjectMeta, Spec: *na.Spec.DeepCopy(), Status: na.Status}
+	ns := na.GetNamespace()
+	if ref := a.Spec.WriteConnectionSecretToReference; ref != nil && ref.Namespace == "" {
+		ref.Namespace = ns
+	}
+	if ref := a.Spec.ForProvider.DescriptionFrom; ref != nil && ref.Namespace == "" {
+		ref.Namespace = ns
+	}
+	if ref := a.Spec.ForProvider.InClusterDeployment; ref != nil && ref.Namespace == "" {
+		ref.Namespace = ns
+	}
+	return a
+}
+
+// fromAgent copies the changes the Agent external client made to the supplied
+// Agent back to the NamespacedAgent it presents, keeping the NamespacedAgent's
+// references as they were.
+func fromAgent(na *v1alpha1.NamespacedAgent, a *v1alpha1.Agent) {
+	spec := a.Spec
+	spec.WriteConnectionSecretToReference = na.Spec.WriteConnectionSecretToReference
+	spec.ForProvider.DescriptionFrom = na.Spec.ForProvider.DescriptionFrom
+	spec.ForProvider.InClusterDeployment = na.Spec.ForProvider.InClusterDeployment
+	na.ObjectMeta = a.ObjectMeta
+	na.Spec = spec
+	na.Status = a.Status
+}
+
+func (e *namespacedExternal) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	na, ok := mg.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotNamespacedAgent)
+	}
+	a := asAgent(na)
+	defer fromAgent(na, a)
+	return e.external.Observe(ctx, a)
+}
+
+func (e *namespacedExternal) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	na, ok := mg.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotNamespacedAgent)
+	}
+	a := asAgent(na)
+	defer fromAgent(na, a)
+	return e.external.Create(ctx, a)
+}
+
+func (e *namespacedExternal) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	na, ok := mg.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotNamespacedAgent)
+	}
+	a := asAgent(na)
+	defer fromAgent(na, a)
+	return e.external.Update(ctx, a)
+}
+
+func (e *namespacedExternal) Delete(ctx context.Context, mg resource.Managed) error {
+	na, ok := mg.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return errors.New(errNotNamespacedAgent)
+	}
+	a := asAgent(na)
+	defer fromAgent(na, a)
+	return e.external.Delete(ctx, a)
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+func TestNamespacedConnect(t *testing.T) {
+	na := &v1alpha1.NamespacedAgent{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "agent"},
+		Spec: v1alpha1.AgentSpec{ResourceSpec: xpv1.ResourceSpec{
+			ProviderConfigReference: &xpv1.Reference{Name: "shared"},
+		}},
+	}
+
+	cases := map[string]struct {
+		reason  string
+		allowed []string
+		want    error
+	}{
+		"NamespaceNotAllowed": {
+			reason:  "A ProviderConfig that does not list the namespace should be refused.",
+			allowed: []string{"team-b"},
+			want:    errors.Errorf(errNamespaceNotAllowed, "team-a", "shared"),
+		},
+		"NamespaceAllowed": {
+			reason:  "A ProviderConfig that lists the namespace should be used.",
+			allowed: []string{"team-b", "team-a"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := &namespacedConnector{connector: &connector{
+				kube: &test.MockClient{
+					MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+						pc := obj.(*apisv1alpha1.ProviderConfig)
+						pc.SetName(key.Name)
+						pc.Spec.Credentials.Source = xpv1.CredentialsSourceNone
+						pc.Spec.AllowedNamespaces = tc.allowed
+						return nil
+					},
+				},
+				usage: resource.TrackerFn(func(_ context.Context, _ resource.Managed) error { return nil }),
+				newServiceFn: func(_ *apisv1alpha1.ProviderConfig, _ []byte) (*clients.Service, error) {
+					return &clients.Service{}, nil
+				},
+			}}
+			_, err := c.Connect(context.Background(), na)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestNamespacedConnectForeignReferences(t *testing.T) {
+	na := &v1alpha1.NamespacedAgent{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "agent"},
+		Spec: v1alpha1.AgentSpec{
+			ResourceSpec: xpv1.ResourceSpec{
+				WriteConnectionSecretToReference: &xpv1.SecretReference{Namespace: "team-b", Name: "agent"},
+			},
+			ForProvider: v1alpha1.AgentParameters{
+				DescriptionFrom: &v1alpha1.ConfigMapKeySelector{Namespace: "team-a", Name: "description", Key: "text"},
+			},
+		},
+	}
+	c := &namespacedConnector{connector: &connector{}}
+	_, err := c.Connect(context.Background(), na)
+	want := errors.Errorf(errForeignReferences, "spec.writeConnectionSecretToRef", "team-a")
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("c.Connect(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestAsAgent(t *testing.T) {
+	agent := func(s v1alpha1.AgentSpec) *v1alpha1.NamespacedAgent {
+		return &v1alpha1.NamespacedAgent{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "agent"}, Spec: s}
+	}
+
+	cases := map[string]struct {
+		reason string
+		na     *v1alpha1.NamespacedAgent
+		want   v1alpha1.AgentSpec
+	}{
+		"NoReferences": {
+			reason: "A NamespacedAgent that references nothing should reference nothing as an Agent.",
+			na:     agent(v1alpha1.AgentSpec{}),
+			want:   v1alpha1.AgentSpec{},
+		},
+		"OwnNamespace": {
+			reason: "References to the NamespacedAgent's namespace should be presented as they are.",
+			na: agent(v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				DescriptionFrom: &v1alpha1.ConfigMapKeySelector{Namespace: "team-a", Name: "description", Key: "text"},
+			}}),
+			want: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				DescriptionFrom: &v1alpha1.ConfigMapKeySelector{Namespace: "team-a", Name: "description", Key: "text"},
+			}},
+		},
+		"NoNamespace": {
+			reason: "References without a namespace should refer to the NamespacedAgent's namespace.",
+			na: agent(v1alpha1.AgentSpec{
+				ResourceSpec: xpv1.ResourceSpec{
+					WriteConnectionSecretToReference: &xpv1.SecretReference{Name: "agent"},
+				},
+				ForProvider: v1alpha1.AgentParameters{
+					DescriptionFrom:     &v1alpha1.ConfigMapKeySelector{Name: "description", Key: "text"},
+					InClusterDeployment: &v1alpha1.DeploymentReference{Name: "gitops-agent"},
+				},
+			}),
+			want: v1alpha1.AgentSpec{
+				ResourceSpec: xpv1.ResourceSpec{
+					WriteConnectionSecretToReference: &xpv1.SecretReference{Namespace: "team-a", Name: "agent"},
+				},
+				ForProvider: v1alpha1.AgentParameters{
+					DescriptionFrom:     &v1alpha1.ConfigMapKeySelector{Namespace: "team-a", Name: "description", Key: "text"},
+					InClusterDeployment: &v1alpha1.DeploymentReference{Namespace: "team-a", Name: "gitops-agent"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			original := tc.na.Spec.DeepCopy()
+			a := asAgent(tc.na)
+			if diff := cmp.Diff(tc.want, a.Spec); diff != "" {
+				t.Errorf("\n%s\nasAgent(...): -want Agent spec, +got Agent spec:\n%s\n", tc.reason, diff)
+			}
+			fromAgent(tc.na, a)
+			if diff := cmp.Diff(*original, tc.na.Spec); diff != "" {
+				t.Errorf("\n%s\nfromAgent(asAgent(...)): -want NamespacedAgent spec, +got NamespacedAgent spec:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFromAgent(t *testing.T) {
+	na := &v1alpha1.NamespacedAgent{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "agent"},
+		Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+			InClusterDeployment: &v1alpha1.DeploymentReference{Name: "gitops-agent"},
+		}},
+	}
+	a := asAgent(na)
+	description := "late initialized"
+	a.Spec.ForProvider.Description = &description
+
+	fromAgent(na, a)
+	want := v1alpha1.AgentParameters{
+		Description:         &description,
+		InClusterDeployment: &v1alpha1.DeploymentReference{Name: "gitops-agent"},
+	}
+	if diff := cmp.Diff(want, na.Spec.ForProvider); diff != "" {
+		t.Errorf("fromAgent(...): -want, +got:\n%s", diff)
+	}
+}
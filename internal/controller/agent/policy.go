@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// mayUpdate returns false if the management policy of the supplied managed
+// resource forbids the provider to change its external resource. The managed
+// reconciler should not ask for such a change; checking the policy again
+// ensures an observe only agent is never changed if it does.
+func mayUpdate(mg resource.Managed) bool {
+	return mg.GetManagementPolicy() != xpv1.ManagementObserveOnly
+}
+
+// mayDelete returns false if the management policy of the supplied managed
+// resource forbids the provider to delete its external resource.
+func mayDelete(mg resource.Managed) bool {
+	switch mg.GetManagementPolicy() {
+	case xpv1.ManagementObserveOnly, xpv1.ManagementOrphanOnDelete:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func TestManagementPolicy(t *testing.T) {
+	account := "account"
+	agent := func(p xpv1.ManagementPolicy) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+		cr.SetManagementPolicy(p)
+		meta.SetExternalName(cr, "agent")
+		return cr
+	}
+	// The agent must not be changed in Harness.
+	unchanged := func(t *testing.T) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("unexpected %s request for %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		op     func(e *external, cr *v1alpha1.Agent) error
+	}{
+		"UpdateObserveOnly": {
+			reason: "Updating an observe only agent should be a no-op.",
+			cr:     agent(xpv1.ManagementObserveOnly),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				_, err := e.Update(context.Background(), cr)
+				return err
+			},
+		},
+		"DeleteObserveOnly": {
+			reason: "Deleting an observe only agent should be a no-op.",
+			cr:     agent(xpv1.ManagementObserveOnly),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				return e.Delete(context.Background(), cr)
+			},
+		},
+		"DeleteOrphanOnDelete": {
+			reason: "Deleting an agent that is orphaned on delete should be a no-op, leaving its dependents alone.",
+			cr:     agent(xpv1.ManagementOrphanOnDelete),
+			op: func(e *external, cr *v1alpha1.Agent) error {
+				e.dependentGC = true
+				return e.Delete(context.Background(), cr)
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{MockDeleteAllOf: test.NewMockDeleteAllOfFn(errors.New("unexpected delete"))}
+			e := &external{service: clientstest.NewService(t, unchanged(t)), kube: kube}
+			err := tc.op(e, tc.cr)
+			if diff := cmp.Diff(nil, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\n%s: -want error, +got error:\n%s\n", tc.reason, name, diff)
+			}
+		})
+	}
+}
+
+func TestDeleteForgetsHealth(t *testing.T) {
+	account := "account"
+
+	for _, p := range []xpv1.ManagementPolicy{xpv1.ManagementObserveOnly, xpv1.ManagementOrphanOnDelete} {
+		t.Run(string(p), func(t *testing.T) {
+			cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account}}}
+			cr.SetManagementPolicy(p)
+			meta.SetExternalName(cr, "agent")
+
+			scope := scopeOf(cr.Spec.ForProvider)
+			healthy.WithLabelValues("agent", scope).Set(1)
+			heartbeatAge.WithLabelValues("agent", scope).Set(30)
+			t.Cleanup(func() { forgetHealth("agent", scope) })
+
+			e := &external{}
+			if err := e.Delete(context.Background(), cr); err != nil {
+				t.Fatalf("e.Delete(...): %s", err)
+			}
+			if got := testutil.CollectAndCount(healthy) + testutil.CollectAndCount(heartbeatAge); got != 0 {
+				t.Errorf("e.Delete(...): want the health metrics of an agent that is left alone forgotten, got %d series", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// maxMinorVersionSkew is how many minor versions a running agent may lag or
+// lead the version Harness expects.
+const maxMinorVersionSkew = 1
+
+// serverVersion returns the agent version Harness expects, or an empty
+// string if it is unknown.
+func serverVersion(a nextgen.V1Agent) string {
+	v := a.Version
+	if v == nil || v.Major == "" {
+		return ""
+	}
+	return strings.Join([]string{v.Major, v.Minor, v.Patch}, ".")
+}
+
+// clientVersion returns the version reported by the running agent, or an
+// empty string if it is unknown.
+func clientVersion(a nextgen.V1Agent) string {
+	if a.Health == nil || a.Health.HarnessGitopsAgent == nil {
+		return ""
+	}
+	return a.Health.HarnessGitopsAgent.Version
+}
+
+// majorMinor parses the major and minor components of versions such as
+// "1.2.3", "v1.2" and "1.2.3-rc.1".
+func majorMinor(v string) (int, int, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	minor, err := strconv.Atoi(strings.SplitN(parts[1], "-", 2)[0])
+	if err != nil {
+		return 0, 0, false
+	}
+	return major, minor, true
+}
+
+// versionSkew returns the VersionSkew condition for the supplied server and
+// client versions. It returns false if either version is unknown.
+func versionSkew(server, client string) (xpv1.Condition, bool) {
+	sMajor, sMinor, ok := majorMinor(server)
+	if !ok {
+		return xpv1.Condition{}, false
+	}
+	cMajor, cMinor, ok := majorMinor(client)
+	if !ok {
+		return xpv1.Condition{}, false
+	}
+	skew := sMinor - cMinor
+	if skew < 0 {
+		skew = -skew
+	}
+	if sMajor != cMajor || skew > maxMinorVersionSkew {
+		return v1alpha1.VersionSkewed(fmt.Sprintf("agent version %s is not supported by Harness, which expects version %s", client, server)), true
+	}
+	return v1alpha1.VersionsCompatible(), true
+}
+
+// installOutdated returns the InstallOutdated condition for the supplied
+// agent.
+func installOutdated(a nextgen.V1Agent) xpv1.Condition {
+	if !a.UpgradeAvailable {
+		return v1alpha1.InstallCurrent()
+	}
+	installed := clientVersion(a)
+	if installed == "" {
+		installed = "unknown"
+	}
+	return v1alpha1.InstallOutdated(fmt.Sprintf("agent version %s is installed, but Harness offers a newer version; reinstall the agent to upgrade it", installed))
+}
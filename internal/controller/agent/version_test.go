@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestVersionSkew(t *testing.T) {
+	type args struct {
+		server string
+		client string
+	}
+	type want struct {
+		c  xpv1.Condition
+		ok bool
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Equal": {
+			reason: "Identical versions should be compatible.",
+			args:   args{server: "0.56.0", client: "v0.56.0"},
+			want:   want{c: v1alpha1.VersionsCompatible(), ok: true},
+		},
+		"WithinRange": {
+			reason: "Versions one minor version apart should be compatible.",
+			args:   args{server: "0.56.0", client: "0.55.3-rc.1"},
+			want:   want{c: v1alpha1.VersionsCompatible(), ok: true},
+		},
+		"MinorSkew": {
+			reason: "Versions more than one minor version apart should be skewed.",
+			args:   args{server: "0.56.0", client: "0.54.0"},
+			want: want{
+				c:  v1alpha1.VersionSkewed("agent version 0.54.0 is not supported by Harness, which expects version 0.56.0"),
+				ok: true,
+			},
+		},
+		"MajorSkew": {
+			reason: "Versions with different major versions should be skewed.",
+			args:   args{server: "1.0.0", client: "0.99.0"},
+			want: want{
+				c:  v1alpha1.VersionSkewed("agent version 0.99.0 is not supported by Harness, which expects version 1.0.0"),
+				ok: true,
+			},
+		},
+		"UnknownClient": {
+			reason: "Skew cannot be determined if the client version is unknown.",
+			args:   args{server: "0.56.0"},
+			want:   want{ok: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c, ok := versionSkew(tc.args.server, tc.args.client)
+			if diff := cmp.Diff(tc.want.ok, ok); diff != "" {
+				t.Errorf("\n%s\nversionSkew(...): -want ok, +got ok:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, c, cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\nversionSkew(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestInstallOutdated(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		agent  nextgen.V1Agent
+		want   xpv1.Condition
+	}{
+		"Current": {
+			reason: "An agent without an upgrade available should be current.",
+			agent:  nextgen.V1Agent{},
+			want:   v1alpha1.InstallCurrent(),
+		},
+		"Outdated": {
+			reason: "An agent with an upgrade available should be outdated, naming the installed version.",
+			agent: nextgen.V1Agent{
+				UpgradeAvailable: true,
+				Health:           &nextgen.V1AgentHealth{HarnessGitopsAgent: &nextgen.V1AgentComponentHealth{Version: "0.55.0"}},
+			},
+			want: v1alpha1.InstallOutdated("agent version 0.55.0 is installed, but Harness offers a newer version; reinstall the agent to upgrade it"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := installOutdated(tc.agent)
+			if diff := cmp.Diff(tc.want, got, cmpopts.IgnoreFields(xpv1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("\n%s\ninstallOutdated(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package agentclaim contains the controller that composes Agents from
+// AgentClaims.
+package agentclaim
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/logging"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+)
+
+const (
+	// finalizer ensures an AgentClaim's Agent is deleted before the claim.
+	finalizer = "finalizer.agentclaim.gitops.harness.crossplane.io"
+
+	timeout   = 2 * time.Minute
+	shortWait = 30 * time.Second
+
+	// maxNameLength is the maximum length of a Kubernetes object name.
+	maxNameLength = 253
+)
+
+const (
+	errGetClaim        = "cannot get AgentClaim"
+	errGetTemplate     = "cannot get AgentTemplate"
+	errGetAgent        = "cannot get composed Agent"
+	errApplyAgent      = "cannot apply composed Agent"
+	errDeleteAgent     = "cannot delete composed Agent"
+	errAddFinalizer    = "cannot add AgentClaim finalizer"
+	errRemoveFinalizer = "cannot remove AgentClaim finalizer"
+	errUpdateStatus    = "cannot update AgentClaim status"
+)
+
+// Event reasons.
+const (
+	reasonCompose event.Reason = "ComposeAgent"
+	reasonDelete  event.Reason = "DeleteAgent"
+)
+
+// Setup adds a controller that composes Agents from AgentClaims.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName("agentclaim/" + strings.ToLower(v1alpha1.AgentClaimGroupKind))
+
+	r := NewReconciler(mgr.GetClient(),
+		WithLogger(o.Logger.WithValues("controller", name)),
+		WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AgentClaim{}).
+		Watches(&source.Kind{Type: &v1alpha1.Agent{}}, handler.EnqueueRequestsFromMapFunc(claimOf)).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// claimOf returns a request for the AgentClaim the supplied Agent was
+// composed from, if any.
+func claimOf(o client.Object) []reconcile.Request {
+	l := o.GetLabels()
+	name, ns := l[v1alpha1.LabelKeyClaimName], l[v1alpha1.LabelKeyClaimNamespace]
+	if name == "" || ns == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: ns, Name: name}}}
+}
+
+// A ReconcilerOption configures a Reconciler.
+type ReconcilerOption func(*Reconciler)
+
+// WithLogger specifies how the Reconciler should log messages.
+func WithLogger(l logging.Logger) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.log = l
+	}
+}
+
+// WithRecorder specifies how the Reconciler should record events.
+func WithRecorder(er event.Recorder) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.record = er
+	}
+}
+
+// WithFinalizer specifies how the Reconciler should add and remove its
+// finalizer to and from AgentClaims.
+func WithFinalizer(f resource.Finalizer) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.finalizer = f
+	}
+}
+
+// WithApplicator specifies how the Reconciler should apply composed Agents.
+func WithApplicator(a resource.Applicator) ReconcilerOption {
+	return func(r *Reconciler) {
+		r.agent = a
+	}
+}
+
+// A Reconciler composes Agents from AgentClaims.
+type Reconciler struct {
+	client    client.Client
+	agent     resource.Applicator
+	finalizer resource.Finalizer
+
+	log    logging.Logger
+	record event.Recorder
+}
+
+// NewReconciler returns a Reconciler that composes Agents from AgentClaims.
+func NewReconciler(c client.Client, o ...ReconcilerOption) *Reconciler {
+	r := &Reconciler{
+		client:    c,
+		agent:     resource.NewAPIPatchingApplicator(c),
+		finalizer: resource.NewAPIFinalizer(c, finalizer),
+		log:       logging.NewNopLogger(),
+		record:    event.NewNopRecorder(),
+	}
+	for _, fn := range o {
+		fn(r)
+	}
+	return r
+}
+
+// Reconcile an AgentClaim by composing an Agent from it and its template.
+func (r *Reconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	log := r.log.WithValues("request", req)
+	log.Debug("Reconciling")
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cm := &v1alpha1.AgentClaim{}
+	if err := r.client.Get(ctx, req.NamespacedName, cm); err != nil {
+		// There's no need to requeue if the claim no longer exists.
+		log.Debug(errGetClaim, "error", err)
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetClaim)
+	}
+
+	if meta.WasDeleted(cm) {
+		return r.delete(ctx, cm)
+	}
+
+	if err := r.finalizer.AddFinalizer(ctx, cm); err != nil {
+		log.Debug(errAddFinalizer, "error", err)
+		cm.SetConditions(xpv1.ReconcileError(errors.Wrap(err, errAddFinalizer)))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+
+	t := &v1alpha1.AgentTemplate{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: cm.Spec.TemplateReference.Name}, t); err != nil {
+		log.Debug(errGetTemplate, "error", err)
+		err = errors.Wrap(err, errGetTemplate)
+		r.record.Event(cm, event.Warning(reasonCompose, err))
+		cm.SetConditions(xpv1.ReconcileError(err))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+
+	a := Compose(cm, t)
+	if err := r.agent.Apply(ctx, a, resource.AllowUpdateIf(composedFrom(cm))); err != nil {
+		log.Debug(errApplyAgent, "error", err)
+		err = errors.Wrap(err, errApplyAgent)
+		r.record.Event(cm, event.Warning(reasonCompose, err))
+		cm.SetConditions(xpv1.ReconcileError(err))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+
+	cm.Status.AgentReference = &xpv1.Reference{Name: a.GetName()}
+	cm.SetConditions(xpv1.ReconcileSuccess(), readiness(a))
+
+	// The claim is requeued when its Agent changes, so there's no need to
+	// poll it.
+	return reconcile.Result{}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+}
+
+func (r *Reconciler) delete(ctx context.Context, cm *v1alpha1.AgentClaim) (reconcile.Result, error) {
+	log := r.log.WithValues("claim", cm.GetName(), "namespace", cm.GetNamespace())
+
+	a := &v1alpha1.Agent{}
+	err := r.client.Get(ctx, types.NamespacedName{Name: AgentName(cm)}, a)
+	if resource.IgnoreNotFound(err) != nil {
+		log.Debug(errGetAgent, "error", err)
+		cm.SetConditions(xpv1.ReconcileError(errors.Wrap(err, errGetAgent)))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+
+	// Wait for the Agent to be deleted, so that the claim is only gone once
+	// its Harness agent is. An Agent the claim did not compose is left alone.
+	if err == nil && composedFrom(cm)(a, nil) {
+		if !meta.WasDeleted(a) {
+			if err := r.client.Delete(ctx, a); resource.IgnoreNotFound(err) != nil {
+				log.Debug(errDeleteAgent, "error", err)
+				err = errors.Wrap(err, errDeleteAgent)
+				r.record.Event(cm, event.Warning(reasonDelete, err))
+				cm.SetConditions(xpv1.ReconcileError(err))
+				return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+			}
+			r.record.Event(cm, event.Normal(reasonDelete, "Requested deletion of composed Agent"))
+		}
+		cm.SetConditions(xpv1.Deleting(), xpv1.ReconcileSuccess())
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+
+	if err := r.finalizer.RemoveFinalizer(ctx, cm); err != nil {
+		log.Debug(errRemoveFinalizer, "error", err)
+		cm.SetConditions(xpv1.ReconcileError(errors.Wrap(err, errRemoveFinalizer)))
+		return reconcile.Result{RequeueAfter: shortWait}, errors.Wrap(r.client.Status().Update(ctx, cm), errUpdateStatus)
+	}
+	return reconcile.Result{}, nil
+}
+
+// AgentName returns the name of the Agent composed from the supplied claim.
+// Agents are cluster scoped, so the name is made unique by the claim's UID.
+func AgentName(cm *v1alpha1.AgentClaim) string {
+	uid := string(cm.GetUID())
+	if len(uid) > 8 {
+		uid = uid[:8]
+	}
+	name := cm.GetName()
+	if limit := maxNameLength - len(uid) - 1; len(name) > limit {
+		name = name[:limit]
+	}
+	return name + "-" + uid
+}
+
+// Compose returns the Agent composed from the supplied claim and template.
+func Compose(cm *v1alpha1.AgentClaim, t *v1alpha1.AgentTemplate) *v1alpha1.Agent {
+	account := t.Spec.AccountIdentifier
+	tags := clients.MergeTags(t.Spec.Tags, cm.Spec.Tags)
+
+	a := &v1alpha1.Agent{}
+	a.SetName(AgentName(cm))
+	a.SetLabels(map[string]string{
+		v1alpha1.LabelKeyClaimName:      cm.GetName(),
+		v1alpha1.LabelKeyClaimNamespace: cm.GetNamespace(),
+	})
+	a.Spec.ProviderConfigReference = t.Spec.ProviderConfigReference
+	a.Spec.ForProvider = v1alpha1.AgentParameters{
+		AccountIdentifier: &account,
+		OrgIdentifier:     t.Spec.OrgIdentifier,
+		ProjectIdentifier: t.Spec.ProjectIdentifier,
+		Identifier:        cm.Spec.Identifier,
+		Name:              cm.Spec.Name,
+		Description:       cm.Spec.Description,
+	}
+	if len(tags) > 0 {
+		a.Spec.ForProvider.Tags = &tags
+	}
+	return a
+}
+
+// composedFrom returns a function that returns true if the current Agent was
+// composed from the supplied claim.
+func composedFrom(cm *v1alpha1.AgentClaim) func(current, _ runtime.Object) bool {
+	return func(current, _ runtime.Object) bool {
+		a, ok := current.(*v1alpha1.Agent)
+		if !ok {
+			return false
+		}
+		l := a.GetLabels()
+		return l[v1alpha1.LabelKeyClaimName] == cm.GetName() && l[v1alpha1.LabelKeyClaimNamespace] == cm.GetNamespace()
+	}
+}
+
+// readiness returns the claim's Ready condition, which mirrors the Agent's.
+func readiness(a *v1alpha1.Agent) xpv1.Condition {
+	c := a.GetCondition(xpv1.TypeReady)
+	if c.Reason == "" {
+		return xpv1.Creating()
+	}
+	return c
+}
@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package agentclaim
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestCompose(t *testing.T) {
+	org, name := "platform", "checkout"
+	cm := &v1alpha1.AgentClaim{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "checkout", UID: types.UID("0123456789abcdef")},
+		Spec: v1alpha1.AgentClaimSpec{
+			TemplateReference: xpv1.Reference{Name: "platform"},
+			Name:              &name,
+			Tags:              map[string]string{"team": "payments"},
+		},
+	}
+	tmpl := &v1alpha1.AgentTemplate{Spec: v1alpha1.AgentTemplateSpec{
+		ProviderConfigReference: &xpv1.Reference{Name: "harness"},
+		AccountIdentifier:       "account",
+		OrgIdentifier:           &org,
+		Tags:                    map[string]string{"team": "platform", "managed-by": "crossplane"},
+	}}
+
+	account := "account"
+	tags := map[string]string{"team": "payments", "managed-by": "crossplane"}
+	want := &v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "checkout-01234567",
+			Labels: map[string]string{
+				v1alpha1.LabelKeyClaimName:      "checkout",
+				v1alpha1.LabelKeyClaimNamespace: "payments",
+			},
+		},
+		Spec: v1alpha1.AgentSpec{
+			ResourceSpec: xpv1.ResourceSpec{ProviderConfigReference: &xpv1.Reference{Name: "harness"}},
+			ForProvider: v1alpha1.AgentParameters{
+				AccountIdentifier: &account,
+				OrgIdentifier:     &org,
+				Name:              &name,
+				Tags:              &tags,
+			},
+		},
+	}
+
+	got := Compose(cm, tmpl)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("Compose(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := metav1.Now()
+	uid := types.UID("0123456789abcdef")
+
+	claim := func(deleted bool) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *v1alpha1.AgentClaim:
+				o.SetName("checkout")
+				o.SetNamespace("payments")
+				o.SetUID(uid)
+				if deleted {
+					o.SetDeletionTimestamp(&now)
+				}
+			case *v1alpha1.AgentTemplate:
+				o.Spec.AccountIdentifier = "account"
+			}
+			return nil
+		}
+	}
+	composed := func(obj client.Object) {
+		obj.SetLabels(map[string]string{
+			v1alpha1.LabelKeyClaimName:      "checkout",
+			v1alpha1.LabelKeyClaimNamespace: "payments",
+		})
+	}
+
+	type args struct {
+		kube client.Client
+		opts []ReconcilerOption
+	}
+	type want struct {
+		r   reconcile.Result
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"ClaimNotFound": {
+			reason: "We should not return an error if the claim no longer exists.",
+			args: args{
+				kube: &test.MockClient{MockGet: test.NewMockGetFn(errNotFound())},
+			},
+			want: want{r: reconcile.Result{}},
+		},
+		"GetTemplateError": {
+			reason: "We should requeue and report the error if we cannot get the template.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+						if _, ok := obj.(*v1alpha1.AgentTemplate); ok {
+							return errBoom
+						}
+						return claim(false)(ctx, key, obj)
+					},
+					MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+						want := xpv1.ReconcileError(errors.Wrap(errBoom, errGetTemplate))
+						if diff := cmp.Diff(want, obj.(*v1alpha1.AgentClaim).GetCondition(xpv1.TypeSynced), test.EquateConditions()); diff != "" {
+							t.Errorf("Status().Update(...): -want, +got:\n%s", diff)
+						}
+						return nil
+					},
+				},
+				opts: []ReconcilerOption{WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }})},
+			},
+			want: want{r: reconcile.Result{RequeueAfter: shortWait}},
+		},
+		"Composed": {
+			reason: "The claim should mirror the readiness of the Agent composed from it.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: claim(false),
+					MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+						cm := obj.(*v1alpha1.AgentClaim)
+						if diff := cmp.Diff(&xpv1.Reference{Name: "checkout-01234567"}, cm.Status.AgentReference); diff != "" {
+							t.Errorf("Status().Update(...): -want agentRef, +got agentRef:\n%s", diff)
+						}
+						if diff := cmp.Diff(xpv1.Available(), cm.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+							t.Errorf("Status().Update(...): -want ready, +got ready:\n%s", diff)
+						}
+						return nil
+					},
+				},
+				opts: []ReconcilerOption{
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }}),
+					WithApplicator(resource.ApplyFn(func(_ context.Context, obj client.Object, _ ...resource.ApplyOption) error {
+						obj.(*v1alpha1.Agent).SetConditions(xpv1.Available())
+						return nil
+					})),
+				},
+			},
+			want: want{r: reconcile.Result{}},
+		},
+		"ApplyError": {
+			reason: "We should requeue if we cannot apply the composed Agent.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet:          claim(false),
+					MockStatusUpdate: test.NewMockSubResourceUpdateFn(nil),
+				},
+				opts: []ReconcilerOption{
+					WithFinalizer(resource.FinalizerFns{AddFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }}),
+					WithApplicator(resource.ApplyFn(func(_ context.Context, _ client.Object, _ ...resource.ApplyOption) error {
+						return errBoom
+					})),
+				},
+			},
+			want: want{r: reconcile.Result{RequeueAfter: shortWait}},
+		},
+		"DeleteAgent": {
+			reason: "Deleting a claim should delete its Agent and wait for it to be gone.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+						if _, ok := obj.(*v1alpha1.Agent); ok {
+							composed(obj)
+							return nil
+						}
+						return claim(true)(ctx, key, obj)
+					},
+					MockDelete:       test.NewMockDeleteFn(nil),
+					MockStatusUpdate: test.NewMockSubResourceUpdateFn(nil),
+				},
+			},
+			want: want{r: reconcile.Result{RequeueAfter: shortWait}},
+		},
+		"AgentDeleted": {
+			reason: "The finalizer should be removed once the Agent is gone.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+						if _, ok := obj.(*v1alpha1.Agent); ok {
+							return errNotFound()
+						}
+						return claim(true)(ctx, key, obj)
+					},
+				},
+				opts: []ReconcilerOption{WithFinalizer(resource.FinalizerFns{RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }})},
+			},
+			want: want{r: reconcile.Result{}},
+		},
+		"ForeignAgent": {
+			reason: "An Agent the claim did not compose should not be deleted.",
+			args: args{
+				kube: &test.MockClient{
+					MockGet: claim(true),
+					MockDelete: func(_ context.Context, _ client.Object, _ ...client.DeleteOption) error {
+						t.Error("unexpected delete of an Agent the claim did not compose")
+						return nil
+					},
+				},
+				opts: []ReconcilerOption{WithFinalizer(resource.FinalizerFns{RemoveFinalizerFn: func(_ context.Context, _ resource.Object) error { return nil }})},
+			},
+			want: want{r: reconcile.Result{}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			r := NewReconciler(tc.args.kube, tc.args.opts...)
+			got, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "payments", Name: "checkout"}})
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.r, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func errNotFound() error {
+	return kerrors.NewNotFound(schema.GroupResource{}, "")
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package application contains the controller of GitOps Application managed
+// resources.
+package application
+
+import (
+	"context"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/dependents"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotApplication = "managed resource is not an Application custom resource"
+
+	errGetApplication    = "cannot get application"
+	errCreateApplication = "cannot create application"
+	errUpdateApplication = "cannot update application"
+	errSyncApplication   = "cannot sync application"
+	errDeleteApplication = "cannot delete application"
+)
+
+// syncStatusSynced is the sync status Harness reports for an application
+// whose deployed resources match its source.
+const syncStatusSynced = "Synced"
+
+// Phases of an application operation that has not yet completed.
+const (
+	operationPhaseRunning     = "Running"
+	operationPhaseTerminating = "Terminating"
+)
+
+// Setup adds a controller that reconciles Application managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.ApplicationGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.ApplicationGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.ApplicationKind)),
+		o.ManagementPolicies(),
+		dependents.WithOwnerReferences(mgr.GetClient(), o.Features, v1alpha1.ApplicationGroupVersionKind, agentOf),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.Application{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// agentOf returns the account and identifier of the agent that deploys the Application.
+func agentOf(mg resource.Managed) (account, identifier string) {
+	cr, ok := mg.(*v1alpha1.Application)
+	if !ok {
+		return "", ""
+	}
+	return cr.Spec.ForProvider.AccountIdentifier, cr.Spec.ForProvider.AgentIdentifier
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the Application's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Application); !ok {
+		return nil, errors.New(errNotApplication)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc}, nil
+}
+
+// An external observes, then either creates, updates and syncs, or deletes
+// an application to ensure it reflects the Application's desired state.
+type external struct {
+	service *clients.Service
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Application)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotApplication)
+	}
+	p := cr.Spec.ForProvider
+
+	rsp, response, err := c.service.ApplicationsApiService.AgentApplicationServiceGet(c.service.Authorize(ctx), p.AgentIdentifier, p.Name,
+		p.AccountIdentifier, p.OrgIdentifier, p.ProjectIdentifier, nil)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetApplication)
+	}
+	if rsp.App == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	observe(cr, *rsp.App)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(p, *rsp.App),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Application)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotApplication)
+	}
+	p := cr.Spec.ForProvider
+
+	app := application(p)
+	_, response, err := c.service.ApplicationsApiService.AgentApplicationServiceCreate(c.service.Authorize(ctx),
+		nextgen.ApplicationsApplicationCreateRequest{Application: &app}, p.AgentIdentifier,
+		&nextgen.ApplicationsApiAgentApplicationServiceCreateOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     optional.NewString(p.OrgIdentifier),
+			ProjectIdentifier: optional.NewString(p.ProjectIdentifier),
+			ClusterIdentifier: clients.OptionalString(p.ClusterIdentifier),
+			RepoIdentifier:    clients.OptionalString(p.RepoIdentifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateApplication)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+// Update updates the application's spec, then syncs it so that its deployed
+// resources match its source.
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Application)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotApplication)
+	}
+	p := cr.Spec.ForProvider
+	ctx = c.service.Authorize(ctx)
+
+	app := application(p)
+	_, response, err := c.service.ApplicationsApiService.AgentApplicationServiceUpdate(ctx,
+		nextgen.ApplicationsApplicationUpdateRequest{Application: &app},
+		p.AccountIdentifier, p.OrgIdentifier, p.ProjectIdentifier, p.AgentIdentifier, p.Name,
+		&nextgen.ApplicationsApiAgentApplicationServiceUpdateOpts{
+			ClusterIdentifier: clients.OptionalString(p.ClusterIdentifier),
+			RepoIdentifier:    clients.OptionalString(p.RepoIdentifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateApplication)
+	}
+
+	_, response, err = c.service.ApplicationsApiService.AgentApplicationServiceSync(ctx,
+		nextgen.ApplicationsApplicationSyncRequest{Name: p.Name, Revision: clients.StringValue(p.Source.TargetRevision)},
+		p.AccountIdentifier, p.OrgIdentifier, p.ProjectIdentifier, p.AgentIdentifier, p.Name)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return managed.ExternalUpdate{}, errors.Wrap(err, errSyncApplication)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Application)
+	if !ok {
+		return errors.New(errNotApplication)
+	}
+	p := cr.Spec.ForProvider
+	cr.SetConditions(xpv1.Deleting())
+
+	_, response, err := c.service.ApplicationsApiService.AgentApplicationServiceDelete(c.service.Authorize(ctx), p.AgentIdentifier, p.Name,
+		&nextgen.ApplicationsApiAgentApplicationServiceDeleteOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     optional.NewString(p.OrgIdentifier),
+			ProjectIdentifier: optional.NewString(p.ProjectIdentifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteApplication)
+}
+
+// application returns the Harness representation of an application with the
+// supplied parameters.
+func application(p v1alpha1.ApplicationParameters) nextgen.ApplicationsApplication {
+	return nextgen.ApplicationsApplication{
+		Metadata: &nextgen.V1ObjectMeta{Name: p.Name},
+		Spec: &nextgen.ApplicationsApplicationSpec{
+			Source: &nextgen.ApplicationsApplicationSource{
+				RepoURL:        p.Source.RepoURL,
+				Path:           clients.StringValue(p.Source.Path),
+				TargetRevision: clients.StringValue(p.Source.TargetRevision),
+			},
+			Destination: &nextgen.ApplicationsApplicationDestination{
+				Server:    p.Destination.Server,
+				Namespace: clients.StringValue(p.Destination.Namespace),
+			},
+			Project: clients.StringValue(p.Project),
+		},
+	}
+}
+
+// observe records the sync and health status of the supplied application.
+func observe(cr *v1alpha1.Application, app nextgen.ApplicationsApplication) {
+	o := &cr.Status.AtProvider
+	o.SyncStatus, o.Revision, o.HealthStatus, o.HealthMessage = "", "", "", ""
+	if app.Status == nil {
+		return
+	}
+	if s := app.Status.Sync; s != nil {
+		o.SyncStatus, o.Revision = s.Status, s.Revision
+	}
+	if h := app.Status.Health; h != nil {
+		o.HealthStatus, o.HealthMessage = h.Status, h.Message
+	}
+}
+
+// upToDate returns true if the observed application matches the supplied
+// parameters and its deployed resources are synced with its source. An
+// application whose spec matches is also up to date while an operation, e.g.
+// a sync, is in progress, so that it is not synced again until the operation
+// completes.
+func upToDate(p v1alpha1.ApplicationParameters, observed nextgen.ApplicationsApplication) bool {
+	if !specUpToDate(p, observed) {
+		return false
+	}
+	st := observed.Status
+	if st == nil {
+		return false
+	}
+	if operationInProgress(st.OperationState) {
+		return true
+	}
+	return st.Sync != nil && st.Sync.Status == syncStatusSynced
+}
+
+// specUpToDate returns true if the spec of the observed application matches
+// the supplied parameters.
+func specUpToDate(p v1alpha1.ApplicationParameters, observed nextgen.ApplicationsApplication) bool {
+	if observed.Spec == nil || observed.Spec.Source == nil || observed.Spec.Destination == nil {
+		return false
+	}
+	src, dst := observed.Spec.Source, observed.Spec.Destination
+	switch {
+	case p.Source.RepoURL != src.RepoURL:
+		return false
+	case p.Source.Path != nil && *p.Source.Path != src.Path:
+		return false
+	case p.Source.TargetRevision != nil && *p.Source.TargetRevision != src.TargetRevision:
+		return false
+	case p.Destination.Server != dst.Server:
+		return false
+	case p.Destination.Namespace != nil && *p.Destination.Namespace != dst.Namespace:
+		return false
+	case p.Project != nil && *p.Project != observed.Spec.Project:
+		return false
+	}
+	return true
+}
+
+// operationInProgress returns true if the supplied operation has not yet
+// completed.
+func operationInProgress(op *nextgen.ApplicationsOperationState) bool {
+	return op != nil && (op.Phase == operationPhaseRunning || op.Phase == operationPhaseTerminating)
+}
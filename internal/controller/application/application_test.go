@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package application
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const (
+	repoURL = "https://github.com/argoproj/argocd-example-apps.git"
+	server  = "https://kubernetes.default.svc"
+)
+
+func guestbook() *v1alpha1.Application {
+	path := "guestbook"
+	return &v1alpha1.Application{Spec: v1alpha1.ApplicationSpec{ForProvider: v1alpha1.ApplicationParameters{
+		AccountIdentifier: "account",
+		OrgIdentifier:     "default",
+		ProjectIdentifier: "gitops",
+		AgentIdentifier:   "agent",
+		Name:              "guestbook",
+		Source:            v1alpha1.ApplicationSource{RepoURL: repoURL, Path: &path},
+		Destination:       v1alpha1.ApplicationDestination{Server: server},
+	}}}
+}
+
+// observed returns an application response with the supplied path, sync
+// status, and operation phase. No operation is reported if phase is empty.
+func observed(path, sync, phase string) string {
+	op := ""
+	if phase != "" {
+		op = `"operationState":{"phase":"` + phase + `"},`
+	}
+	return `{"name":"guestbook","app":{"spec":{"source":{"repoURL":"` + repoURL + `","path":"` + path + `"},` +
+		`"destination":{"server":"` + server + `"}},"status":{` + op + `"sync":{"status":"` + sync + `","revision":"abc123"},` +
+		`"health":{"status":"Healthy"}}}}`
+}
+
+func TestObserve(t *testing.T) {
+	type want struct {
+		o   managed.ExternalObservation
+		obs v1alpha1.ApplicationObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"NotFound": {
+			reason:  "An application Harness does not know should be reported as absent.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+			want:    want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"GetError": {
+			reason:  "Errors other than not found should be returned rather than reported as absent.",
+			handler: clientstest.Respond(http.StatusInternalServerError, ""),
+			want:    want{err: errors.Wrap(errors.New("500 Internal Server Error"), errGetApplication)},
+		},
+		"Synced": {
+			reason:  "A synced application matching its spec should be up to date and record its status.",
+			handler: clientstest.Respond(http.StatusOK, observed("guestbook", "Synced", "Succeeded")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "Synced", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+		"OutOfSync": {
+			reason:  "An application whose deployed resources drifted should not be up to date, so that it is synced.",
+			handler: clientstest.Respond(http.StatusOK, observed("guestbook", "OutOfSync", "")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "OutOfSync", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+		"SpecChanged": {
+			reason:  "An application with a different source should not be up to date.",
+			handler: clientstest.Respond(http.StatusOK, observed("helm-guestbook", "Synced", "")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "Synced", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+		"MidSync": {
+			reason:  "An application that is being synced should be up to date, so that it is not synced again every poll.",
+			handler: clientstest.Respond(http.StatusOK, observed("guestbook", "OutOfSync", "Running")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "OutOfSync", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+		"SyncFailed": {
+			reason:  "An application whose last sync completed without syncing it should not be up to date, so that it is synced again.",
+			handler: clientstest.Respond(http.StatusOK, observed("guestbook", "OutOfSync", "Failed")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "OutOfSync", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+		"SpecChangedMidSync": {
+			reason:  "An application with a different source should not be up to date even while it is being synced.",
+			handler: clientstest.Respond(http.StatusOK, observed("helm-guestbook", "OutOfSync", "Running")),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.ApplicationObservation{SyncStatus: "OutOfSync", Revision: "abc123", HealthStatus: "Healthy"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := guestbook()
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	type want struct {
+		calls []string
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason string
+		sync   int
+		want   want
+	}{
+		"Synced": {
+			reason: "Updating an application should update its spec, then sync it.",
+			sync:   http.StatusOK,
+			want:   want{calls: []string{"PUT /gitops/api/v1/agents/agent/applications/guestbook", "POST /gitops/api/v1/agents/agent/applications/guestbook/sync"}},
+		},
+		"SyncError": {
+			reason: "Errors syncing the application should be returned.",
+			sync:   http.StatusBadRequest,
+			want: want{
+				calls: []string{"PUT /gitops/api/v1/agents/agent/applications/guestbook", "POST /gitops/api/v1/agents/agent/applications/guestbook/sync"},
+				err:   errors.Wrap(errors.New("400 Bad Request"), errSyncApplication),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var calls []string
+			var sync nextgen.ApplicationsApplicationSyncRequest
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, r.Method+" "+r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				if strings.HasSuffix(r.URL.Path, "/sync") {
+					_ = json.NewDecoder(r.Body).Decode(&sync)
+					w.WriteHeader(tc.sync)
+				}
+				_, _ = w.Write([]byte(`{}`))
+			}
+
+			e := external{service: clientstest.NewService(t, handler)}
+			_, err := e.Update(context.Background(), guestbook())
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.calls, calls); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want calls, +got calls:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff("guestbook", sync.Name); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want synced application, +got synced application:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "A deleted application should be reported as deleted.",
+			handler: clientstest.Respond(http.StatusOK, `{}`),
+		},
+		"AlreadyDeleted": {
+			reason:  "An application Harness no longer knows should be treated as deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"Error": {
+			reason:  "Other errors deleting the application should be returned.",
+			handler: clientstest.Respond(http.StatusBadRequest, ""),
+			want:    errors.Wrap(errors.New("400 Bad Request"), errDeleteApplication),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), guestbook())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cluster contains the controller of GitOps Cluster managed
+// resources.
+package cluster
+
+import (
+	"context"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/dependents"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotCluster = "managed resource is not a Cluster custom resource"
+
+	errGetCluster    = "cannot get cluster"
+	errCreateCluster = "cannot create cluster"
+	errUpdateCluster = "cannot update cluster"
+	errDeleteCluster = "cannot delete cluster"
+)
+
+// Setup adds a controller that reconciles Cluster managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.ClusterGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.ClusterGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.ClusterKind)),
+		o.ManagementPolicies(),
+		dependents.WithOwnerReferences(mgr.GetClient(), o.Features, v1alpha1.ClusterGroupVersionKind, agentOf),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.Cluster{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// agentOf returns the account and identifier of the agent that the Cluster is added to.
+func agentOf(mg resource.Managed) (account, identifier string) {
+	cr, ok := mg.(*v1alpha1.Cluster)
+	if !ok {
+		return "", ""
+	}
+	return cr.Spec.ForProvider.AccountIdentifier, cr.Spec.ForProvider.AgentIdentifier
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the Cluster's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Cluster); !ok {
+		return nil, errors.New(errNotCluster)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc, kube: c.harness.Kube}, nil
+}
+
+// An external observes, then either registers, updates, or deregisters a
+// cluster to ensure it reflects the Cluster's desired state.
+type external struct {
+	service *clients.Service
+	kube    client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Cluster)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCluster)
+	}
+	p := cr.Spec.ForProvider
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.ClustersApi.AgentClusterServiceGet(c.service.Authorize(ctx), p.AgentIdentifier, p.Identifier, p.AccountIdentifier,
+		&nextgen.ClustersApiAgentClusterServiceGetOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCluster)
+	}
+	if rsp.Cluster == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	o := &cr.Status.AtProvider
+	o.ConnectionStatus, o.ConnectionMessage = "", ""
+	if s := rsp.Cluster.ConnectionState; s != nil {
+		o.ConnectionStatus, o.ConnectionMessage = s.Status, s.Message
+	}
+	o.ServerVersion = rsp.Cluster.ServerVersion
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(p, *rsp.Cluster),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Cluster)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCluster)
+	}
+	p := cr.Spec.ForProvider
+
+	cluster, err := c.cluster(ctx, p)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.ClustersApi.AgentClusterServiceCreate(c.service.Authorize(ctx),
+		nextgen.ClustersClusterCreateRequest{Cluster: &cluster}, p.AgentIdentifier,
+		&nextgen.ClustersApiAgentClusterServiceCreateOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+			Identifier:        optional.NewString(p.Identifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCluster)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Cluster)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCluster)
+	}
+	p := cr.Spec.ForProvider
+
+	cluster, err := c.cluster(ctx, p)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.ClustersApi.AgentClusterServiceUpdate(c.service.Authorize(ctx),
+		nextgen.ClustersClusterUpdateRequest{Cluster: &cluster}, p.AgentIdentifier, p.Identifier,
+		&nextgen.ClustersApiAgentClusterServiceUpdateOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCluster)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Cluster)
+	if !ok {
+		return errors.New(errNotCluster)
+	}
+	p := cr.Spec.ForProvider
+	cr.SetConditions(xpv1.Deleting())
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.ClustersApi.AgentClusterServiceDelete(c.service.Authorize(ctx), p.AgentIdentifier, p.Identifier,
+		&nextgen.ClustersApiAgentClusterServiceDeleteOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteCluster)
+}
+
+// cluster returns the Harness representation of a cluster with the supplied
+// parameters, including its credentials.
+func (c *external) cluster(ctx context.Context, p v1alpha1.ClusterParameters) (nextgen.ClustersCluster, error) {
+	token, err := clients.SecretValue(ctx, c.kube, p.BearerTokenSecretRef)
+	if err != nil {
+		return nextgen.ClustersCluster{}, err
+	}
+	return nextgen.ClustersCluster{
+		Server:     p.Server,
+		Name:       clients.StringValue(p.Name),
+		Namespaces: p.Namespaces,
+		Config: &nextgen.ClustersClusterConfig{
+			ClusterConnectionType: clients.StringValue(p.ConnectionType),
+			BearerToken:           token,
+		},
+	}, nil
+}
+
+// upToDate returns true if the observed cluster matches the supplied
+// parameters. Credentials are not compared, since Harness does not return
+// them.
+func upToDate(p v1alpha1.ClusterParameters, observed nextgen.ClustersCluster) bool {
+	switch {
+	case p.Server != observed.Server:
+		return false
+	case p.Name != nil && *p.Name != observed.Name:
+		return false
+	case !clients.SameStrings(p.Namespaces, observed.Namespaces):
+		return false
+	}
+	return true
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const server = "https://kubernetes.default.svc"
+
+func cluster(namespaces ...string) *v1alpha1.Cluster {
+	return &v1alpha1.Cluster{Spec: v1alpha1.ClusterSpec{ForProvider: v1alpha1.ClusterParameters{
+		AccountIdentifier: "account",
+		AgentIdentifier:   "agent",
+		Identifier:        "incluster",
+		Server:            server,
+		Namespaces:        namespaces,
+	}}}
+}
+
+func TestObserve(t *testing.T) {
+	type want struct {
+		o   managed.ExternalObservation
+		c   xpv1.Condition
+		obs v1alpha1.ClusterObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		cr      *v1alpha1.Cluster
+		want    want
+	}{
+		"NotFound": {
+			reason:  "A cluster Harness does not know should be reported as absent.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+			cr:      cluster(),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+				c: xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown},
+			},
+		},
+		"GetError": {
+			reason:  "Errors other than not found should be returned rather than reported as absent.",
+			handler: clientstest.Respond(http.StatusInternalServerError, ""),
+			cr:      cluster(),
+			want: want{
+				c:   xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown},
+				err: errors.Wrap(errors.New("500 Internal Server Error"), errGetCluster),
+			},
+		},
+		"UpToDate": {
+			reason: "A registered cluster with the desired namespaces, in any order, should be up to date and record its connection state.",
+			handler: clientstest.Respond(http.StatusOK, `{"identifier":"incluster","cluster":{"server":"`+server+`","namespaces":["b","a"],`+
+				`"serverVersion":"1.29","connectionState":{"status":"Successful","message":"cluster is reachable"}}}`),
+			cr: cluster("a", "b"),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c:   xpv1.Available(),
+				obs: v1alpha1.ClusterObservation{ConnectionStatus: "Successful", ConnectionMessage: "cluster is reachable", ServerVersion: "1.29"},
+			},
+		},
+		"OutOfDate": {
+			reason:  "A registered cluster with different namespaces should not be up to date.",
+			handler: clientstest.Respond(http.StatusOK, `{"identifier":"incluster","cluster":{"server":"`+server+`","namespaces":["a"]}}`),
+			cr:      cluster("a", "b"),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				c: xpv1.Available(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, tc.cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var created nextgen.ClustersClusterCreateRequest
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"identifier":"incluster"}`))
+	}
+
+	cr := cluster("guestbook")
+	sa := "SERVICE_ACCOUNT"
+	cr.Spec.ForProvider.ConnectionType = &sa
+	cr.Spec.ForProvider.BearerTokenSecretRef = &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "cluster"},
+		Key:             "token",
+	}
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Secret).Data = map[string][]byte{"token": []byte("secret")}
+		return nil
+	}}
+
+	e := external{service: clientstest.NewService(t, handler), kube: kube}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %s", err)
+	}
+	want := nextgen.ClustersClusterCreateRequest{Cluster: &nextgen.ClustersCluster{
+		Server:     server,
+		Namespaces: []string{"guestbook"},
+		Config:     &nextgen.ClustersClusterConfig{ClusterConnectionType: sa, BearerToken: "secret"},
+	}}
+	if diff := cmp.Diff(want, created); diff != "" {
+		t.Errorf("e.Create(...): -want request, +got request:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "A deregistered cluster should be reported as deleted.",
+			handler: clientstest.Respond(http.StatusOK, `{}`),
+		},
+		"AlreadyDeleted": {
+			reason:  "A cluster Harness no longer knows should be treated as deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"Error": {
+			reason:  "Other errors deregistering the cluster should be returned.",
+			handler: clientstest.Respond(http.StatusBadRequest, ""),
+			want:    errors.Wrap(errors.New("400 Bad Request"), errDeleteCluster),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), cluster())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,415 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package connector contains the controller of platform Connector managed
+// resources.
+package connector
+
+import (
+	"context"
+
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotConnector = "managed resource is not a Connector custom resource"
+
+	errGetConnector    = "cannot get connector"
+	errCreateConnector = "cannot create connector"
+	errUpdateConnector = "cannot update connector"
+	errDeleteConnector = "cannot delete connector"
+	errInvalidTags     = "invalid tags"
+
+	errNoURL           = "url is required for %s connectors"
+	errUnsupportedAuth = "%s connectors do not support %s authentication"
+	errMissingAuth     = "%s authentication requires %s"
+)
+
+// connectivitySuccess is the connectivity status Harness reports for a
+// connector it connected through.
+const connectivitySuccess = "SUCCESS"
+
+// Setup adds a controller that reconciles Connector managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.ConnectorGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.ConnectorGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.ConnectorKind)),
+		o.ManagementPolicies(),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.Connector{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the Connector's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Connector); !ok {
+		return nil, errors.New(errNotConnector)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a Harness
+// connector to ensure it reflects the Connector's desired state.
+type external struct {
+	service *clients.Service
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Connector)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotConnector)
+	}
+	p := cr.Spec.ForProvider
+
+	desired, err := connectorInfo(p, c.service.DefaultTags)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.ConnectorsApi.GetConnector(c.service.Authorize(ctx), p.AccountIdentifier, p.Identifier,
+		&nextgen.ConnectorsApiGetConnectorOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetConnector)
+	}
+	if rsp.Data == nil || rsp.Data.Connector == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	observe(cr, rsp.Data)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(desired, *rsp.Data.Connector),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Connector)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotConnector)
+	}
+
+	info, err := connectorInfo(cr.Spec.ForProvider, c.service.DefaultTags)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if err := clients.ValidateTags(info.Tags); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidTags)
+	}
+
+	_, response, err := c.service.ConnectorsApi.CreateConnector(c.service.Authorize(ctx), nextgen.Connector{Connector: &info}, cr.Spec.ForProvider.AccountIdentifier, nil)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateConnector)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Connector)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotConnector)
+	}
+
+	info, err := connectorInfo(cr.Spec.ForProvider, c.service.DefaultTags)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := clients.ValidateTags(info.Tags); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvalidTags)
+	}
+
+	_, response, err := c.service.ConnectorsApi.UpdateConnector(c.service.Authorize(ctx), nextgen.Connector{Connector: &info}, cr.Spec.ForProvider.AccountIdentifier, nil)
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateConnector)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Connector)
+	if !ok {
+		return errors.New(errNotConnector)
+	}
+	cr.SetConditions(xpv1.Deleting())
+	p := cr.Spec.ForProvider
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.ConnectorsApi.DeleteConnector(c.service.Authorize(ctx), p.AccountIdentifier, p.Identifier,
+		&nextgen.ConnectorsApiDeleteConnectorOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	// A connector that is already gone has been deleted.
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteConnector)
+}
+
+// observe records the observed state of the supplied connector.
+func observe(cr *v1alpha1.Connector, rsp *nextgen.ConnectorResponse) {
+	cr.Status.AtProvider.CreatedAt = clients.TimeFromMillis(rsp.CreatedAt)
+	cr.Status.AtProvider.LastModifiedAt = clients.TimeFromMillis(rsp.LastModifiedAt)
+	cr.Status.AtProvider.ConnectivityStatus = ""
+	cr.Status.AtProvider.ConnectivityError = ""
+	if s := rsp.Status; s != nil {
+		cr.Status.AtProvider.ConnectivityStatus = s.Status
+		if s.Status != connectivitySuccess {
+			cr.Status.AtProvider.ConnectivityError = s.ErrorSummary
+		}
+	}
+}
+
+// connectorInfo returns the connector described by the supplied parameters,
+// tagged with the supplied default tags beneath its own. It returns an error if the parameters do not describe a valid connector of
+// their type.
+func connectorInfo(p v1alpha1.ConnectorParameters, defaultTags map[string]string) (nextgen.ConnectorInfo, error) {
+	info := nextgen.ConnectorInfo{
+		Identifier:        p.Identifier,
+		Name:              p.Identifier,
+		Description:       clients.StringValue(p.Description),
+		OrgIdentifier:     clients.StringValue(p.OrgIdentifier),
+		ProjectIdentifier: clients.StringValue(p.ProjectIdentifier),
+		Tags:              clients.MergeTags(defaultTags, p.Tags),
+		Type_:             nextgen.ConnectorType(p.Type),
+	}
+	if p.Name != nil {
+		info.Name = *p.Name
+	}
+
+	var err error
+	switch p.Type {
+	case v1alpha1.ConnectorTypeGit:
+		info.Git, err = gitConfig(p)
+	case v1alpha1.ConnectorTypeDockerRegistry:
+		info.DockerRegistry, err = dockerConnector(p)
+	case v1alpha1.ConnectorTypeAws:
+		info.Aws, err = awsConnector(p)
+	}
+	return info, err
+}
+
+func gitConfig(p v1alpha1.ConnectorParameters) (*nextgen.GitConfig, error) {
+	if p.URL == nil {
+		return nil, errors.Errorf(errNoURL, p.Type)
+	}
+	g := &nextgen.GitConfig{
+		Url:               *p.URL,
+		ValidationRepo:    clients.StringValue(p.ValidationRepo),
+		ConnectionType:    string(nextgen.GitConnectorTypes.Repo),
+		DelegateSelectors: p.DelegateSelectors,
+	}
+	if p.ConnectionType != nil {
+		g.ConnectionType = *p.ConnectionType
+	}
+
+	a := p.Auth
+	switch a.Type {
+	case v1alpha1.ConnectorAuthUsernamePassword:
+		if a.PasswordRef == nil {
+			return nil, errors.Errorf(errMissingAuth, a.Type, "passwordRef")
+		}
+		g.Type_ = nextgen.GitAuthTypes.Http
+		g.Http = &nextgen.GitHttpAuthenticationDto{Username: clients.StringValue(a.Username), UsernameRef: clients.StringValue(a.UsernameRef), PasswordRef: *a.PasswordRef}
+	case v1alpha1.ConnectorAuthSSHKey:
+		if a.SSHKeyRef == nil {
+			return nil, errors.Errorf(errMissingAuth, a.Type, "sshKeyRef")
+		}
+		g.Type_ = nextgen.GitAuthTypes.Ssh
+		g.Ssh = &nextgen.GitSshAuthentication{SshKeyRef: *a.SSHKeyRef}
+	default:
+		return nil, errors.Errorf(errUnsupportedAuth, p.Type, a.Type)
+	}
+	return g, nil
+}
+
+func dockerConnector(p v1alpha1.ConnectorParameters) (*nextgen.DockerConnector, error) {
+	if p.URL == nil {
+		return nil, errors.Errorf(errNoURL, p.Type)
+	}
+	d := &nextgen.DockerConnector{
+		DockerRegistryUrl: *p.URL,
+		ProviderType:      string(nextgen.DockerRegistryTypes.Other),
+		DelegateSelectors: p.DelegateSelectors,
+	}
+	if p.RegistryType != nil {
+		d.ProviderType = *p.RegistryType
+	}
+
+	a := p.Auth
+	switch a.Type {
+	case v1alpha1.ConnectorAuthAnonymous:
+		d.Auth = &nextgen.DockerAuthentication{Type_: nextgen.DockerAuthTypes.Anonymous}
+	case v1alpha1.ConnectorAuthUsernamePassword:
+		if a.PasswordRef == nil {
+			return nil, errors.Errorf(errMissingAuth, a.Type, "passwordRef")
+		}
+		d.Auth = &nextgen.DockerAuthentication{
+			Type_:            nextgen.DockerAuthTypes.UsernamePassword,
+			UsernamePassword: &nextgen.DockerUserNamePassword{Username: clients.StringValue(a.Username), UsernameRef: clients.StringValue(a.UsernameRef), PasswordRef: *a.PasswordRef},
+		}
+	default:
+		return nil, errors.Errorf(errUnsupportedAuth, p.Type, a.Type)
+	}
+	return d, nil
+}
+
+func awsConnector(p v1alpha1.ConnectorParameters) (*nextgen.AwsConnector, error) {
+	aws := &nextgen.AwsConnector{DelegateSelectors: p.DelegateSelectors}
+
+	a := p.Auth
+	switch a.Type {
+	case v1alpha1.ConnectorAuthAccessKey:
+		if a.SecretKeyRef == nil {
+			return nil, errors.Errorf(errMissingAuth, a.Type, "secretKeyRef")
+		}
+		aws.Credential = &nextgen.AwsCredential{
+			Type_:        nextgen.AwsAuthTypes.ManualConfig,
+			ManualConfig: &nextgen.AwsManualConfigSpec{AccessKey: clients.StringValue(a.AccessKey), AccessKeyRef: clients.StringValue(a.AccessKeyRef), SecretKeyRef: *a.SecretKeyRef},
+		}
+	case v1alpha1.ConnectorAuthInheritFromDelegate:
+		aws.Credential = &nextgen.AwsCredential{Type_: nextgen.AwsAuthTypes.InheritFromDelegate}
+	default:
+		return nil, errors.Errorf(errUnsupportedAuth, p.Type, a.Type)
+	}
+	return aws, nil
+}
+
+// upToDate returns true if the observed connector matches the desired one.
+// Only the fields a Connector configures are compared, since Harness returns
+// defaults for others.
+func upToDate(desired, observed nextgen.ConnectorInfo) bool {
+	if desired.Name != observed.Name || desired.Description != observed.Description || desired.Type_ != observed.Type_ {
+		return false
+	}
+	if !clients.TagsEqual(desired.Tags, observed.Tags) {
+		return false
+	}
+	switch desired.Type_ {
+	case nextgen.ConnectorTypes.Git:
+		return observed.Git != nil && gitUpToDate(*desired.Git, *observed.Git)
+	case nextgen.ConnectorTypes.DockerRegistry:
+		return observed.DockerRegistry != nil && dockerUpToDate(*desired.DockerRegistry, *observed.DockerRegistry)
+	case nextgen.ConnectorTypes.Aws:
+		return observed.Aws != nil && awsUpToDate(*desired.Aws, *observed.Aws)
+	}
+	return true
+}
+
+func gitUpToDate(desired, observed nextgen.GitConfig) bool {
+	if desired.Url != observed.Url || desired.ValidationRepo != observed.ValidationRepo || desired.ConnectionType != observed.ConnectionType || desired.Type_ != observed.Type_ {
+		return false
+	}
+	if !clients.SameStrings(desired.DelegateSelectors, observed.DelegateSelectors) {
+		return false
+	}
+	// The type of the authentication is only reported by the GitConfig, so
+	// the type of the Http or Ssh authentication itself is not compared.
+	if (desired.Http == nil) != (observed.Http == nil) || (desired.Ssh == nil) != (observed.Ssh == nil) {
+		return false
+	}
+	if desired.Http != nil {
+		d, o := desired.Http, observed.Http
+		if d.Username != o.Username || d.UsernameRef != o.UsernameRef || d.PasswordRef != o.PasswordRef {
+			return false
+		}
+	}
+	return desired.Ssh == nil || desired.Ssh.SshKeyRef == observed.Ssh.SshKeyRef
+}
+
+func dockerUpToDate(desired, observed nextgen.DockerConnector) bool {
+	if desired.DockerRegistryUrl != observed.DockerRegistryUrl || desired.ProviderType != observed.ProviderType {
+		return false
+	}
+	if !clients.SameStrings(desired.DelegateSelectors, observed.DelegateSelectors) {
+		return false
+	}
+	if observed.Auth == nil || desired.Auth.Type_ != observed.Auth.Type_ {
+		return false
+	}
+	d, o := desired.Auth.UsernamePassword, observed.Auth.UsernamePassword
+	if d == nil || o == nil {
+		return d == o
+	}
+	return d.Username == o.Username && d.UsernameRef == o.UsernameRef && d.PasswordRef == o.PasswordRef
+}
+
+func awsUpToDate(desired, observed nextgen.AwsConnector) bool {
+	if !clients.SameStrings(desired.DelegateSelectors, observed.DelegateSelectors) {
+		return false
+	}
+	if observed.Credential == nil || desired.Credential.Type_ != observed.Credential.Type_ {
+		return false
+	}
+	d, o := desired.Credential.ManualConfig, observed.Credential.ManualConfig
+	if d == nil || o == nil {
+		return d == o
+	}
+	return d.AccessKey == o.AccessKey && d.AccessKeyRef == o.AccessKeyRef && d.SecretKeyRef == o.SecretKeyRef
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const url = "https://github.com/argoproj/argocd-example-apps.git"
+
+// found returns the body of a response that returns the supplied connector.
+func found(t *testing.T, info nextgen.ConnectorInfo, st *nextgen.ConnectorConnectivityDetails) string {
+	t.Helper()
+	b, err := json.Marshal(nextgen.ResponseDtoConnectorResponse{Status: "SUCCESS", Data: &nextgen.ConnectorResponse{
+		Connector:      &info,
+		CreatedAt:      1700000000000,
+		LastModifiedAt: 1700000000000,
+		Status:         st,
+	}})
+	if err != nil {
+		t.Fatalf("json.Marshal(...): %s", err)
+	}
+	return string(b)
+}
+
+func gitParameters() v1alpha1.ConnectorParameters {
+	u, password := url, "account.github-token"
+	user := "git"
+	return v1alpha1.ConnectorParameters{
+		AccountIdentifier: "account",
+		Identifier:        "github",
+		Type:              v1alpha1.ConnectorTypeGit,
+		URL:               &u,
+		Auth:              v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthUsernamePassword, Username: &user, PasswordRef: &password},
+	}
+}
+
+func newConnector(p v1alpha1.ConnectorParameters) *v1alpha1.Connector {
+	return &v1alpha1.Connector{Spec: v1alpha1.ConnectorSpec{ForProvider: p}}
+}
+
+func TestObserve(t *testing.T) {
+	desired, err := connectorInfo(gitParameters(), nil)
+	if err != nil {
+		t.Fatalf("connectorInfo(...): %s", err)
+	}
+	moved := desired
+	movedGit := *desired.Git
+	movedGit.Url = "https://example.org/other.git"
+	moved.Git = &movedGit
+	at := time.UnixMilli(1700000000000)
+
+	type want struct {
+		o   managed.ExternalObservation
+		at  v1alpha1.ConnectorObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    want
+	}{
+		"NotFound": {
+			reason:  "A connector Harness does not know should be reported as absent.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+			want:    want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"GetError": {
+			reason:  "Errors other than not found should be returned rather than reported as absent.",
+			handler: clientstest.Respond(http.StatusInternalServerError, `{"status":"ERROR","message":"boom"}`),
+			want:    want{err: errors.Wrap(errors.New("boom"), errGetConnector)},
+		},
+		"UpToDate": {
+			reason:  "A connector matching the desired state should be up to date.",
+			handler: clientstest.Respond(http.StatusOK, found(t, desired, &nextgen.ConnectorConnectivityDetails{Status: connectivitySuccess})),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				at: v1alpha1.ConnectorObservation{
+					ConnectivityStatus: connectivitySuccess,
+					CreatedAt:          &metav1.Time{Time: at},
+					LastModifiedAt:     &metav1.Time{Time: at},
+				},
+			},
+		},
+		"OutOfDate": {
+			reason:  "A connector with a different URL should not be up to date, and a failed connection test should be recorded.",
+			handler: clientstest.Respond(http.StatusOK, found(t, moved, &nextgen.ConnectorConnectivityDetails{Status: "FAILURE", ErrorSummary: "repository not found"})),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				at: v1alpha1.ConnectorObservation{
+					ConnectivityStatus: "FAILURE",
+					ConnectivityError:  "repository not found",
+					CreatedAt:          &metav1.Time{Time: at},
+					LastModifiedAt:     &metav1.Time{Time: at},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := newConnector(gitParameters())
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.at, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var body map[string]interface{}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(b, &body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"SUCCESS"}`))
+	}
+
+	p := gitParameters()
+	p.Tags = map[string]string{"env": "prod"}
+	svc := clientstest.NewService(t, handler)
+	svc.DefaultTags = map[string]string{"env": "dev", "team": "platform"}
+	e := external{service: svc}
+	if _, err := e.Create(context.Background(), newConnector(p)); err != nil {
+		t.Fatalf("e.Create(...): %s", err)
+	}
+
+	want := map[string]interface{}{"connector": map[string]interface{}{
+		"name":       "github",
+		"identifier": "github",
+		"type":       "Git",
+		"tags":       map[string]interface{}{"env": "prod", "team": "platform"},
+		"spec": map[string]interface{}{
+			"url":            url,
+			"connectionType": "Repo",
+			"type":           "Http",
+			"spec": map[string]interface{}{
+				"type":        "",
+				"username":    "git",
+				"passwordRef": "account.github-token",
+			},
+		},
+	}}
+	if diff := cmp.Diff(want, body); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	long := strings.Repeat("a", clients.MaxTagValueLength+1)
+
+	cases := map[string]struct {
+		reason      string
+		tags        map[string]string
+		defaultTags map[string]string
+		want        error
+		requests    int
+	}{
+		"Updated": {
+			reason:      "A connector with valid tags should be updated.",
+			tags:        map[string]string{"env": "prod"},
+			defaultTags: map[string]string{"team": "platform"},
+			requests:    1,
+		},
+		"InvalidTags": {
+			reason: "A connector with tags Harness would reject should not be updated.",
+			tags:   map[string]string{"": "prod"},
+			want:   errors.Wrap(clients.ValidateTags(map[string]string{"": "prod"}), errInvalidTags),
+		},
+		"InvalidDefaultTags": {
+			reason:      "The ProviderConfig's default tags should be validated along with the connector's own.",
+			tags:        map[string]string{"env": "prod"},
+			defaultTags: map[string]string{"team": long},
+			want:        errors.Wrap(clients.ValidateTags(map[string]string{"team": long}), errInvalidTags),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				clientstest.Respond(http.StatusOK, `{"status":"SUCCESS"}`)(w, r)
+			}
+			svc := clientstest.NewService(t, handler)
+			svc.DefaultTags = tc.defaultTags
+			e := external{service: svc}
+
+			p := gitParameters()
+			p.Tags = tc.tags
+			_, err := e.Update(context.Background(), newConnector(p))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.requests, requests); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "A deleted connector should be reported as such.",
+			handler: clientstest.Respond(http.StatusOK, `{"status":"SUCCESS","data":true}`),
+		},
+		"AlreadyDeleted": {
+			reason:  "A connector Harness no longer knows should be treated as deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"Error": {
+			reason:  "Other errors deleting the connector should be returned.",
+			handler: clientstest.Respond(http.StatusBadRequest, `{"status":"FAILURE","message":"boom"}`),
+			want:    errors.Wrap(errors.New("boom"), errDeleteConnector),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), newConnector(gitParameters()))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnectorInfo(t *testing.T) {
+	registry, secret := "https://registry.example.org", "account.aws-secret-key"
+
+	cases := map[string]struct {
+		reason string
+		p      func() v1alpha1.ConnectorParameters
+		want   error
+	}{
+		"Git": {
+			reason: "A Git connector authenticated with a username and password should be valid.",
+			p:      gitParameters,
+		},
+		"GitWithoutURL": {
+			reason: "A Git connector without a URL should be rejected.",
+			p: func() v1alpha1.ConnectorParameters {
+				p := gitParameters()
+				p.URL = nil
+				return p
+			},
+			want: errors.Errorf(errNoURL, v1alpha1.ConnectorTypeGit),
+		},
+		"GitAnonymous": {
+			reason: "A Git connector does not support anonymous authentication.",
+			p: func() v1alpha1.ConnectorParameters {
+				p := gitParameters()
+				p.Auth = v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthAnonymous}
+				return p
+			},
+			want: errors.Errorf(errUnsupportedAuth, v1alpha1.ConnectorTypeGit, v1alpha1.ConnectorAuthAnonymous),
+		},
+		"GitWithoutSSHKey": {
+			reason: "A Git connector authenticated with an SSH key must reference one.",
+			p: func() v1alpha1.ConnectorParameters {
+				p := gitParameters()
+				p.Auth = v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthSSHKey}
+				return p
+			},
+			want: errors.Errorf(errMissingAuth, v1alpha1.ConnectorAuthSSHKey, "sshKeyRef"),
+		},
+		"DockerRegistryAnonymous": {
+			reason: "A Docker registry connector may authenticate anonymously.",
+			p: func() v1alpha1.ConnectorParameters {
+				return v1alpha1.ConnectorParameters{Type: v1alpha1.ConnectorTypeDockerRegistry, URL: &registry, Auth: v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthAnonymous}}
+			},
+		},
+		"AwsAccessKey": {
+			reason: "An AWS connector authenticated with an access key should be valid.",
+			p: func() v1alpha1.ConnectorParameters {
+				return v1alpha1.ConnectorParameters{Type: v1alpha1.ConnectorTypeAws, Auth: v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthAccessKey, SecretKeyRef: &secret}}
+			},
+		},
+		"AwsWithoutSecretKey": {
+			reason: "An AWS connector authenticated with an access key must reference its secret key.",
+			p: func() v1alpha1.ConnectorParameters {
+				return v1alpha1.ConnectorParameters{Type: v1alpha1.ConnectorTypeAws, Auth: v1alpha1.ConnectorAuth{Type: v1alpha1.ConnectorAuthAccessKey}}
+			},
+			want: errors.Errorf(errMissingAuth, v1alpha1.ConnectorAuthAccessKey, "secretKeyRef"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := connectorInfo(tc.p(), nil)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nconnectorInfo(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
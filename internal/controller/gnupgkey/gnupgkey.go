@@ -0,0 +1,261 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package gnupgkey contains the controller of GitOps GnuPGKey managed
+// resources.
+package gnupgkey
+
+import (
+	"context"
+	"strings"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotGnuPGKey = "managed resource is not a GnuPGKey custom resource"
+
+	errGetKey    = "cannot get GnuPG key"
+	errCreateKey = "cannot create GnuPG key"
+	errUpdateKey = "cannot update GnuPG key"
+	errDeleteKey = "cannot delete GnuPG key"
+	errPersistID = "cannot persist the ID of the replacement GnuPG key"
+	errNoKeyID   = "Harness did not return the ID of the GnuPG key"
+)
+
+// Setup adds a controller that reconciles GnuPGKey managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.GnuPGKeyGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.GnuPGKeyGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.GnuPGKeyKind)),
+		o.ManagementPolicies(),
+		// Harness derives key IDs from the keys, so the external name is set
+		// when the key is created rather than defaulted to the name.
+		managed.WithInitializers(),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.GnuPGKey{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the GnuPGKey's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.GnuPGKey); !ok {
+		return nil, errors.New(errNotGnuPGKey)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc, kube: c.harness.Kube}, nil
+}
+
+// An external observes, then either registers, replaces, or deregisters a
+// GnuPG key to ensure it reflects the GnuPGKey's desired state. A key is
+// identified by the key ID Harness derives from it, which is recorded as the
+// GnuPGKey's external name.
+type external struct {
+	service *clients.Service
+	kube    client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.GnuPGKey)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGnuPGKey)
+	}
+	p := cr.Spec.ForProvider
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	key, response, err := c.service.GnuPGPKeysApi.AgentGPGKeyServiceGet(c.service.Authorize(ctx), p.AgentIdentifier, id, p.AccountIdentifier,
+		&nextgen.GnuPGPKeysApiAgentGPGKeyServiceGetOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+	}
+	if key.KeyID == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider.KeyID = key.KeyID
+	cr.Status.AtProvider.Fingerprint = key.Fingerprint
+	cr.Status.AtProvider.Owner = key.Owner
+	cr.Status.AtProvider.Trust = key.Trust
+	cr.Status.AtProvider.SubType = key.SubType
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(p, key),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.GnuPGKey)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGnuPGKey)
+	}
+
+	id, err := c.register(ctx, cr.Spec.ForProvider, false)
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateKey)
+	}
+
+	meta.SetExternalName(cr, id)
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+// Update replaces the key, since a key with different data has a different
+// key ID. The new key is registered before the old one is deregistered so
+// that commits signed with either remain verifiable in between.
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.GnuPGKey)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGnuPGKey)
+	}
+
+	old := meta.GetExternalName(cr)
+	id, err := c.register(ctx, cr.Spec.ForProvider, true)
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateKey)
+	}
+	if id == old {
+		return managed.ExternalUpdate{}, nil
+	}
+	// The managed reconciler only persists the external name after a Create,
+	// so the new key ID is persisted here. It is persisted before the old key
+	// is deregistered so that the GnuPGKey never tracks a key that is gone.
+	meta.SetExternalName(cr, id)
+	if err := c.kube.Update(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errPersistID)
+	}
+	return managed.ExternalUpdate{}, errors.Wrap(c.deregister(ctx, cr.Spec.ForProvider, old), errUpdateKey)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.GnuPGKey)
+	if !ok {
+		return errors.New(errNotGnuPGKey)
+	}
+	cr.SetConditions(xpv1.Deleting())
+	return errors.Wrap(c.deregister(ctx, cr.Spec.ForProvider, meta.GetExternalName(cr)), errDeleteKey)
+}
+
+// register registers the key with the supplied parameters and returns its
+// key ID. A key that is already registered is reported as skipped, in which
+// case the ID of the existing key is returned.
+func (c *external) register(ctx context.Context, p v1alpha1.GnuPGKeyParameters, upsert bool) (string, error) {
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.GnuPGPKeysApi.AgentGPGKeyServiceCreate(c.service.Authorize(ctx),
+		nextgen.GpgkeysGnuPgPublicKeyCreateRequest{Publickey: &nextgen.GpgkeysGnuPgPublicKey{KeyData: p.PublicKey}, Upsert: upsert},
+		p.AgentIdentifier,
+		&nextgen.GnuPGPKeysApiAgentGPGKeyServiceCreateOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return "", err
+	}
+	switch {
+	case rsp.Created != nil && len(rsp.Created.Items) > 0 && rsp.Created.Items[0].KeyID != "":
+		return rsp.Created.Items[0].KeyID, nil
+	case len(rsp.Skipped) > 0 && rsp.Skipped[0] != "":
+		return rsp.Skipped[0], nil
+	}
+	return "", errors.New(errNoKeyID)
+}
+
+// deregister deregisters the key with the supplied key ID. A key Harness no
+// longer knows is considered deregistered.
+func (c *external) deregister(ctx context.Context, p v1alpha1.GnuPGKeyParameters, id string) error {
+	if id == "" {
+		return nil
+	}
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.GnuPGPKeysApi.AgentGPGKeyServiceDelete(c.service.Authorize(ctx), p.AgentIdentifier, id,
+		&nextgen.GnuPGPKeysApiAgentGPGKeyServiceDeleteOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return err
+}
+
+// upToDate returns true if the observed key matches the supplied parameters.
+// Harness does not always return the key data, in which case the key is
+// assumed to be up to date, since its ID is derived from its data.
+func upToDate(p v1alpha1.GnuPGKeyParameters, observed nextgen.GpgkeysGnuPgPublicKey) bool {
+	return observed.KeyData == "" || strings.TrimSpace(p.PublicKey) == strings.TrimSpace(observed.KeyData)
+}
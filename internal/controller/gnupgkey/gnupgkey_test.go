@@ -0,0 +1,305 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package gnupgkey
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const publicKey = "-----BEGIN PGP PUBLIC KEY BLOCK-----\nmQINBGExampleKey\n-----END PGP PUBLIC KEY BLOCK-----\n"
+
+func gnupgKey(id string) *v1alpha1.GnuPGKey {
+	cr := &v1alpha1.GnuPGKey{Spec: v1alpha1.GnuPGKeySpec{ForProvider: v1alpha1.GnuPGKeyParameters{
+		AccountIdentifier: "account",
+		AgentIdentifier:   "agent",
+		PublicKey:         publicKey,
+	}}}
+	if id != "" {
+		meta.SetExternalName(cr, id)
+	}
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	type want struct {
+		o   managed.ExternalObservation
+		at  v1alpha1.GnuPGKeyObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler func(t *testing.T) http.HandlerFunc
+		cr      *v1alpha1.GnuPGKey
+		want    want
+	}{
+		"NotCreated": {
+			reason: "A key without a key ID has not been created yet.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return func(_ http.ResponseWriter, _ *http.Request) { t.Errorf("unexpected request") }
+			},
+			cr:   gnupgKey(""),
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"NotFound": {
+			reason: "A key Harness does not know should be reported as absent.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusNotFound, "")
+			},
+			cr:   gnupgKey("4AEE18F83AFDEB23"),
+			want: want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"GetError": {
+			reason: "Errors other than not found should be returned rather than reported as absent.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusInternalServerError, "")
+			},
+			cr:   gnupgKey("4AEE18F83AFDEB23"),
+			want: want{err: errors.Wrap(errors.New("500 Internal Server Error"), errGetKey)},
+		},
+		"UpToDate": {
+			reason: "A key matching the desired key data should be up to date, ignoring surrounding whitespace.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusOK, clientstest.JSON(t, nextgen.GpgkeysGnuPgPublicKey{
+					KeyID: "4AEE18F83AFDEB23", Fingerprint: "fingerprint", Owner: "owner", Trust: "unknown", SubType: "rsa4096", KeyData: "\n" + publicKey,
+				}))
+			},
+			cr: gnupgKey("4AEE18F83AFDEB23"),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				at: v1alpha1.GnuPGKeyObservation{KeyID: "4AEE18F83AFDEB23", Fingerprint: "fingerprint", Owner: "owner", Trust: "unknown", SubType: "rsa4096"},
+			},
+		},
+		"OutOfDate": {
+			reason: "A key with different key data should not be up to date.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusOK, clientstest.JSON(t, nextgen.GpgkeysGnuPgPublicKey{KeyID: "4AEE18F83AFDEB23", KeyData: "other"}))
+			},
+			cr: gnupgKey("4AEE18F83AFDEB23"),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				at: v1alpha1.GnuPGKeyObservation{KeyID: "4AEE18F83AFDEB23"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler(t))}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.at, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		id  string
+		err error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler func(t *testing.T) http.HandlerFunc
+		want    want
+	}{
+		"Created": {
+			reason: "The key ID of a created key should be recorded as the external name.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					req := nextgen.GpgkeysGnuPgPublicKeyCreateRequest{}
+					_ = json.NewDecoder(r.Body).Decode(&req)
+					if req.Upsert || req.Publickey == nil || req.Publickey.KeyData != publicKey {
+						t.Errorf("unexpected create request %+v", req)
+					}
+					clientstest.Respond(http.StatusOK, clientstest.JSON(t, nextgen.GpgkeysGnuPgPublicKeyCreateResponse{
+						Created: &nextgen.GpgkeysGnuPgPublicKeyList{Items: []nextgen.GpgkeysGnuPgPublicKey{{KeyID: "4AEE18F83AFDEB23"}}},
+					}))(w, r)
+				}
+			},
+			want: want{id: "4AEE18F83AFDEB23"},
+		},
+		"Skipped": {
+			reason: "The key ID of an already registered key should be recorded as the external name.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusOK, clientstest.JSON(t, nextgen.GpgkeysGnuPgPublicKeyCreateResponse{Skipped: []string{"4AEE18F83AFDEB23"}}))
+			},
+			want: want{id: "4AEE18F83AFDEB23"},
+		},
+		"NoKeyID": {
+			reason: "Creating a key should fail if Harness does not return its key ID.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return clientstest.Respond(http.StatusOK, "{}")
+			},
+			want: want{err: errors.Wrap(errors.New(errNoKeyID), errCreateKey)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := gnupgKey("")
+			e := external{service: clientstest.NewService(t, tc.handler(t))}
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		err    error
+		id     string
+		events []string
+	}
+
+	cases := map[string]struct {
+		reason string
+		key    string
+		update test.MockUpdateFn
+		want   want
+	}{
+		"Replaced": {
+			reason: "The ID of the replacement key should be persisted before the old key is deregistered.",
+			key:    "NEWKEY",
+			update: test.NewMockUpdateFn(nil),
+			want: want{
+				id:     "NEWKEY",
+				events: []string{"register", "persist NEWKEY", "deregister /gitops/api/v1/agents/agent/gpgkeys/OLDKEY"},
+			},
+		},
+		"Unchanged": {
+			reason: "Nothing should be persisted or deregistered if Harness reports the same key ID.",
+			key:    "OLDKEY",
+			update: test.NewMockUpdateFn(errBoom),
+			want: want{
+				id:     "OLDKEY",
+				events: []string{"register"},
+			},
+		},
+		"PersistError": {
+			reason: "The old key should not be deregistered if the ID of its replacement cannot be persisted.",
+			key:    "NEWKEY",
+			update: test.NewMockUpdateFn(errBoom),
+			want: want{
+				err:    errors.Wrap(errBoom, errPersistID),
+				id:     "NEWKEY",
+				events: []string{"register", "persist NEWKEY"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var events []string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodDelete {
+					events = append(events, "deregister "+r.URL.Path)
+					clientstest.Respond(http.StatusOK, "{}")(w, r)
+					return
+				}
+				events = append(events, "register")
+				clientstest.Respond(http.StatusOK, clientstest.JSON(t, nextgen.GpgkeysGnuPgPublicKeyCreateResponse{
+					Created: &nextgen.GpgkeysGnuPgPublicKeyList{Items: []nextgen.GpgkeysGnuPgPublicKey{{KeyID: tc.key}}},
+				}))(w, r)
+			}
+			kube := &test.MockClient{MockUpdate: func(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+				events = append(events, "persist "+meta.GetExternalName(obj))
+				return tc.update(ctx, obj, opts...)
+			}}
+
+			cr := gnupgKey("OLDKEY")
+			e := external{service: clientstest.NewService(t, handler), kube: kube}
+			_, err := e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, events); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "Deleting a key should succeed.",
+			handler: clientstest.Respond(http.StatusOK, "{}"),
+		},
+		"NotFound": {
+			reason:  "A key Harness does not know should be considered deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"DeleteError": {
+			reason:  "Errors other than not found should be returned.",
+			handler: clientstest.Respond(http.StatusInternalServerError, ""),
+			want:    errors.Wrap(errors.New("500 Internal Server Error"), errDeleteKey),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := gnupgKey("4AEE18F83AFDEB23")
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), cr)
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(xpv1.Deleting(), cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
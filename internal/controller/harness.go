@@ -17,19 +17,53 @@ limitations under the License.
 package controller
 
 import (
-	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	gitopsv1alpha1 "github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	platformv1alpha1 "github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+
 	"github.com/crossplane/provider-harness/internal/controller/agent"
+	"github.com/crossplane/provider-harness/internal/controller/agentclaim"
+	"github.com/crossplane/provider-harness/internal/controller/application"
+	"github.com/crossplane/provider-harness/internal/controller/cluster"
 	"github.com/crossplane/provider-harness/internal/controller/config"
+	"github.com/crossplane/provider-harness/internal/controller/connector"
+	"github.com/crossplane/provider-harness/internal/controller/gnupgkey"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/controller/project"
+	"github.com/crossplane/provider-harness/internal/controller/repository"
+	"github.com/crossplane/provider-harness/internal/controller/repositorycertificate"
 )
 
+// PolledKinds are the managed resource kinds whose controllers Setup
+// registers with a poll interval that may be overridden per kind.
+var PolledKinds = []string{
+	gitopsv1alpha1.AgentKind,
+	gitopsv1alpha1.NamespacedAgentKind,
+	gitopsv1alpha1.RepositoryKind,
+	gitopsv1alpha1.ClusterKind,
+	gitopsv1alpha1.ApplicationKind,
+	gitopsv1alpha1.RepositoryCertificateKind,
+	gitopsv1alpha1.GnuPGKeyKind,
+	platformv1alpha1.ConnectorKind,
+	platformv1alpha1.ProjectKind,
+}
+
 // Setup creates all Harness controllers with the supplied logger and adds them to
 // the supplied manager.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
-	for _, setup := range []func(ctrl.Manager, controller.Options) error{
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	for _, setup := range []func(ctrl.Manager, options.Options) error{
 		config.Setup,
 		agent.Setup,
+		agent.SetupNamespaced,
+		agentclaim.Setup,
+		repository.Setup,
+		cluster.Setup,
+		application.Setup,
+		repositorycertificate.Setup,
+		gnupgkey.Setup,
+		connector.Setup,
+		project.Setup,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
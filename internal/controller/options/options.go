@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package options contains the options shared by the Harness controllers.
+package options
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane/provider-harness/internal/features"
+)
+
+const (
+	errPollInterval = "invalid poll interval %q for kind %s: must be a positive duration"
+	errPollKind     = "unknown kind %q in poll intervals: must be one of %s"
+)
+
+// Options configures the Harness controllers.
+type Options struct {
+	controller.Options
+
+	// MaxReconcilesPerMinute caps how many times a single managed resource
+	// may be reconciled per minute. Zero means no cap, though resources may
+	// still set their own by annotation.
+	MaxReconcilesPerMinute int
+
+	// ReconcileFailureThreshold is the number of consecutive failed
+	// reconciles after which a managed resource is flagged as failing. Zero
+	// means resources are never flagged.
+	ReconcileFailureThreshold int64
+
+	// ControllerNamePrefix is prepended to the names of controllers, which
+	// are used to label their metrics and as the source of their events.
+	// Controller names are not prefixed when it is empty.
+	ControllerNamePrefix string
+
+	// PollIntervals overrides PollInterval for the managed resource kinds it
+	// contains, keyed by kind.
+	PollIntervals map[string]time.Duration
+}
+
+// ControllerName returns the supplied controller name with the configured
+// prefix, if any.
+func (o Options) ControllerName(name string) string {
+	if o.ControllerNamePrefix == "" {
+		return name
+	}
+	return o.ControllerNamePrefix + "/" + name
+}
+
+// PollIntervalFor returns the poll interval of the supplied managed resource
+// kind, falling back to PollInterval if it is not overridden.
+func (o Options) PollIntervalFor(kind string) time.Duration {
+	if d, ok := o.PollIntervals[kind]; ok {
+		return d
+	}
+	return o.PollInterval
+}
+
+// ManagementPolicies returns a reconciler option that enables support for
+// management policies if the feature is enabled, and does nothing otherwise.
+func (o Options) ManagementPolicies() managed.ReconcilerOption {
+	return func(r *managed.Reconciler) {
+		if o.Features.Enabled(features.EnableAlphaManagementPolicies) {
+			managed.WithManagementPolicies()(r)
+		}
+	}
+}
+
+// ParsePollIntervals parses per-kind poll intervals from a map of kind to
+// duration, e.g. {"Agent": "30s"}. Kinds must be one of the supplied kinds,
+// exactly as they are spelled, so that a misspelled kind is not silently
+// ignored.
+func ParsePollIntervals(in map[string]string, kinds []string) (map[string]time.Duration, error) {
+	known := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		known[k] = true
+	}
+	out := make(map[string]time.Duration, len(in))
+	for kind, v := range in {
+		if !known[kind] {
+			return nil, errors.Errorf(errPollKind, kind, strings.Join(kinds, ", "))
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, errors.Errorf(errPollInterval, v, kind)
+		}
+		out[kind] = d
+	}
+	return out, nil
+}
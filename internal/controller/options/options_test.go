@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestControllerName(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		prefix string
+		want   string
+	}{
+		"NoPrefix": {
+			reason: "Controller names should be unchanged when no prefix is configured.",
+			want:   "managed/agent.gitops.harness.crossplane.io",
+		},
+		"Prefix": {
+			reason: "Controller names should be prefixed when a prefix is configured.",
+			prefix: "acme",
+			want:   "acme/managed/agent.gitops.harness.crossplane.io",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := Options{ControllerNamePrefix: tc.prefix}.ControllerName("managed/agent.gitops.harness.crossplane.io")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nControllerName(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPollIntervalFor(t *testing.T) {
+	o := Options{
+		Options:       controller.Options{PollInterval: time.Minute},
+		PollIntervals: map[string]time.Duration{"Agent": 30 * time.Second},
+	}
+
+	cases := map[string]struct {
+		reason string
+		kind   string
+		want   time.Duration
+	}{
+		"Overridden": {
+			reason: "An overridden kind should use its own poll interval.",
+			kind:   "Agent",
+			want:   30 * time.Second,
+		},
+		"Default": {
+			reason: "Other kinds should use the global poll interval.",
+			kind:   "Repository",
+			want:   time.Minute,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, o.PollIntervalFor(tc.kind)); diff != "" {
+				t.Errorf("\n%s\nPollIntervalFor(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestParsePollIntervals(t *testing.T) {
+	kinds := []string{"Agent", "Repository"}
+
+	type want struct {
+		out map[string]time.Duration
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		in     map[string]string
+		want   want
+	}{
+		"Valid": {
+			reason: "Valid durations should be parsed.",
+			in:     map[string]string{"Agent": "30s", "Repository": "10m"},
+			want:   want{out: map[string]time.Duration{"Agent": 30 * time.Second, "Repository": 10 * time.Minute}},
+		},
+		"Invalid": {
+			reason: "An unparseable duration should be rejected.",
+			in:     map[string]string{"Agent": "often"},
+			want:   want{err: errors.Errorf(errPollInterval, "often", "Agent")},
+		},
+		"NotPositive": {
+			reason: "A zero duration should be rejected.",
+			in:     map[string]string{"Agent": "0s"},
+			want:   want{err: errors.Errorf(errPollInterval, "0s", "Agent")},
+		},
+		"LowerCaseKind": {
+			reason: "A kind that is not spelled exactly as registered should be rejected.",
+			in:     map[string]string{"agent": "30s"},
+			want:   want{err: errors.Errorf(errPollKind, "agent", "Agent, Repository")},
+		},
+		"PluralKind": {
+			reason: "A plural kind should be rejected.",
+			in:     map[string]string{"Agents": "30s"},
+			want:   want{err: errors.Errorf(errPollKind, "Agents", "Agent, Repository")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePollIntervals(tc.in, kinds)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nParsePollIntervals(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.out, got); diff != "" {
+				t.Errorf("\n%s\nParsePollIntervals(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package project contains the controller of platform Project managed
+// resources.
+package project
+
+import (
+	"context"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotProject = "managed resource is not a Project custom resource"
+
+	errGetProject    = "cannot get project"
+	errCreateProject = "cannot create project"
+	errUpdateProject = "cannot update project"
+	errDeleteProject = "cannot delete project"
+	errInvalidTags   = "invalid tags"
+)
+
+// Setup adds a controller that reconciles Project managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.ProjectGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.ProjectGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.ProjectKind)),
+		o.ManagementPolicies(),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.Project{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the Project's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Project); !ok {
+		return nil, errors.New(errNotProject)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a Harness
+// project to ensure it reflects the Project's desired state.
+type external struct {
+	service *clients.Service
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Project)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotProject)
+	}
+	p := cr.Spec.ForProvider
+
+	rsp, response, err := c.service.ProjectApi.GetProject(c.service.Authorize(ctx), p.Identifier, p.AccountIdentifier,
+		&nextgen.ProjectApiGetProjectOpts{OrgIdentifier: optional.NewString(p.OrgIdentifier)})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetProject)
+	}
+	if rsp.Data == nil || rsp.Data.Project == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	observe(cr, rsp.Data)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(project(p, c.service.DefaultTags), *rsp.Data.Project),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Project)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotProject)
+	}
+	p := cr.Spec.ForProvider
+
+	desired := project(p, c.service.DefaultTags)
+	if err := clients.ValidateTags(desired.Tags); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errInvalidTags)
+	}
+	_, response, err := c.service.ProjectApi.PostProject(c.service.Authorize(ctx), nextgen.ProjectRequest{Project: &desired}, p.AccountIdentifier,
+		&nextgen.ProjectApiPostProjectOpts{OrgIdentifier: optional.NewString(p.OrgIdentifier)})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateProject)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Project)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotProject)
+	}
+	p := cr.Spec.ForProvider
+
+	desired := project(p, c.service.DefaultTags)
+	if err := clients.ValidateTags(desired.Tags); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvalidTags)
+	}
+	_, response, err := c.service.ProjectApi.PutProject(c.service.Authorize(ctx), nextgen.ProjectRequest{Project: &desired}, p.AccountIdentifier, p.Identifier,
+		&nextgen.ProjectApiPutProjectOpts{OrgIdentifier: optional.NewString(p.OrgIdentifier)})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateProject)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Project)
+	if !ok {
+		return errors.New(errNotProject)
+	}
+	cr.SetConditions(xpv1.Deleting())
+	p := cr.Spec.ForProvider
+
+	_, response, err := c.service.ProjectApi.DeleteProject(c.service.Authorize(ctx), p.Identifier, p.AccountIdentifier,
+		&nextgen.ProjectApiDeleteProjectOpts{OrgIdentifier: optional.NewString(p.OrgIdentifier)})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	// A project that is already gone has been deleted.
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteProject)
+}
+
+// observe records the observed state of the supplied project.
+func observe(cr *v1alpha1.Project, rsp *nextgen.ProjectResponse) {
+	cr.Status.AtProvider.Color = rsp.Project.Color
+	cr.Status.AtProvider.Modules = rsp.Project.Modules
+	cr.Status.AtProvider.CreatedAt = clients.TimeFromMillis(rsp.CreatedAt)
+	cr.Status.AtProvider.LastModifiedAt = clients.TimeFromMillis(rsp.LastModifiedAt)
+}
+
+// project returns the project described by the supplied parameters, tagged
+// with the supplied default tags beneath its own.
+func project(p v1alpha1.ProjectParameters, defaultTags map[string]string) nextgen.Project {
+	pr := nextgen.Project{
+		OrgIdentifier: p.OrgIdentifier,
+		Identifier:    p.Identifier,
+		Name:          p.Identifier,
+		Color:         clients.StringValue(p.Color),
+		Modules:       p.Modules,
+		Description:   clients.StringValue(p.Description),
+		Tags:          clients.MergeTags(defaultTags, p.Tags),
+	}
+	if p.Name != nil {
+		pr.Name = *p.Name
+	}
+	return pr
+}
+
+// upToDate returns true if the observed project matches the desired one.
+// Harness picks a color and default modules for projects that do not specify
+// them, so these are only compared when they are specified.
+func upToDate(desired, observed nextgen.Project) bool {
+	if desired.Name != observed.Name || desired.Description != observed.Description {
+		return false
+	}
+	if !clients.TagsEqual(desired.Tags, observed.Tags) {
+		return false
+	}
+	if desired.Color != "" && desired.Color != observed.Color {
+		return false
+	}
+	if desired.Modules != nil && !clients.SameStrings(desired.Modules, observed.Modules) {
+		return false
+	}
+	return true
+}
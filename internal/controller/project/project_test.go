@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+func newProject(p v1alpha1.ProjectParameters) *v1alpha1.Project {
+	return &v1alpha1.Project{Spec: v1alpha1.ProjectSpec{ForProvider: p}}
+}
+
+func parameters() v1alpha1.ProjectParameters {
+	return v1alpha1.ProjectParameters{AccountIdentifier: "account", OrgIdentifier: "default", Identifier: "guestbook"}
+}
+
+func TestObserve(t *testing.T) {
+	// Harness picks a color and enables its default modules for projects
+	// that do not specify them.
+	found := `{"status":"SUCCESS","data":{"project":{"orgIdentifier":"default","identifier":"guestbook","name":"guestbook","color":"#0063F7","modules":["CD","CI"]}}}`
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		p       func() v1alpha1.ProjectParameters
+		want    managed.ExternalObservation
+		err     error
+	}{
+		"NotFound": {
+			reason:  "A project Harness does not know should be reported as absent.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+			p:       parameters,
+			want:    managed.ExternalObservation{ResourceExists: false},
+		},
+		"GetError": {
+			reason:  "Errors other than not found should be returned rather than reported as absent.",
+			handler: clientstest.Respond(http.StatusInternalServerError, `{"status":"ERROR","message":"boom"}`),
+			p:       parameters,
+			err:     errors.Wrap(errors.New("boom"), errGetProject),
+		},
+		"UpToDate": {
+			reason:  "A project that leaves its color and modules to Harness should be up to date.",
+			handler: clientstest.Respond(http.StatusOK, found),
+			p:       parameters,
+			want:    managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"ModulesInAnyOrder": {
+			reason:  "The order of a project's modules should not matter.",
+			handler: clientstest.Respond(http.StatusOK, found),
+			p: func() v1alpha1.ProjectParameters {
+				p := parameters()
+				p.Modules = []string{"CI", "CD"}
+				return p
+			},
+			want: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"OutOfDate": {
+			reason:  "A project with different modules should not be up to date.",
+			handler: clientstest.Respond(http.StatusOK, found),
+			p: func() v1alpha1.ProjectParameters {
+				p := parameters()
+				p.Modules = []string{"CD"}
+				return p
+			},
+			want: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			got, err := e.Observe(context.Background(), newProject(tc.p()))
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestObserveTagsInAnyOrder reconciles an unchanged project repeatedly while
+// Harness returns its tags in a different order than they are specified in,
+// as a reconciler would, to catch spurious drift that would update the
+// project on every poll.
+func TestObserveTagsInAnyOrder(t *testing.T) {
+	// The tags are deliberately not in key order, which encoding/json would
+	// otherwise produce.
+	found := `{"status":"SUCCESS","data":{"project":{"orgIdentifier":"default","identifier":"guestbook","name":"guestbook",` +
+		`"color":"#0063F7","modules":["CD","CI"],"tags":{"team":"platform","env":"prod","owner":"payments","cost-center":"42"}}}}`
+
+	updates := 0
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			updates++
+		}
+		clientstest.Respond(http.StatusOK, found)(w, r)
+	}
+	svc := clientstest.NewService(t, handler)
+	svc.DefaultTags = map[string]string{"team": "platform", "cost-center": "42"}
+	e := external{service: svc}
+
+	p := parameters()
+	p.Tags = map[string]string{"cost-center": "42", "env": "prod", "owner": "payments"}
+	cr := newProject(p)
+
+	for i := 0; i < 50; i++ {
+		o, err := e.Observe(context.Background(), cr)
+		if err != nil {
+			t.Fatalf("e.Observe(...): iteration %d: %s", i, err)
+		}
+		if !o.ResourceUpToDate {
+			t.Errorf("e.Observe(...): iteration %d reported an unchanged project as not up to date", i)
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("e.Update(...): iteration %d: %s", i, err)
+			}
+		}
+	}
+	if diff := cmp.Diff(0, updates); diff != "" {
+		t.Errorf("e.Update(...): -want updates, +got updates:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var body nextgen.ProjectRequest
+	var org string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		org = r.URL.Query().Get("orgIdentifier")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"SUCCESS"}`))
+	}
+
+	p := parameters()
+	name, color := "Guestbook", "#0063F7"
+	p.Name, p.Color, p.Modules = &name, &color, []string{"CD"}
+	p.Tags = map[string]string{"env": "prod"}
+
+	svc := clientstest.NewService(t, handler)
+	svc.DefaultTags = map[string]string{"env": "dev", "team": "platform"}
+	e := external{service: svc}
+	if _, err := e.Create(context.Background(), newProject(p)); err != nil {
+		t.Fatalf("e.Create(...): %s", err)
+	}
+	if diff := cmp.Diff("default", org); diff != "" {
+		t.Errorf("e.Create(...): -want org, +got org:\n%s", diff)
+	}
+	want := nextgen.ProjectRequest{Project: &nextgen.Project{
+		OrgIdentifier: "default",
+		Identifier:    "guestbook",
+		Name:          "Guestbook",
+		Color:         "#0063F7",
+		Modules:       []string{"CD"},
+		Tags:          map[string]string{"env": "prod", "team": "platform"},
+	}}
+	if diff := cmp.Diff(want, body); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	long := strings.Repeat("a", clients.MaxTagValueLength+1)
+
+	cases := map[string]struct {
+		reason      string
+		tags        map[string]string
+		defaultTags map[string]string
+		want        error
+		requests    int
+	}{
+		"Updated": {
+			reason:      "A project with valid tags should be updated.",
+			tags:        map[string]string{"env": "prod"},
+			defaultTags: map[string]string{"team": "platform"},
+			requests:    1,
+		},
+		"InvalidTags": {
+			reason: "A project with tags Harness would reject should not be updated.",
+			tags:   map[string]string{"": "prod"},
+			want:   errors.Wrap(clients.ValidateTags(map[string]string{"": "prod"}), errInvalidTags),
+		},
+		"InvalidDefaultTags": {
+			reason:      "The ProviderConfig's default tags should be validated along with the project's own.",
+			tags:        map[string]string{"env": "prod"},
+			defaultTags: map[string]string{"team": long},
+			want:        errors.Wrap(clients.ValidateTags(map[string]string{"team": long}), errInvalidTags),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			requests := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				clientstest.Respond(http.StatusOK, `{"status":"SUCCESS"}`)(w, r)
+			}
+			svc := clientstest.NewService(t, handler)
+			svc.DefaultTags = tc.defaultTags
+			e := external{service: svc}
+
+			p := parameters()
+			p.Tags = tc.tags
+			_, err := e.Update(context.Background(), newProject(p))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.requests, requests); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "A deleted project should be reported as such.",
+			handler: clientstest.Respond(http.StatusOK, `{"status":"SUCCESS","data":true}`),
+		},
+		"AlreadyDeleted": {
+			reason:  "A project Harness no longer knows should be treated as deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"Error": {
+			reason:  "Other errors deleting the project should be returned.",
+			handler: clientstest.Respond(http.StatusBadRequest, `{"status":"FAILURE","message":"boom"}`),
+			want:    errors.Wrap(errors.New("boom"), errDeleteProject),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), newProject(parameters()))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
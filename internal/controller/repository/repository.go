@@ -0,0 +1,378 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package repository contains the controller of GitOps Repository managed
+// resources.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/dependents"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotRepository = "managed resource is not a Repository custom resource"
+
+	errGetRepository    = "cannot get repository"
+	errCreateRepository = "cannot create repository"
+	errUpdateRepository = "cannot update repository"
+	errDeleteRepository = "cannot delete repository"
+	errTestConnection   = "cannot test connection to repository"
+)
+
+// connectionSuccessful is the status Harness reports for a repository the
+// agent connected to.
+const connectionSuccessful = "Successful"
+
+const (
+	// connectionTestInterval is how often a successful connection test is
+	// repeated while the repository is unchanged.
+	connectionTestInterval = time.Hour
+
+	// connectionRetryInterval is how often a failed connection test is
+	// repeated while the repository is unchanged.
+	connectionRetryInterval = time.Minute
+)
+
+// Setup adds a controller that reconciles Repository managed resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.RepositoryGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.RepositoryGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.RepositoryKind)),
+		o.ManagementPolicies(),
+		dependents.WithOwnerReferences(mgr.GetClient(), o.Features, v1alpha1.RepositoryGroupVersionKind, agentOf),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.Repository{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// agentOf returns the account and identifier of the agent that the Repository is added to.
+func agentOf(mg resource.Managed) (account, identifier string) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return "", ""
+	}
+	return cr.Spec.ForProvider.AccountIdentifier, cr.Spec.ForProvider.AgentIdentifier
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the Repository's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Repository); !ok {
+		return nil, errors.New(errNotRepository)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc, kube: c.harness.Kube}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a
+// repository to ensure it reflects the Repository's desired state.
+type external struct {
+	service *clients.Service
+	kube    client.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotRepository)
+	}
+	p := cr.Spec.ForProvider
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceGet(c.service.Authorize(ctx), p.AgentIdentifier, p.Identifier, p.AccountIdentifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceGetOpts{OrgIdentifier: org, ProjectIdentifier: project})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetRepository)
+	}
+	if rsp.Repository == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.SetConditions(xpv1.Available())
+
+	if connectionTestDue(cr.Status.AtProvider, time.Now()) {
+		desired, err := c.repository(ctx, cr)
+		if err != nil {
+			return managed.ExternalObservation{}, err
+		}
+		if err := c.testConnection(ctx, cr, desired); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(p, *rsp.Repository),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotRepository)
+	}
+	p := cr.Spec.ForProvider
+
+	repo, err := c.repository(ctx, cr)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceCreateRepository(c.service.Authorize(ctx),
+		nextgen.RepositoriesRepoCreateRequest{Repo: &repo}, p.AgentIdentifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceCreateRepositoryOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+			Identifier:        optional.NewString(p.Identifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateRepository)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	// The repository was created, so a connection test that failed
+	// transiently is left to the next observation to repeat.
+	_ = c.testConnection(ctx, cr, repo)
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotRepository)
+	}
+	p := cr.Spec.ForProvider
+
+	repo, err := c.repository(ctx, cr)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceUpdateRepository(c.service.Authorize(ctx),
+		nextgen.RepositoriesRepoUpdateRequest{Repo: &repo}, p.AgentIdentifier, p.Identifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceUpdateRepositoryOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateRepository)
+	}
+
+	_ = c.testConnection(ctx, cr, repo)
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Repository)
+	if !ok {
+		return errors.New(errNotRepository)
+	}
+	p := cr.Spec.ForProvider
+	cr.SetConditions(xpv1.Deleting())
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceDeleteRepository(c.service.Authorize(ctx), p.AgentIdentifier, p.Identifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceDeleteRepositoryOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteRepository)
+}
+
+// testConnection tests the agent's connection to the supplied repository and
+// records the result. A failed test is not a reconcile error: the repository
+// itself was reconciled, and the Unreachable condition reports the failure.
+// A test Harness could not run because of a transient error says nothing of
+// whether the repository is reachable, so it is returned rather than recorded.
+func (c *external) testConnection(ctx context.Context, cr *v1alpha1.Repository, repo nextgen.RepositoriesRepository) error {
+	p := cr.Spec.ForProvider
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	state, response, err := c.service.RepositoriesApiService.AgentRepositoryServiceValidateAccess(c.service.Authorize(ctx), accessQuery(repo), p.AccountIdentifier, p.AgentIdentifier,
+		&nextgen.RepositoriesApiAgentRepositoryServiceValidateAccessOpts{
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+			Identifier:        optional.NewString(p.Identifier),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsTransient(err, response) {
+		return errors.Wrap(err, errTestConnection)
+	}
+	if err != nil {
+		state = nextgen.CommonsConnectionState{Message: clients.ErrorMessage(err)}
+	}
+	recordConnection(cr, state, metav1.Now())
+	return nil
+}
+
+// recordConnection records the result of a connection test made at the
+// supplied time. A test succeeded only if Harness reported it as successful.
+func recordConnection(cr *v1alpha1.Repository, state nextgen.CommonsConnectionState, now metav1.Time) {
+	o := &cr.Status.AtProvider
+	o.ConnectionStatus = state.Status
+	o.ConnectionMessage = state.Message
+	o.LastConnectionTestTime = &now
+
+	if state.Status != connectionSuccessful {
+		cr.SetConditions(v1alpha1.Unreachable(state.Message))
+		return
+	}
+	o.LastSuccessfulConnectionTestTime = &now
+	cr.SetConditions(v1alpha1.Reachable())
+}
+
+// connectionTestDue returns true if the connection to a repository with the
+// supplied observation should be tested at the supplied time.
+func connectionTestDue(o v1alpha1.RepositoryObservation, now time.Time) bool {
+	if t := o.LastSuccessfulConnectionTestTime; t != nil && now.Sub(t.Time) < connectionTestInterval {
+		return false
+	}
+	t := o.LastConnectionTestTime
+	return t == nil || now.Sub(t.Time) >= connectionRetryInterval
+}
+
+// repository returns the Harness representation of the supplied Repository,
+// including its credentials.
+func (c *external) repository(ctx context.Context, cr *v1alpha1.Repository) (nextgen.RepositoriesRepository, error) {
+	p := cr.Spec.ForProvider
+	r := nextgen.RepositoriesRepository{
+		Repo:           p.URL,
+		Type_:          clients.StringValue(p.Type),
+		Name:           clients.StringValue(p.Name),
+		ConnectionType: clients.StringValue(p.ConnectionType),
+		Project:        clients.StringValue(p.Project),
+		Insecure:       p.Insecure != nil && *p.Insecure,
+	}
+	for _, s := range []struct {
+		ref *xpv1.SecretKeySelector
+		out *string
+	}{
+		{ref: p.UsernameSecretRef, out: &r.Username},
+		{ref: p.PasswordSecretRef, out: &r.Password},
+		{ref: p.SSHPrivateKeySecretRef, out: &r.SshPrivateKey},
+	} {
+		v, err := clients.SecretValue(ctx, c.kube, s.ref)
+		if err != nil {
+			return nextgen.RepositoriesRepository{}, err
+		}
+		*s.out = v
+	}
+	return r, nil
+}
+
+// accessQuery returns a query that tests access to the supplied repository.
+func accessQuery(r nextgen.RepositoriesRepository) nextgen.RepositoriesRepoAccessQuery {
+	return nextgen.RepositoriesRepoAccessQuery{
+		Repo:          r.Repo,
+		Username:      r.Username,
+		Password:      r.Password,
+		SshPrivateKey: r.SshPrivateKey,
+		Insecure:      r.Insecure,
+		Type_:         r.Type_,
+		Name:          r.Name,
+		Project:       r.Project,
+	}
+}
+
+// upToDate returns true if the observed repository matches the supplied
+// parameters. Credentials are not compared, since Harness does not return
+// them.
+func upToDate(p v1alpha1.RepositoryParameters, observed nextgen.RepositoriesRepository) bool {
+	switch {
+	case p.URL != observed.Repo:
+		return false
+	case p.Type != nil && *p.Type != observed.Type_:
+		return false
+	case p.Name != nil && *p.Name != observed.Name:
+		return false
+	case p.ConnectionType != nil && *p.ConnectionType != observed.ConnectionType:
+		return false
+	case p.Project != nil && *p.Project != observed.Project:
+		return false
+	case p.Insecure != nil && *p.Insecure != observed.Insecure:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const url = "https://github.com/argoproj/argocd-example-apps.git"
+
+// harness returns a handler that serves the supplied repository and
+// connection state. Connection tests fail the test if validate is empty.
+func harness(t *testing.T, repo, validate string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/validate") {
+			if validate == "" {
+				t.Errorf("unexpected connection test")
+			}
+			_, _ = w.Write([]byte(validate))
+			return
+		}
+		_, _ = w.Write([]byte(repo))
+	}
+}
+
+func repository(o v1alpha1.RepositoryObservation) *v1alpha1.Repository {
+	cr := &v1alpha1.Repository{Spec: v1alpha1.RepositorySpec{ForProvider: v1alpha1.RepositoryParameters{
+		AccountIdentifier: "account",
+		AgentIdentifier:   "agent",
+		Identifier:        "guestbook",
+		URL:               url,
+	}}}
+	cr.Status.AtProvider = o
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	recently := metav1.NewTime(time.Now().Add(-time.Minute * 5))
+	found := `{"identifier":"guestbook","repository":{"repo":"` + url + `"}}`
+	untested := xpv1.Condition{Type: v1alpha1.TypeUnreachable, Status: corev1.ConditionUnknown}
+
+	type want struct {
+		o       managed.ExternalObservation
+		c       xpv1.Condition
+		message string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler func(t *testing.T) http.HandlerFunc
+		cr      *v1alpha1.Repository
+		want    want
+	}{
+		"NotFound": {
+			reason: "A repository Harness does not know should be reported as absent.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) }
+			},
+			cr:   repository(v1alpha1.RepositoryObservation{}),
+			want: want{o: managed.ExternalObservation{ResourceExists: false}, c: untested},
+		},
+		"GetError": {
+			reason: "Errors other than not found should be returned rather than reported as absent.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusInternalServerError) }
+			},
+			cr:   repository(v1alpha1.RepositoryObservation{}),
+			want: want{err: errors.Wrap(errors.New("500 Internal Server Error"), errGetRepository), c: untested},
+		},
+		"RecentlyTested": {
+			reason: "A recently successful connection test should not be repeated.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return harness(t, found, "")
+			},
+			cr: repository(v1alpha1.RepositoryObservation{LastConnectionTestTime: &recently, LastSuccessfulConnectionTestTime: &recently}),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c: untested,
+			},
+		},
+		"Unreachable": {
+			reason: "A failed connection test should be recorded and reported as unreachable.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return harness(t, found, `{"status":"Failed","message":"authentication required"}`)
+			},
+			cr: repository(v1alpha1.RepositoryObservation{}),
+			want: want{
+				o:       managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c:       v1alpha1.Unreachable("authentication required"),
+				message: "authentication required",
+			},
+		},
+		"TransientConnectionTestError": {
+			reason: "A connection test Harness could not run because of a transient error should be returned rather than recorded.",
+			handler: func(_ *testing.T) http.HandlerFunc {
+				return func(w http.ResponseWriter, r *http.Request) {
+					if strings.HasSuffix(r.URL.Path, "/validate") {
+						w.WriteHeader(http.StatusServiceUnavailable)
+						return
+					}
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(found))
+				}
+			},
+			cr:   repository(v1alpha1.RepositoryObservation{}),
+			want: want{err: errors.Wrap(errors.New("503 Service Unavailable"), errTestConnection), c: untested},
+		},
+		"OutOfDate": {
+			reason: "A repository with a different URL should not be up to date.",
+			handler: func(t *testing.T) http.HandlerFunc {
+				return harness(t, `{"identifier":"guestbook","repository":{"repo":"https://example.org/other.git"}}`, `{"status":"Successful"}`)
+			},
+			cr: repository(v1alpha1.RepositoryObservation{}),
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				c: v1alpha1.Reachable(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler(t))}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, tc.cr.GetCondition(v1alpha1.TypeUnreachable), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.message, tc.cr.Status.AtProvider.ConnectionMessage); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want message, +got message:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	var validated nextgen.RepositoriesRepoAccessQuery
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/validate") {
+			_ = json.NewDecoder(r.Body).Decode(&validated)
+			_, _ = w.Write([]byte(`{"status":"Successful"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"identifier":"guestbook"}`))
+	}
+
+	cr := repository(v1alpha1.RepositoryObservation{})
+	cr.Spec.ForProvider.PasswordSecretRef = &xpv1.SecretKeySelector{
+		SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: "creds"},
+		Key:             "password",
+	}
+	kube := &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+		obj.(*corev1.Secret).Data = map[string][]byte{"password": []byte("token")}
+		return nil
+	}}
+
+	e := external{service: clientstest.NewService(t, handler), kube: kube}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %s", err)
+	}
+	if diff := cmp.Diff(v1alpha1.Reachable(), cr.GetCondition(v1alpha1.TypeUnreachable), test.EquateConditions()); diff != "" {
+		t.Errorf("e.Create(...): -want condition, +got condition:\n%s", diff)
+	}
+	if cr.Status.AtProvider.LastSuccessfulConnectionTestTime == nil {
+		t.Errorf("e.Create(...): want the successful connection test to be recorded")
+	}
+	want := nextgen.RepositoriesRepoAccessQuery{Repo: url, Password: "token"}
+	if diff := cmp.Diff(want, validated); diff != "" {
+		t.Errorf("e.Create(...): -want access query, +got access query:\n%s", diff)
+	}
+}
+
+func TestConnectionTestDue(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) *metav1.Time {
+		t := metav1.NewTime(now.Add(-d))
+		return &t
+	}
+
+	cases := map[string]struct {
+		reason string
+		o      v1alpha1.RepositoryObservation
+		want   bool
+	}{
+		"NeverTested": {
+			reason: "A connection that was never tested should be tested.",
+			want:   true,
+		},
+		"RecentSuccess": {
+			reason: "A connection that recently tested successfully should not be tested.",
+			o:      v1alpha1.RepositoryObservation{LastConnectionTestTime: ago(time.Minute * 10), LastSuccessfulConnectionTestTime: ago(time.Minute * 10)},
+			want:   false,
+		},
+		"StaleSuccess": {
+			reason: "A connection that last tested successfully long ago should be tested.",
+			o:      v1alpha1.RepositoryObservation{LastConnectionTestTime: ago(2 * time.Hour), LastSuccessfulConnectionTestTime: ago(2 * time.Hour)},
+			want:   true,
+		},
+		"RecentFailure": {
+			reason: "A connection that just failed should not be retested immediately.",
+			o:      v1alpha1.RepositoryObservation{LastConnectionTestTime: ago(time.Second)},
+			want:   false,
+		},
+		"EarlierFailure": {
+			reason: "A connection that failed a while ago should be retested.",
+			o:      v1alpha1.RepositoryObservation{LastConnectionTestTime: ago(2 * time.Minute)},
+			want:   true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, connectionTestDue(tc.o, now)); diff != "" {
+				t.Errorf("\n%s\nconnectionTestDue(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package repositorycertificate contains the controller of GitOps
+// RepositoryCertificate managed resources.
+package repositorycertificate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/antihax/optional"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+	"github.com/crossplane/provider-harness/internal/controller/options"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+	"github.com/crossplane/provider-harness/internal/features"
+	"github.com/crossplane/provider-harness/internal/status"
+	"github.com/crossplane/provider-harness/internal/throttle"
+)
+
+const (
+	errNotRepositoryCertificate = "managed resource is not a RepositoryCertificate custom resource"
+
+	errListCertificates  = "cannot list repository certificates"
+	errCreateCertificate = "cannot create repository certificate"
+	errUpdateCertificate = "cannot update repository certificate"
+	errDeleteCertificate = "cannot delete repository certificate"
+)
+
+// Setup adds a controller that reconciles RepositoryCertificate managed
+// resources.
+func Setup(mgr ctrl.Manager, o options.Options) error {
+	name := o.ControllerName(managed.ControllerName(v1alpha1.RepositoryCertificateGroupKind))
+
+	r := managed.NewReconciler(status.SkipNoopUpdates(mgr),
+		resource.ManagedKind(v1alpha1.RepositoryCertificateGroupVersionKind),
+		managed.WithExternalConnecter(throttle.NewConnecter(deadletter.NewConnecter(&connector{
+			harness: clients.NewConnector(mgr.GetClient(), o.Features.Enabled(features.EnableAlphaExternalSecretStores)),
+		}, o.ReconcileFailureThreshold), throttle.NewLimiter(o.MaxReconcilesPerMinute))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollIntervalFor(v1alpha1.RepositoryCertificateKind)),
+		o.ManagementPolicies(),
+		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		WithEventFilter(resource.DesiredStateChanged()).
+		For(&v1alpha1.RepositoryCertificate{}).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	harness *clients.Connector
+}
+
+// Connect produces an ExternalClient authenticated using the credentials of
+// the RepositoryCertificate's ProviderConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.RepositoryCertificate); !ok {
+		return nil, errors.New(errNotRepositoryCertificate)
+	}
+
+	svc, err := c.harness.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &external{service: svc}, nil
+}
+
+// An external observes, then either registers or deregisters a repository
+// certificate to ensure it reflects the RepositoryCertificate's desired
+// state. Harness has no API to update a certificate, so updates replace it.
+type external struct {
+	service *clients.Service
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.RepositoryCertificate)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotRepositoryCertificate)
+	}
+	p := cr.Spec.ForProvider
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	rsp, response, err := c.service.RepositoryCertificatesApi.AgentCertificateServiceList(c.service.Authorize(ctx), p.AgentIdentifier, p.AccountIdentifier,
+		&nextgen.RepositoryCertificatesApiAgentCertificateServiceListOpts{
+			OrgIdentifier:        org,
+			ProjectIdentifier:    project,
+			QueryHostNamePattern: optional.NewString(p.ServerName),
+			QueryCertType:        optional.NewString(p.CertType),
+			QueryCertSubType:     clients.OptionalString(p.CertSubType),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errListCertificates)
+	}
+
+	cert, ok := find(p, rsp.Items)
+	if !ok {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider.CertInfo = cert.CertInfo
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: upToDate(p, cert),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.RepositoryCertificate)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotRepositoryCertificate)
+	}
+
+	if err := c.register(ctx, cr.Spec.ForProvider, false); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCertificate)
+	}
+
+	cr.SetConditions(xpv1.Creating())
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.RepositoryCertificate)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotRepositoryCertificate)
+	}
+
+	if err := c.register(ctx, cr.Spec.ForProvider, true); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCertificate)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.RepositoryCertificate)
+	if !ok {
+		return errors.New(errNotRepositoryCertificate)
+	}
+	p := cr.Spec.ForProvider
+	cr.SetConditions(xpv1.Deleting())
+
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.RepositoryCertificatesApi.AgentCertificateServiceDelete(c.service.Authorize(ctx), p.AgentIdentifier,
+		&nextgen.RepositoryCertificatesApiAgentCertificateServiceDeleteOpts{
+			AccountIdentifier:    optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:        org,
+			ProjectIdentifier:    project,
+			QueryHostNamePattern: optional.NewString(p.ServerName),
+			QueryCertType:        optional.NewString(p.CertType),
+			QueryCertSubType:     clients.OptionalString(p.CertSubType),
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	if clients.IsNotFound(response) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteCertificate)
+}
+
+// register registers the certificate with the supplied parameters, replacing
+// any certificate of the same server and type if upsert is true.
+func (c *external) register(ctx context.Context, p v1alpha1.RepositoryCertificateParameters, upsert bool) error {
+	org, project := clients.ScopeOpts(p.OrgIdentifier, p.ProjectIdentifier)
+	_, response, err := c.service.RepositoryCertificatesApi.AgentCertificateServiceCreate(c.service.Authorize(ctx),
+		nextgen.CertificateRepositoryCertificateCreateRequest{
+			Certificates: &nextgen.Applicationv1alpha1RepositoryCertificateList{Items: []nextgen.Applicationv1alpha1RepositoryCertificate{{
+				ServerName:  p.ServerName,
+				CertType:    p.CertType,
+				CertSubType: clients.StringValue(p.CertSubType),
+				CertData:    p.CertData,
+			}}},
+			Upsert: upsert,
+		}, p.AgentIdentifier,
+		&nextgen.RepositoryCertificatesApiAgentCertificateServiceCreateOpts{
+			AccountIdentifier: optional.NewString(p.AccountIdentifier),
+			OrgIdentifier:     org,
+			ProjectIdentifier: project,
+		})
+	if response != nil {
+		_ = response.Body.Close()
+	}
+	return err
+}
+
+// find returns the certificate matching the supplied parameters. A server may
+// have several SSH known host keys, so one with the desired data is preferred.
+func find(p v1alpha1.RepositoryCertificateParameters, certs []nextgen.CertificatesRepositoryCertificate) (nextgen.CertificatesRepositoryCertificate, bool) {
+	var found *nextgen.CertificatesRepositoryCertificate
+	for i := range certs {
+		cert := &certs[i]
+		if cert.ServerName != p.ServerName || cert.CertType != p.CertType {
+			continue
+		}
+		if p.CertSubType != nil && cert.CertSubType != *p.CertSubType {
+			continue
+		}
+		if sameData(p.CertData, cert.CertData) {
+			return *cert, true
+		}
+		if found == nil {
+			found = cert
+		}
+	}
+	if found == nil {
+		return nextgen.CertificatesRepositoryCertificate{}, false
+	}
+	return *found, true
+}
+
+// upToDate returns true if the observed certificate matches the supplied
+// parameters. Harness does not return the data of every certificate, in
+// which case it is assumed to be up to date.
+func upToDate(p v1alpha1.RepositoryCertificateParameters, observed nextgen.CertificatesRepositoryCertificate) bool {
+	return observed.CertData == "" || sameData(p.CertData, observed.CertData)
+}
+
+// sameData returns true if the supplied certificate data are equal, ignoring
+// surrounding whitespace.
+func sameData(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
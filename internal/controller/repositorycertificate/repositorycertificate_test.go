@@ -0,0 +1,234 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repositorycertificate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/harness/harness-go-sdk/harness/nextgen"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients/clientstest"
+)
+
+const (
+	serverName = "git.example.org"
+	pem        = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----"
+)
+
+// certificates returns a list response holding the supplied certificates.
+func certificates(t *testing.T, certs ...nextgen.CertificatesRepositoryCertificate) string {
+	t.Helper()
+	return clientstest.JSON(t, nextgen.CertificatesRepositoryCertificateList{Items: certs})
+}
+
+func certificate() *v1alpha1.RepositoryCertificate {
+	return &v1alpha1.RepositoryCertificate{Spec: v1alpha1.RepositoryCertificateSpec{ForProvider: v1alpha1.RepositoryCertificateParameters{
+		AccountIdentifier: "account",
+		AgentIdentifier:   "agent",
+		ServerName:        serverName,
+		CertType:          "https",
+		CertData:          pem,
+	}}}
+}
+
+func TestObserve(t *testing.T) {
+	unknown := xpv1.Condition{Type: xpv1.TypeReady, Status: corev1.ConditionUnknown}
+
+	type want struct {
+		o   managed.ExternalObservation
+		c   xpv1.Condition
+		obs v1alpha1.RepositoryCertificateObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		body   func(t *testing.T) string
+		status int
+		want   want
+	}{
+		"NotRegistered": {
+			reason: "A certificate Harness does not list should be reported as absent.",
+			status: http.StatusOK,
+			body: func(t *testing.T) string {
+				return certificates(t, nextgen.CertificatesRepositoryCertificate{ServerName: "other.example.org", CertType: "https"})
+			},
+			want: want{o: managed.ExternalObservation{ResourceExists: false}, c: unknown},
+		},
+		"ListError": {
+			reason: "Errors listing certificates should be returned rather than reported as absent.",
+			status: http.StatusInternalServerError,
+			body:   func(_ *testing.T) string { return "" },
+			want:   want{c: unknown, err: errors.Wrap(errors.New("500 Internal Server Error"), errListCertificates)},
+		},
+		"UpToDate": {
+			reason: "A registered certificate with the desired data should be up to date and record its info.",
+			status: http.StatusOK,
+			body: func(t *testing.T) string {
+				return certificates(t, nextgen.CertificatesRepositoryCertificate{ServerName: serverName, CertType: "https", CertData: pem + "\n", CertInfo: "CN=git.example.org"})
+			},
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				c:   xpv1.Available(),
+				obs: v1alpha1.RepositoryCertificateObservation{CertInfo: "CN=git.example.org"},
+			},
+		},
+		"OutOfDate": {
+			reason: "A registered certificate with different data should not be up to date.",
+			status: http.StatusOK,
+			body: func(t *testing.T) string {
+				return certificates(t, nextgen.CertificatesRepositoryCertificate{ServerName: serverName, CertType: "https", CertData: "other"})
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				c: xpv1.Available(),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := certificate()
+			e := external{service: clientstest.NewService(t, clientstest.Respond(tc.status, tc.body(t)))}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, cr.GetCondition(xpv1.TypeReady), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	var registered nextgen.CertificateRepositoryCertificateCreateRequest
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&registered)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}
+
+	e := external{service: clientstest.NewService(t, handler)}
+	if _, err := e.Update(context.Background(), certificate()); err != nil {
+		t.Fatalf("e.Update(...): %s", err)
+	}
+	want := nextgen.CertificateRepositoryCertificateCreateRequest{
+		Certificates: &nextgen.Applicationv1alpha1RepositoryCertificateList{Items: []nextgen.Applicationv1alpha1RepositoryCertificate{{
+			ServerName: serverName,
+			CertType:   "https",
+			CertData:   pem,
+		}}},
+		Upsert: true,
+	}
+	if diff := cmp.Diff(want, registered); diff != "" {
+		t.Errorf("e.Update(...): -want request, +got request:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		want    error
+	}{
+		"Deleted": {
+			reason:  "A deregistered certificate should be reported as deleted.",
+			handler: clientstest.Respond(http.StatusOK, `{}`),
+		},
+		"AlreadyDeleted": {
+			reason:  "A certificate Harness no longer knows should be treated as deleted.",
+			handler: clientstest.Respond(http.StatusNotFound, ""),
+		},
+		"Error": {
+			reason:  "Other errors deregistering the certificate should be returned.",
+			handler: clientstest.Respond(http.StatusBadRequest, ""),
+			want:    errors.Wrap(errors.New("400 Bad Request"), errDeleteCertificate),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{service: clientstest.NewService(t, tc.handler)}
+			err := e.Delete(context.Background(), certificate())
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	rsa, ecdsa := "ssh-rsa", "ecdsa-sha2-nistp256"
+	keys := []nextgen.CertificatesRepositoryCertificate{
+		{ServerName: serverName, CertType: "ssh", CertSubType: rsa, CertData: "AAAA-rsa"},
+		{ServerName: serverName, CertType: "ssh", CertSubType: ecdsa, CertData: "AAAA-ecdsa"},
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.RepositoryCertificateParameters
+		want   string
+		found  bool
+	}{
+		"SubType": {
+			reason: "The key of the desired sub type should be found.",
+			p:      v1alpha1.RepositoryCertificateParameters{ServerName: serverName, CertType: "ssh", CertSubType: &ecdsa},
+			want:   "AAAA-ecdsa",
+			found:  true,
+		},
+		"SameData": {
+			reason: "Without a sub type, the key with the desired data should be preferred.",
+			p:      v1alpha1.RepositoryCertificateParameters{ServerName: serverName, CertType: "ssh", CertData: "AAAA-ecdsa"},
+			want:   "AAAA-ecdsa",
+			found:  true,
+		},
+		"OtherType": {
+			reason: "A certificate of another type should not be found.",
+			p:      v1alpha1.RepositoryCertificateParameters{ServerName: serverName, CertType: "https"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, found := find(tc.p, keys)
+			if diff := cmp.Diff(tc.found, found); diff != "" {
+				t.Errorf("\n%s\nfind(...): -want found, +got found:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got.CertData); diff != "" {
+				t.Errorf("\n%s\nfind(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
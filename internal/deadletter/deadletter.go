@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deadletter flags managed resources that fail to reconcile
+// repeatedly, so that chronically broken resources can be found by their
+// conditions rather than retrying unnoticed.
+package deadletter
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// DefaultThreshold is the default number of consecutive failed reconciles
+// after which a resource is flagged.
+const DefaultThreshold = 5
+
+const msgReconcileFailing = "%d consecutive reconciles failed, last error: %s"
+
+// A FailureCounter is a managed resource that counts its consecutive failed
+// reconciles.
+type FailureCounter interface {
+	resource.Managed
+
+	GetConsecutiveFailures() int64
+	SetConsecutiveFailures(n int64)
+}
+
+// NewConnecter wraps the supplied connecter such that resources are flagged
+// with a ReconcileFailing condition once they fail to connect or to operate
+// on their external resource threshold times in a row. The condition is
+// cleared by the next reconcile that succeeds as a whole. Failed resources are still
+// retried with the reconciler's usual capped backoff. Resources that are not
+// a FailureCounter are not tracked.
+func NewConnecter(c managed.ExternalConnecter, threshold int64) managed.ExternalConnecter {
+	return &connecter{ExternalConnecter: c, threshold: threshold}
+}
+
+type connecter struct {
+	managed.ExternalConnecter
+	threshold int64
+}
+
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	ec, err := c.ExternalConnecter.Connect(ctx, mg)
+	if err != nil {
+		failed(mg, err, c.threshold)
+		return nil, err
+	}
+	return &external{ExternalClient: ec, threshold: c.threshold}, nil
+}
+
+type external struct {
+	managed.ExternalClient
+	threshold int64
+}
+
+func (e *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.ExternalClient.Observe(ctx, mg)
+	switch {
+	case err != nil:
+		failed(mg, err, e.threshold)
+	case settled(mg, o):
+		succeeded(mg)
+	}
+	return o, err
+}
+
+func (e *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.ExternalClient.Create(ctx, mg)
+	record(mg, err, e.threshold)
+	return c, err
+}
+
+func (e *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.ExternalClient.Update(ctx, mg)
+	record(mg, err, e.threshold)
+	return u, err
+}
+
+func (e *external) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.ExternalClient.Delete(ctx, mg)
+	record(mg, err, e.threshold)
+	return err
+}
+
+// settled returns true if a reconcile ends after the supplied successful
+// observation, without creating, updating or deleting the external resource.
+// A reconcile that goes on to do so has not yet succeeded, so its failure
+// count must survive the observation.
+func settled(mg resource.Managed, o managed.ExternalObservation) bool {
+	if meta.WasDeleted(mg) {
+		return !o.ResourceExists
+	}
+	return o.ResourceExists && (o.ResourceUpToDate || mg.GetManagementPolicy() == xpv1.ManagementObserveOnly)
+}
+
+func record(mg resource.Managed, err error, threshold int64) {
+	if err != nil {
+		failed(mg, err, threshold)
+		return
+	}
+	succeeded(mg)
+}
+
+func failed(mg resource.Managed, err error, threshold int64) {
+	fc, ok := mg.(FailureCounter)
+	if !ok {
+		return
+	}
+	n := fc.GetConsecutiveFailures() + 1
+	fc.SetConsecutiveFailures(n)
+	if threshold > 0 && n >= threshold {
+		fc.SetConditions(v1alpha1.ReconcileFailing(fmt.Sprintf(msgReconcileFailing, n, err)))
+	}
+}
+
+func succeeded(mg resource.Managed) {
+	fc, ok := mg.(FailureCounter)
+	if !ok {
+		return
+	}
+	fc.SetConsecutiveFailures(0)
+	if fc.GetCondition(v1alpha1.TypeReconcileFailing).Status == corev1.ConditionTrue {
+		fc.SetConditions(v1alpha1.ReconcileRecovered())
+	}
+}
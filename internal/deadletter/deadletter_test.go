@@ -0,0 +1,168 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package deadletter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestObserve(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	agent := func(failures int64, c ...xpv1.Condition) *v1alpha1.Agent {
+		cr := &v1alpha1.Agent{}
+		cr.SetConsecutiveFailures(failures)
+		cr.SetConditions(c...)
+		return cr
+	}
+
+	settled := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Agent
+		o      managed.ExternalObservation
+		err    error
+		want   *v1alpha1.Agent
+	}{
+		"BelowThreshold": {
+			reason: "A failure below the threshold should only be counted.",
+			cr:     agent(1),
+			err:    errBoom,
+			want:   agent(2),
+		},
+		"ReachedThreshold": {
+			reason: "A resource should be flagged once it reaches the threshold.",
+			cr:     agent(2),
+			err:    errBoom,
+			want:   agent(3, v1alpha1.ReconcileFailing("3 consecutive reconciles failed, last error: boom")),
+		},
+		"Succeeded": {
+			reason: "An observation that ends the reconcile should reset the count.",
+			cr:     agent(2),
+			o:      settled,
+			want:   agent(0),
+		},
+		"NeedsUpdate": {
+			reason: "An observation after which the resource still needs an update should not reset the count.",
+			cr:     agent(2),
+			o:      managed.ExternalObservation{ResourceExists: true},
+			want:   agent(2),
+		},
+		"NeedsCreate": {
+			reason: "An observation after which the resource still needs to be created should not reset the count.",
+			cr:     agent(2),
+			want:   agent(2),
+		},
+		"Recovered": {
+			reason: "A success should clear the condition of a flagged resource.",
+			cr:     agent(7, v1alpha1.ReconcileFailing("7 consecutive reconciles failed, last error: boom")),
+			o:      settled,
+			want:   agent(0, v1alpha1.ReconcileRecovered()),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{
+					ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+						return tc.o, tc.err
+					},
+				}, nil
+			}), 3)
+			e, err := c.Connect(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("Connect(...): %v", err)
+			}
+			_, err = e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, tc.cr, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	errBoom := errors.New("boom")
+	cr := &v1alpha1.Agent{}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return nil, errBoom
+	}), 1)
+	_, err := c.Connect(context.Background(), cr)
+	if diff := cmp.Diff(errBoom, err, test.EquateErrors()); diff != "" {
+		t.Errorf("Connect(...): -want error, +got error:\n%s", diff)
+	}
+	want := &v1alpha1.Agent{}
+	want.SetConsecutiveFailures(1)
+	want.SetConditions(v1alpha1.ReconcileFailing("1 consecutive reconciles failed, last error: boom"))
+	if diff := cmp.Diff(want, cr, test.EquateConditions()); diff != "" {
+		t.Errorf("Connect(...): -want, +got:\n%s", diff)
+	}
+}
+
+// TestUpdateFailing asserts that a resource whose Update fails on every
+// reconcile is flagged, although each reconcile observes it successfully
+// first.
+func TestUpdateFailing(t *testing.T) {
+	errBoom := errors.New("boom")
+	cr := &v1alpha1.Agent{}
+	c := NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return &managed.ExternalClientFns{
+			ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				return managed.ExternalObservation{ResourceExists: true}, nil
+			},
+			UpdateFn: func(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+				return managed.ExternalUpdate{}, errBoom
+			},
+		}, nil
+	}), 3)
+
+	for i := 0; i < 3; i++ {
+		e, err := c.Connect(context.Background(), cr)
+		if err != nil {
+			t.Fatalf("Connect(...): %v", err)
+		}
+		if _, err := e.Observe(context.Background(), cr); err != nil {
+			t.Fatalf("e.Observe(...): %v", err)
+		}
+		if _, err := e.Update(context.Background(), cr); !errors.Is(err, errBoom) {
+			t.Fatalf("e.Update(...): want %v, got %v", errBoom, err)
+		}
+	}
+
+	want := &v1alpha1.Agent{}
+	want.SetConsecutiveFailures(3)
+	want.SetConditions(v1alpha1.ReconcileFailing("3 consecutive reconciles failed, last error: boom"))
+	if diff := cmp.Diff(want, cr, test.EquateConditions()); diff != "" {
+		t.Errorf("e.Update(...): -want, +got:\n%s", diff)
+	}
+}
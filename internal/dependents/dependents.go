@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dependents wires managed resources that depend on an Agent to it
+// via owner references, so that they are garbage collected along with it.
+package dependents
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/features"
+)
+
+const (
+	errListAgents      = "cannot list Agents"
+	errUpdateOwner     = "cannot add Agent owner reference"
+	errListDependents  = "cannot list dependents of Agent"
+	errDeleteDependent = "cannot delete dependent of Agent"
+)
+
+var (
+	mu    sync.RWMutex
+	kinds []schema.GroupVersionKind
+)
+
+// Register records the list kind of a managed resource that may depend on
+// an Agent. Controllers of dependent kinds call Register during setup.
+func Register(list schema.GroupVersionKind) {
+	mu.Lock()
+	defer mu.Unlock()
+	kinds = append(kinds, list)
+}
+
+func registered() []schema.GroupVersionKind {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]schema.GroupVersionKind(nil), kinds...)
+}
+
+// An AgentReferencer returns the account and identifier of the Harness agent
+// the supplied managed resource depends on, or empty strings if it does not
+// depend on one.
+type AgentReferencer func(mg resource.Managed) (account, identifier string)
+
+// WithOwnerReferences returns a reconciler option that, if garbage collection
+// of Agent dependents is enabled, makes managed resources of the supplied kind
+// owned by the Agents they depend on, and registers the kind so that they are
+// deleted along with their Agent. It does nothing otherwise.
+func WithOwnerReferences(kube client.Client, f *feature.Flags, gvk schema.GroupVersionKind, ref AgentReferencer) managed.ReconcilerOption {
+	if !f.Enabled(features.EnableAlphaAgentDependentGC) {
+		return func(_ *managed.Reconciler) {}
+	}
+	Register(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+	return managed.WithInitializers(managed.NewNameAsExternalName(kube), NewOwnerReferenceInitializer(kube, ref))
+}
+
+// NewOwnerReferenceInitializer returns an Initializer that makes a managed
+// resource owned by the Agent that manages the Harness agent it depends on.
+// A resource whose agent is not managed by an Agent is left alone. Owner
+// references are only ever added, so a resource that later stops
+// referencing an Agent is not orphaned mid-flight.
+func NewOwnerReferenceInitializer(kube client.Client, ref AgentReferencer) managed.InitializerFn {
+	return func(ctx context.Context, mg resource.Managed) error {
+		account, id := ref(mg)
+		if id == "" {
+			return nil
+		}
+		l := &v1alpha1.AgentList{}
+		if err := kube.List(ctx, l); err != nil {
+			return errors.Wrap(err, errListAgents)
+		}
+		a := owner(l.Items, account, id)
+		if a == nil {
+			return nil
+		}
+		or := meta.AsOwner(meta.TypedReferenceTo(a, v1alpha1.AgentGroupVersionKind))
+		block := true
+		or.BlockOwnerDeletion = &block
+		if hasOwner(mg, or.UID) {
+			return nil
+		}
+		meta.AddOwnerReference(mg, or)
+		return errors.Wrap(kube.Update(ctx, mg), errUpdateOwner)
+	}
+}
+
+// Delete requests deletion of every registered dependent owned by the
+// supplied Agent and returns how many of them still exist. Callers should not
+// delete the external agent until no dependents remain, so that Harness
+// entities are removed in dependency order.
+func Delete(ctx context.Context, kube client.Client, owner metav1.Object) (int, error) {
+	remaining := 0
+	for _, gvk := range registered() {
+		l := &unstructured.UnstructuredList{}
+		l.SetGroupVersionKind(gvk)
+		if err := kube.List(ctx, l); err != nil {
+			return 0, errors.Wrap(err, errListDependents)
+		}
+		for i := range l.Items {
+			d := &l.Items[i]
+			if !hasOwner(d, owner.GetUID()) {
+				continue
+			}
+			remaining++
+			if meta.WasDeleted(d) {
+				continue
+			}
+			if err := kube.Delete(ctx, d); resource.Ignore(kerrors.IsNotFound, err) != nil {
+				return 0, errors.Wrap(err, errDeleteDependent)
+			}
+		}
+	}
+	return remaining, nil
+}
+
+// owner returns the Agent that manages the Harness agent with the supplied
+// account and identifier, or nil if none does.
+func owner(agents []v1alpha1.Agent, account, id string) *v1alpha1.Agent {
+	for i := range agents {
+		a := &agents[i]
+		p := a.Spec.ForProvider
+		if p.AccountIdentifier == nil || *p.AccountIdentifier != account {
+			continue
+		}
+		// Like the Agent controller, prefer the requested identifier over
+		// the external name.
+		aid := meta.GetExternalName(a)
+		if p.Identifier != nil && *p.Identifier != "" {
+			aid = *p.Identifier
+		}
+		if aid == id {
+			return a
+		}
+	}
+	return nil
+}
+
+func hasOwner(o metav1.Object, uid types.UID) bool {
+	for _, r := range o.GetOwnerReferences() {
+		if r.UID == uid {
+			return true
+		}
+	}
+	return false
+}
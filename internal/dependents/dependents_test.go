@@ -0,0 +1,197 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dependents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/feature"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/features"
+)
+
+func TestDelete(t *testing.T) {
+	errBoom := errors.New("boom")
+	owner := &v1alpha1.Agent{ObjectMeta: metav1.ObjectMeta{Name: "agent", UID: "owner-uid"}}
+
+	dependent := func(name, ownerUID string, deleting bool) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetName(name)
+		u.SetOwnerReferences([]metav1.OwnerReference{{UID: types.UID(ownerUID)}})
+		if deleting {
+			now := metav1.Now()
+			u.SetDeletionTimestamp(&now)
+		}
+		return u
+	}
+
+	type want struct {
+		remaining int
+		deleted   []string
+		err       error
+	}
+
+	cases := map[string]struct {
+		reason string
+		items  []unstructured.Unstructured
+		list   error
+		want   want
+	}{
+		"NoDependents": {
+			reason: "Resources owned by another Agent should be left alone.",
+			items:  []unstructured.Unstructured{dependent("a", "other-uid", false)},
+			want:   want{},
+		},
+		"DeleteOwned": {
+			reason: "Owned resources should be deleted and counted as remaining.",
+			items: []unstructured.Unstructured{
+				dependent("a", "owner-uid", false),
+				dependent("b", "owner-uid", true),
+				dependent("c", "other-uid", false),
+			},
+			want: want{remaining: 2, deleted: []string{"a"}},
+		},
+		"ListError": {
+			reason: "Errors listing dependents should be returned.",
+			list:   errBoom,
+			want:   want{err: errors.Wrap(errBoom, errListDependents)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mu.Lock()
+			kinds = []schema.GroupVersionKind{{Group: "example.org", Version: "v1", Kind: "DependentList"}}
+			mu.Unlock()
+
+			var deleted []string
+			kube := &test.MockClient{
+				MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+					obj.(*unstructured.UnstructuredList).Items = tc.items
+					return tc.list
+				},
+				MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+					deleted = append(deleted, obj.GetName())
+					return nil
+				},
+			}
+
+			got, err := Delete(context.Background(), kube, owner)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.remaining, got); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want remaining, +got remaining:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\nDelete(...): -want deleted, +got deleted:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestGarbageCollection makes Repositories owned by the Agent they depend on,
+// as the Repository controller does, then deletes that Agent's dependents, as
+// the Agent controller does before deleting its agent.
+func TestGarbageCollection(t *testing.T) {
+	account, id := "account", "agent"
+	a := v1alpha1.Agent{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", UID: "agent-uid"},
+		Spec:       v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account, Identifier: &id}},
+	}
+	repos := map[string]*v1alpha1.Repository{
+		"guestbook": {
+			ObjectMeta: metav1.ObjectMeta{Name: "guestbook"},
+			Spec:       v1alpha1.RepositorySpec{ForProvider: v1alpha1.RepositoryParameters{AccountIdentifier: account, AgentIdentifier: id}},
+		},
+		"other": {
+			ObjectMeta: metav1.ObjectMeta{Name: "other"},
+			Spec:       v1alpha1.RepositorySpec{ForProvider: v1alpha1.RepositoryParameters{AccountIdentifier: account, AgentIdentifier: "other"}},
+		},
+	}
+
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *v1alpha1.AgentList:
+				l.Items = []v1alpha1.Agent{a}
+			case *unstructured.UnstructuredList:
+				for _, r := range repos {
+					u := unstructured.Unstructured{}
+					u.SetName(r.GetName())
+					u.SetOwnerReferences(r.GetOwnerReferences())
+					l.Items = append(l.Items, u)
+				}
+			}
+			return nil
+		},
+		MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+			repos[obj.GetName()] = obj.(*v1alpha1.Repository)
+			return nil
+		},
+		MockDelete: func(_ context.Context, obj client.Object, _ ...client.DeleteOption) error {
+			delete(repos, obj.GetName())
+			return nil
+		},
+	}
+
+	mu.Lock()
+	kinds = nil
+	mu.Unlock()
+	f := &feature.Flags{}
+	f.Enable(features.EnableAlphaAgentDependentGC)
+	ref := func(mg resource.Managed) (string, string) {
+		p := mg.(*v1alpha1.Repository).Spec.ForProvider
+		return p.AccountIdentifier, p.AgentIdentifier
+	}
+	WithOwnerReferences(kube, f, v1alpha1.RepositoryGroupVersionKind, ref)(&managed.Reconciler{})
+
+	i := NewOwnerReferenceInitializer(kube, ref)
+	for _, name := range []string{"guestbook", "other"} {
+		if err := i.Initialize(context.Background(), repos[name]); err != nil {
+			t.Fatalf("Initialize(%s): %s", name, err)
+		}
+	}
+
+	n, err := Delete(context.Background(), kube, &a)
+	if err != nil {
+		t.Fatalf("Delete(...): %s", err)
+	}
+	if diff := cmp.Diff(1, n); diff != "" {
+		t.Errorf("Delete(...): -want remaining, +got remaining:\n%s", diff)
+	}
+	remaining := make([]string, 0, len(repos))
+	for name := range repos {
+		remaining = append(remaining, name)
+	}
+	if diff := cmp.Diff([]string{"other"}, remaining); diff != "" {
+		t.Errorf("Delete(...): -want Repositories, +got Repositories:\n%s", diff)
+	}
+}
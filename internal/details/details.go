@@ -0,0 +1,161 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package details renders the connection details of managed resources in
+// additional formats, for workloads that cannot consume raw keys.
+package details
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Connection details formats.
+const (
+	// FormatRaw publishes only the raw connection details.
+	FormatRaw = "Raw"
+
+	// FormatDotenv additionally publishes the connection details as a dotenv
+	// file under KeyDotenv.
+	FormatDotenv = "Dotenv"
+
+	// FormatJSON additionally publishes the connection details as a JSON
+	// object under KeyJSON.
+	FormatJSON = "JSON"
+)
+
+// Keys of rendered connection details.
+const (
+	KeyDotenv = ".env"
+	KeyJSON   = "connection.json"
+)
+
+const (
+	errUnknownFormat = "unknown connection details format %q"
+	errRender        = "cannot render connection details"
+)
+
+// A Formatted resource specifies the format its connection details should be
+// rendered in.
+type Formatted interface {
+	GetConnectionDetailsFormat() string
+}
+
+// Render returns the supplied connection details along with their rendering
+// in the supplied format. The raw details are always included.
+func Render(format string, cd managed.ConnectionDetails) (managed.ConnectionDetails, error) {
+	out := make(managed.ConnectionDetails, len(cd)+1)
+	for k, v := range cd {
+		out[k] = v
+	}
+
+	switch format {
+	case "", FormatRaw:
+	case FormatDotenv:
+		out[KeyDotenv] = dotenv(cd)
+	case FormatJSON:
+		m := make(map[string]string, len(cd))
+		for k, v := range cd {
+			m[k] = string(v)
+		}
+		// Marshalling a map of strings cannot fail.
+		b, _ := json.Marshal(m)
+		out[KeyJSON] = b
+	default:
+		return nil, errors.Errorf(errUnknownFormat, format)
+	}
+	return out, nil
+}
+
+// dotenv renders connection details as sorted KEY="value" lines. Keys are
+// upper cased, with characters not allowed in environment variable names
+// replaced by underscores.
+func dotenv(cd managed.ConnectionDetails) []byte {
+	keys := make([]string, 0, len(cd))
+	for k := range cd {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	b := &strings.Builder{}
+	for _, k := range keys {
+		b.WriteString(envName(k))
+		b.WriteString("=")
+		b.WriteString(strconv.Quote(string(cd[k])))
+		b.WriteString("\n")
+	}
+	return []byte(b.String())
+}
+
+func envName(k string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, k)
+}
+
+// A Publisher renders connection details in the format their resource
+// specifies before publishing them with the wrapped ConnectionPublisher.
+// Error messages never include the details themselves.
+type Publisher struct {
+	managed.ConnectionPublisher
+}
+
+// NewPublisher returns a Publisher that wraps the supplied publisher.
+func NewPublisher(p managed.ConnectionPublisher) *Publisher {
+	return &Publisher{ConnectionPublisher: p}
+}
+
+// PublishConnection renders and publishes the supplied connection details.
+func (p *Publisher) PublishConnection(ctx context.Context, so resource.ConnectionSecretOwner, c managed.ConnectionDetails) (bool, error) {
+	rc, err := render(so, c)
+	if err != nil {
+		return false, err
+	}
+	return p.ConnectionPublisher.PublishConnection(ctx, so, rc)
+}
+
+// UnpublishConnection renders and unpublishes the supplied connection
+// details.
+func (p *Publisher) UnpublishConnection(ctx context.Context, so resource.ConnectionSecretOwner, c managed.ConnectionDetails) error {
+	rc, err := render(so, c)
+	if err != nil {
+		return err
+	}
+	return p.ConnectionPublisher.UnpublishConnection(ctx, so, rc)
+}
+
+func render(so resource.ConnectionSecretOwner, c managed.ConnectionDetails) (managed.ConnectionDetails, error) {
+	f, ok := so.(Formatted)
+	if !ok || len(c) == 0 {
+		return c, nil
+	}
+	rc, err := Render(f.GetConnectionDetailsFormat(), c)
+	return rc, errors.Wrap(err, errRender)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package details
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestRender(t *testing.T) {
+	cd := managed.ConnectionDetails{
+		"token":        []byte("s3cr3t"),
+		"install.yaml": []byte("kind: Namespace\nname: \"harness\"\n"),
+	}
+
+	type want struct {
+		cd  managed.ConnectionDetails
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		format string
+		want   want
+	}{
+		"Unset": {
+			reason: "No format should publish only the raw details.",
+			want:   want{cd: cd},
+		},
+		"Raw": {
+			reason: "The Raw format should publish only the raw details.",
+			format: FormatRaw,
+			want:   want{cd: cd},
+		},
+		"Dotenv": {
+			reason: "The Dotenv format should add sorted, quoted environment variables.",
+			format: FormatDotenv,
+			want: want{cd: managed.ConnectionDetails{
+				"token":        []byte("s3cr3t"),
+				"install.yaml": []byte("kind: Namespace\nname: \"harness\"\n"),
+				KeyDotenv:      []byte("INSTALL_YAML=\"kind: Namespace\\nname: \\\"harness\\\"\\n\"\nTOKEN=\"s3cr3t\"\n"),
+			}},
+		},
+		"JSON": {
+			reason: "The JSON format should add a JSON object of the details.",
+			format: FormatJSON,
+			want: want{cd: managed.ConnectionDetails{
+				"token":        []byte("s3cr3t"),
+				"install.yaml": []byte("kind: Namespace\nname: \"harness\"\n"),
+				KeyJSON:        []byte(`{"install.yaml":"kind: Namespace\nname: \"harness\"\n","token":"s3cr3t"}`),
+			}},
+		},
+		"Unknown": {
+			reason: "An unknown format should return an error that does not include the details.",
+			format: "Kubeconfig",
+			want:   want{err: errors.Errorf(errUnknownFormat, "Kubeconfig")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := Render(tc.format, cd)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cd, got); diff != "" {
+				t.Errorf("\n%s\nRender(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPublishConnection(t *testing.T) {
+	cr := &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ConnectionDetailsFormat: FormatDotenv}}
+
+	var got managed.ConnectionDetails
+	p := NewPublisher(managed.ConnectionPublisherFns{
+		PublishConnectionFn: func(_ context.Context, _ resource.ConnectionSecretOwner, c managed.ConnectionDetails) (bool, error) {
+			got = c
+			return true, nil
+		},
+	})
+	if _, err := p.PublishConnection(context.Background(), cr, managed.ConnectionDetails{"token": []byte("s3cr3t")}); err != nil {
+		t.Fatalf("PublishConnection(...): %v", err)
+	}
+
+	want := managed.ConnectionDetails{
+		"token":   []byte("s3cr3t"),
+		KeyDotenv: []byte("TOKEN=\"s3cr3t\"\n"),
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("PublishConnection(...): -want, +got:\n%s", diff)
+	}
+}
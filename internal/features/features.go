@@ -29,4 +29,16 @@ const (
 	// Management Policies. See the below design for more details.
 	// https://github.com/crossplane/crossplane/blob/master/design/design-doc-observe-only-resources.md
 	EnableAlphaManagementPolicies feature.Flag = "EnableAlphaManagementPolicies"
+
+	// EnableAlphaAgentDependentGC enables owner references from managed
+	// resources that depend on an Agent to that Agent, so that deleting the
+	// Agent cascades to its dependents before the agent itself is removed
+	// from Harness.
+	EnableAlphaAgentDependentGC feature.Flag = "EnableAlphaAgentDependentGC"
+
+	// EnableAlphaInClusterAgentHealth enables checking the readiness of the
+	// Deployment of agents that run in the provider's own cluster, in
+	// addition to the health Harness reports. It requires the provider to
+	// be granted permission to get Deployments.
+	EnableAlphaInClusterAgentHealth feature.Flag = "EnableAlphaInClusterAgentHealth"
 )
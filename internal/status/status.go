@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package status reduces the load managed resource status updates put on
+// the API server, and keeps them from clobbering conditions set by other
+// controllers.
+package status
+
+import (
+	"context"
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// SkipNoopUpdates wraps the supplied manager such that status updates made
+// through its client are skipped when they would not change the status of
+// the object. The managed resource reconciler writes status at the end of
+// every reconcile; for large fleets of stable resources most of these writes
+// are no-ops. Status updates also preserve conditions of types the updated
+// object does not have, which other controllers may have set.
+func SkipNoopUpdates(mgr ctrl.Manager) ctrl.Manager {
+	return &manager{Manager: mgr, client: &Client{Client: mgr.GetClient(), Fresh: mgr.GetAPIReader()}}
+}
+
+type manager struct {
+	ctrl.Manager
+	client client.Client
+}
+
+func (m *manager) GetClient() client.Client {
+	return m.client
+}
+
+// A Client skips status updates that would not change the status of an
+// object, and preserves conditions set by other controllers.
+type Client struct {
+	client.Client
+
+	// Fresh reads objects from the API server rather than from a cache, so
+	// that conditions other controllers set since the object being written
+	// was read are not lost. The Client itself is used if it is nil.
+	Fresh client.Reader
+}
+
+// Status returns a status writer that skips no-op updates.
+func (c *Client) Status() client.SubResourceWriter {
+	w := &writer{SubResourceWriter: c.Client.Status(), cached: c.Client, fresh: c.Fresh}
+	if w.fresh == nil {
+		w.fresh = c.Client
+	}
+	return w
+}
+
+type writer struct {
+	client.SubResourceWriter
+	cached client.Reader
+	fresh  client.Reader
+}
+
+// Update the status of the supplied object, unless it is identical to the
+// cached status. Otherwise the status is patched against a fresh read from
+// the API server. Conditions of types the supplied object does not have are
+// carried over from that read, so that the provider only owns the condition
+// types it sets. The patch fails if the status changed again after the read.
+func (w *writer) Update(ctx context.Context, obj client.Object, opts ...client.SubResourceUpdateOption) error {
+	cached, ok := obj.DeepCopyObject().(client.Object)
+	if !ok || len(opts) > 0 {
+		return w.SubResourceWriter.Update(ctx, obj, opts...)
+	}
+	if err := w.cached.Get(ctx, client.ObjectKeyFromObject(obj), cached); err == nil {
+		merge(cached, obj)
+		if Equal(cached, obj) {
+			return nil
+		}
+	}
+
+	// The API server's response is decoded into a new object, so that no
+	// field of the supplied object can survive in it.
+	current := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(client.Object)
+	// Any error, including NotFound, is left for the real update to report.
+	if err := w.fresh.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		return w.SubResourceWriter.Update(ctx, obj)
+	}
+	merge(current, obj)
+	return w.SubResourceWriter.Patch(ctx, obj, client.MergeFromWithOptions(current, client.MergeFromWithOptimisticLock{}))
+}
+
+// merge sets the foreign conditions of the current object on the desired one.
+func merge(current, desired client.Object) {
+	c, ok := desired.(resource.Conditioned)
+	if !ok {
+		return
+	}
+	if foreign := ForeignConditions(current, desired); len(foreign) > 0 {
+		c.SetConditions(foreign...)
+	}
+}
+
+// Equal returns true if the supplied objects have semantically equal status.
+func Equal(a, b runtime.Object) bool {
+	ua, err := runtime.DefaultUnstructuredConverter.ToUnstructured(a)
+	if err != nil {
+		return false
+	}
+	ub, err := runtime.DefaultUnstructuredConverter.ToUnstructured(b)
+	if err != nil {
+		return false
+	}
+	return equality.Semantic.DeepEqual(ua["status"], ub["status"])
+}
+
+// ForeignConditions returns the conditions of the current object whose types
+// the desired object does not have.
+func ForeignConditions(current, desired runtime.Object) []xpv1.Condition {
+	own := map[xpv1.ConditionType]bool{}
+	for _, c := range conditions(desired) {
+		own[c.Type] = true
+	}
+	var out []xpv1.Condition
+	for _, c := range conditions(current) {
+		if !own[c.Type] {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func conditions(o runtime.Object) []xpv1.Condition {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o)
+	if err != nil {
+		return nil
+	}
+	s := struct {
+		Status xpv1.ConditionedStatus `json:"status"`
+	}{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u, &s); err != nil {
+		return nil
+	}
+	return s.Status.Conditions
+}
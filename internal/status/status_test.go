@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestUpdate(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	agent := func(c ...xpv1.Condition) *v1alpha1.Agent {
+		a := &v1alpha1.Agent{}
+		a.SetName("cool")
+		a.Status.SetConditions(c...)
+		return a
+	}
+	available := agent(xpv1.Available(), xpv1.ReconcileSuccess())
+	current := func(a *v1alpha1.Agent) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			a.DeepCopyInto(obj.(*v1alpha1.Agent))
+			return nil
+		}
+	}
+
+	type args struct {
+		get   test.MockGetFn
+		fresh test.MockGetFn
+		obj   *v1alpha1.Agent
+	}
+	foreign := xpv1.Condition{Type: "PolicyCompliant", Status: "True", Reason: "Compliant"}
+
+	type want struct {
+		written    bool
+		conditions []xpv1.Condition
+		err        error
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   want
+	}{
+		"Unchanged": {
+			reason: "No status write should occur when the status is unchanged.",
+			args: args{
+				get: current(available),
+				obj: available.DeepCopy(),
+			},
+			want: want{written: false},
+		},
+		"Changed": {
+			reason: "Status should be written when it has changed.",
+			args: args{
+				get: current(agent(xpv1.Creating())),
+				obj: agent(xpv1.Available()),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available()}},
+		},
+		"ForeignCondition": {
+			reason: "A condition set by another controller should survive a status update.",
+			args: args{
+				get: current(agent(xpv1.Creating(), foreign)),
+				obj: agent(xpv1.Available()),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available(), foreign}},
+		},
+		"OwnConditionChanged": {
+			reason: "A condition type the provider sets should be overwritten, not preserved.",
+			args: args{
+				get: current(agent(xpv1.Creating(), foreign)),
+				obj: agent(xpv1.Available(), xpv1.Condition{Type: "PolicyCompliant", Status: "False", Reason: "NotCompliant"}),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available(), {Type: "PolicyCompliant", Status: "False", Reason: "NotCompliant"}}},
+		},
+		"ForeignConditionSinceRead": {
+			reason: "A condition another controller set after the object was read should survive a status update.",
+			args: args{
+				get:   current(agent(xpv1.Creating())),
+				fresh: current(agent(xpv1.Creating(), foreign)),
+				obj:   agent(xpv1.Available()),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available(), foreign}},
+		},
+		"CacheGetError": {
+			reason: "Status should be written if the cached status cannot be read.",
+			args: args{
+				get:   test.NewMockGetFn(errBoom),
+				fresh: current(agent(xpv1.Creating())),
+				obj:   agent(xpv1.Available()),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available()}},
+		},
+		"GetError": {
+			reason: "Status should be written if the current status cannot be read.",
+			args: args{
+				get: test.NewMockGetFn(errBoom),
+				obj: agent(xpv1.Available()),
+			},
+			want: want{written: true, conditions: []xpv1.Condition{xpv1.Available()}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			written := false
+			var conditions []xpv1.Condition
+			fresh := tc.args.fresh
+			if fresh == nil {
+				fresh = tc.args.get
+			}
+			c := &Client{
+				Client: &test.MockClient{
+					MockGet: tc.args.get,
+					MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+						written = true
+						conditions = obj.(*v1alpha1.Agent).Status.Conditions
+						return nil
+					},
+					MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+						written = true
+						conditions = obj.(*v1alpha1.Agent).Status.Conditions
+						return nil
+					},
+				},
+				Fresh: &test.MockClient{MockGet: fresh},
+			}
+			err := c.Status().Update(context.Background(), tc.args.obj)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.written, written); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want written, +got written:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// TestUpdatePatch asserts that the status is patched against the object read
+// from the API server, such that the patch fails if the status changes again.
+func TestUpdatePatch(t *testing.T) {
+	foreign := xpv1.Condition{Type: "PolicyCompliant", Status: "True", Reason: "Compliant"}
+	read := &v1alpha1.Agent{}
+	read.SetName("cool")
+	read.SetResourceVersion("1")
+	read.Status.SetConditions(xpv1.Creating())
+	written := read.DeepCopy()
+	written.SetResourceVersion("2")
+	written.Status.SetConditions(foreign)
+
+	var data string
+	c := &Client{
+		Client: &test.MockClient{
+			MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+				read.DeepCopyInto(obj.(*v1alpha1.Agent))
+				return nil
+			},
+			MockStatusPatch: func(_ context.Context, obj client.Object, p client.Patch, _ ...client.SubResourcePatchOption) error {
+				b, err := p.Data(obj)
+				data = string(b)
+				return err
+			},
+		},
+		Fresh: &test.MockClient{MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			written.DeepCopyInto(obj.(*v1alpha1.Agent))
+			return nil
+		}},
+	}
+
+	obj := read.DeepCopy()
+	obj.Status.SetConditions(xpv1.Available())
+	if err := c.Status().Update(context.Background(), obj); err != nil {
+		t.Fatalf("Update(...): %v", err)
+	}
+	if diff := cmp.Diff([]xpv1.Condition{xpv1.Available(), foreign}, obj.Status.Conditions, test.EquateConditions()); diff != "" {
+		t.Errorf("Update(...): -want conditions, +got conditions:\n%s", diff)
+	}
+	// A merge patch replaces the conditions, so it must hold the foreign one.
+	if !strings.Contains(data, `"resourceVersion":"2"`) || !strings.Contains(data, "PolicyCompliant") {
+		t.Errorf("Update(...): want a patch holding the foreign condition against resourceVersion 2, got %s", data)
+	}
+}
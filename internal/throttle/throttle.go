@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package throttle caps how often individual managed resources reconcile,
+// so that a single pathological resource cannot exhaust the API budget of a
+// Harness account.
+package throttle
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+// Annotations that configure throttling of a managed resource.
+const (
+	// AnnotationKeyMaxReconcilesPerMinute lowers the number of times the
+	// annotated resource may be reconciled per minute. It cannot raise the
+	// limit a Limiter enforces, but does apply if the Limiter has none.
+	AnnotationKeyMaxReconcilesPerMinute = "harness.crossplane.io/max-reconciles-per-minute"
+
+	// AnnotationKeyForceReconcile forces a reconcile regardless of budget
+	// each time its value changes, e.g. to the current time.
+	AnnotationKeyForceReconcile = "harness.crossplane.io/force-reconcile"
+)
+
+// window is the period over which reconciles are counted.
+const window = time.Minute
+
+const errThrottled = "reconcile budget of %d per minute exceeded, retrying in %s"
+
+// A Limiter tracks the reconciles of managed resources against a budget.
+type Limiter struct {
+	limit int
+
+	mu        sync.Mutex
+	budgets   map[types.UID]*budget
+	lastSweep time.Time
+}
+
+type budget struct {
+	reconciles []time.Time
+	force      string
+}
+
+// NewLimiter returns a Limiter that allows each resource the supplied number
+// of reconciles per minute, unless lowered by annotation. A limit of zero or
+// less allows unlimited reconciles of resources without the annotation.
+func NewLimiter(limit int) *Limiter {
+	return &Limiter{limit: limit, budgets: map[types.UID]*budget{}}
+}
+
+// Allow records a reconcile of the supplied resource at the supplied time if
+// it is within budget. It returns the budget that applied and, when the
+// reconcile is not allowed, how long until it would be.
+func (l *Limiter) Allow(o metav1.Object, now time.Time) (limit int, retryAfter time.Duration, ok bool) {
+	if l == nil {
+		return 0, 0, true
+	}
+	limit = l.limit
+	if v, err := strconv.Atoi(o.GetAnnotations()[AnnotationKeyMaxReconcilesPerMinute]); err == nil && v > 0 && (limit <= 0 || v < limit) {
+		limit = v
+	}
+	if limit <= 0 {
+		return limit, 0, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, exists := l.budgets[o.GetUID()]
+	if !exists {
+		b = &budget{}
+		l.budgets[o.GetUID()] = b
+	}
+	b.expire(now)
+
+	if f := o.GetAnnotations()[AnnotationKeyForceReconcile]; f != "" && f != b.force {
+		b.force = f
+		b.reconciles = append(b.reconciles, now)
+		return limit, 0, true
+	}
+	if len(b.reconciles) >= limit {
+		return limit, b.reconciles[0].Add(window).Sub(now), false
+	}
+	b.reconciles = append(b.reconciles, now)
+	return limit, 0, true
+}
+
+// NewConnecter wraps the supplied connecter such that resources that exceed
+// their reconcile budget are not connected, and are flagged with a Throttled
+// condition until they are connected again. Throttling happens before
+// anything else so that a resource stuck in an error loop stops consuming API
+// budget. The returned error causes the resource to be requeued with
+// exponential backoff. Wrap it around a deadletter connecter, so that
+// throttled reconciles are not counted as failures.
+func NewConnecter(c managed.ExternalConnecter, l *Limiter) managed.ExternalConnecter {
+	return &connecter{ExternalConnecter: c, limiter: l}
+}
+
+type connecter struct {
+	managed.ExternalConnecter
+	limiter *Limiter
+}
+
+func (c *connecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	limit, retryAfter, ok := c.limiter.Allow(mg, time.Now())
+	if !ok {
+		err := errors.Errorf(errThrottled, limit, retryAfter.Round(time.Second))
+		mg.SetConditions(v1alpha1.Throttled(err.Error()))
+		return nil, err
+	}
+	if mg.GetCondition(v1alpha1.TypeThrottled).Status == corev1.ConditionTrue {
+		mg.SetConditions(v1alpha1.Unthrottled())
+	}
+	return c.ExternalConnecter.Connect(ctx, mg)
+}
+
+// sweep forgets resources that have not reconciled within the window, so
+// that deleted resources do not leak.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < window {
+		return
+	}
+	l.lastSweep = now
+	for uid, b := range l.budgets {
+		b.expire(now)
+		if len(b.reconciles) == 0 {
+			delete(l.budgets, uid)
+		}
+	}
+}
+
+func (b *budget) expire(now time.Time) {
+	i := 0
+	for i < len(b.reconciles) && now.Sub(b.reconciles[i]) >= window {
+		i++
+	}
+	b.reconciles = b.reconciles[i:]
+}
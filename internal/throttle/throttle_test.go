@@ -0,0 +1,183 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/deadletter"
+)
+
+func TestAllow(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+
+	type step struct {
+		after       time.Duration
+		annotations map[string]string
+		ok          bool
+		retryAfter  time.Duration
+	}
+	cases := map[string]struct {
+		reason string
+		limit  int
+		steps  []step
+	}{
+		"Unlimited": {
+			reason: "Reconciles should never be throttled without a limit.",
+			limit:  0,
+			steps:  []step{{ok: true}, {ok: true}, {ok: true}},
+		},
+		"WithinBudget": {
+			reason: "Reconciles should be throttled once the budget is spent, until the window moves on.",
+			limit:  2,
+			steps: []step{
+				{ok: true},
+				{after: 10 * time.Second, ok: true},
+				{after: 20 * time.Second, ok: false, retryAfter: 40 * time.Second},
+				{after: 60 * time.Second, ok: true},
+			},
+		},
+		"AnnotationLowers": {
+			reason: "The annotation should lower the default budget.",
+			limit:  5,
+			steps: []step{
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "1"}, ok: true},
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "1"}, ok: false, retryAfter: time.Minute},
+			},
+		},
+		"AnnotationCannotRaise": {
+			reason: "The annotation should not raise the default budget.",
+			limit:  1,
+			steps: []step{
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "5"}, ok: true},
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "5"}, ok: false, retryAfter: time.Minute},
+			},
+		},
+		"AnnotationWithoutLimit": {
+			reason: "The annotation should apply even if there is no default budget.",
+			limit:  0,
+			steps: []step{
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "1"}, ok: true},
+				{annotations: map[string]string{AnnotationKeyMaxReconcilesPerMinute: "1"}, ok: false, retryAfter: time.Minute},
+			},
+		},
+		"ForceReconcile": {
+			reason: "Changing the force annotation should bypass the budget once.",
+			limit:  1,
+			steps: []step{
+				{ok: true},
+				{annotations: map[string]string{AnnotationKeyForceReconcile: "a"}, ok: true},
+				{annotations: map[string]string{AnnotationKeyForceReconcile: "a"}, ok: false, retryAfter: time.Minute},
+				{annotations: map[string]string{AnnotationKeyForceReconcile: "b"}, ok: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewLimiter(tc.limit)
+			for i, s := range tc.steps {
+				o := &metav1.ObjectMeta{UID: "cool", Annotations: s.annotations}
+				_, retryAfter, ok := l.Allow(o, start.Add(s.after))
+				if diff := cmp.Diff(s.ok, ok); diff != "" {
+					t.Errorf("\n%s\nstep %d: Allow(...): -want ok, +got ok:\n%s\n", tc.reason, i, diff)
+				}
+				if diff := cmp.Diff(s.retryAfter, retryAfter); diff != "" {
+					t.Errorf("\n%s\nstep %d: Allow(...): -want retryAfter, +got retryAfter:\n%s\n", tc.reason, i, diff)
+				}
+			}
+		})
+	}
+}
+
+func TestConnect(t *testing.T) {
+	type want struct {
+		connects   int
+		failures   int64
+		conditions []xpv1.Condition
+		err        error
+	}
+
+	throttled := &v1alpha1.Agent{}
+	throttled.SetConditions(v1alpha1.Throttled("reconcile budget of 1 per minute exceeded, retrying in 1m0s"))
+
+	cases := map[string]struct {
+		reason   string
+		cr       *v1alpha1.Agent
+		attempts int
+		want     want
+	}{
+		"Unthrottled": {
+			reason:   "A resource within its budget should be connected, and no longer flagged as throttled.",
+			cr:       throttled,
+			attempts: 1,
+			want: want{
+				connects:   1,
+				conditions: []xpv1.Condition{v1alpha1.Unthrottled()},
+			},
+		},
+		"Throttled": {
+			reason:   "A throttled resource should be flagged without being connected, or counted as failing.",
+			cr:       &v1alpha1.Agent{},
+			attempts: 2,
+			want: want{
+				connects:   1,
+				conditions: []xpv1.Condition{v1alpha1.Throttled("reconcile budget of 1 per minute exceeded, retrying in 1m0s")},
+				err:        errors.Errorf(errThrottled, 1, time.Minute),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			connects := 0
+			c := NewConnecter(deadletter.NewConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				connects++
+				return &managed.ExternalClientFns{}, nil
+			}), 1), NewLimiter(1))
+
+			var err error
+			for i := 0; i < tc.attempts; i++ {
+				_, err = c.Connect(context.Background(), tc.cr)
+			}
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.connects, connects); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want connects, +got connects:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.failures, tc.cr.GetConsecutiveFailures()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want failures, +got failures:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, tc.cr.Status.Conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nc.Connect(...): -want conditions, +got conditions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
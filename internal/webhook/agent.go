@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+// defaultAgentNamespace is the namespace an agent is installed in unless its
+// Agent specifies one.
+const defaultAgentNamespace = "harness"
+
+const (
+	errNoAccountIdentifier = "accountIdentifier is required unless the ProviderConfig has a default account"
+	errInvalidType         = "type %q is invalid: it must be MANAGED_ARGO or CONNECTED_ARGO"
+	errInvalidIdentifier   = "%s %q is invalid: a Harness identifier must start with a letter or underscore, contain only letters, digits, underscores and dollar signs, and be at most 128 characters long"
+	errForeignReferences   = "%s must reference objects in the NamespacedAgent's own namespace %q"
+)
+
+// identifierRE matches the identifiers Harness accepts for its entities.
+var identifierRE = regexp.MustCompile(`^[a-zA-Z_][0-9a-zA-Z_$]{0,127}$`)
+
+// validateParameters rejects Agents that Harness would reject when the Agent
+// is reconciled: those without an account, of an unknown type, or with an
+// identifier, or an organization or project identifier, Harness does not
+// accept. Resources of other kinds are left to Harness to validate.
+func validateParameters(obj runtime.Object) error {
+	switch obj.(type) {
+	case *v1alpha1.Agent, *v1alpha1.NamespacedAgent:
+	default:
+		return nil
+	}
+	params, err := parameters(obj)
+	if err != nil {
+		return err
+	}
+	if clients.StringValue(params.AccountIdentifier) == "" {
+		return errors.New(errNoAccountIdentifier)
+	}
+	if t := params.Type; t != nil && *t != v1alpha1.AgentTypeManagedArgo && *t != v1alpha1.AgentTypeConnectedArgo {
+		return errors.Errorf(errInvalidType, *t)
+	}
+	for _, id := range []struct {
+		field string
+		value *string
+	}{
+		{field: "identifier", value: params.Identifier},
+		{field: "orgIdentifier", value: params.OrgIdentifier},
+		{field: "projectIdentifier", value: params.ProjectIdentifier},
+	} {
+		if id.value != nil && !identifierRE.MatchString(*id.value) {
+			return errors.Errorf(errInvalidIdentifier, id.field, *id.value)
+		}
+	}
+	return nil
+}
+
+// validateReferences rejects NamespacedAgents that reference objects outside
+// their own namespace, which their controller refuses to read or write.
+func validateReferences(obj runtime.Object) error {
+	na, ok := obj.(*v1alpha1.NamespacedAgent)
+	if !ok {
+		return nil
+	}
+	if f := na.ForeignReferences(); len(f) > 0 {
+		return errors.Errorf(errForeignReferences, strings.Join(f, ", "), na.GetNamespace())
+	}
+	return nil
+}
+
+// DefaultAgent defaults the namespace an agent is installed in, and whether it
+// is installed with replicated components, so that the defaults are recorded
+// in the Agent's spec rather than implied by the controller.
+func DefaultAgent(_ context.Context, obj runtime.Object) error {
+	var params *v1alpha1.AgentParameters
+	switch o := obj.(type) {
+	case *v1alpha1.Agent:
+		params = &o.Spec.ForProvider
+	case *v1alpha1.NamespacedAgent:
+		params = &o.Spec.ForProvider
+	default:
+		return errors.Errorf(errUnsupportedKind, obj)
+	}
+	if params.Namespace == nil {
+		ns := defaultAgentNamespace
+		params.Namespace = &ns
+	}
+	if params.HighAvailability == nil {
+		ha := true
+		params.HighAvailability = &ha
+	}
+	return nil
+}
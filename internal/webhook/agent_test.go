@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+)
+
+func TestValidateParameters(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	cases := map[string]struct {
+		reason string
+		params v1alpha1.AgentParameters
+		want   error
+	}{
+		"Valid": {
+			reason: "An Agent with an account and valid identifiers should be admitted.",
+			params: v1alpha1.AgentParameters{
+				AccountIdentifier: str("nYY7inrwTrqqa3r1a_-krg"),
+				OrgIdentifier:     str("Innovation"),
+				ProjectIdentifier: str("ahpoc"),
+				Identifier:        str("_gitops$agent1"),
+			},
+		},
+		"NoAccount": {
+			reason: "An Agent without an account should be rejected.",
+			params: v1alpha1.AgentParameters{Identifier: str("agent")},
+			want:   errors.New(errNoAccountIdentifier),
+		},
+		"EmptyAccount": {
+			reason: "An Agent with an empty account should be rejected.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("")},
+			want:   errors.New(errNoAccountIdentifier),
+		},
+		"ValidType": {
+			reason: "An Agent of a type Harness supports should be admitted.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("account"), Type: str(v1alpha1.AgentTypeConnectedArgo)},
+		},
+		"InvalidType": {
+			reason: "An Agent of a type Harness does not support should be rejected.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("account"), Type: str("ARGO")},
+			want:   errors.Errorf(errInvalidType, "ARGO"),
+		},
+		"InvalidCharacters": {
+			reason: "An identifier containing characters Harness does not accept should be rejected.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("account"), Identifier: str("gitops-agent")},
+			want:   errors.Errorf(errInvalidIdentifier, "identifier", "gitops-agent"),
+		},
+		"LeadingDigit": {
+			reason: "An identifier starting with a digit should be rejected.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("account"), ProjectIdentifier: str("1project")},
+			want:   errors.Errorf(errInvalidIdentifier, "projectIdentifier", "1project"),
+		},
+		"TooLong": {
+			reason: "An identifier longer than 128 characters should be rejected.",
+			params: v1alpha1.AgentParameters{AccountIdentifier: str("account"), Identifier: str(strings.Repeat("a", 129))},
+			want:   errors.Errorf(errInvalidIdentifier, "identifier", strings.Repeat("a", 129)),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateParameters(&v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: tc.params}})
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateParameters(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateReferences(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   error
+	}{
+		"Agent": {
+			reason: "An Agent may reference objects in any namespace.",
+			obj: &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				InClusterDeployment: &v1alpha1.DeploymentReference{Namespace: "harness", Name: "gitops-agent"},
+			}}},
+		},
+		"OwnNamespace": {
+			reason: "A NamespacedAgent referencing objects in its own namespace, explicitly or not, should be accepted.",
+			obj: &v1alpha1.NamespacedAgent{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+				Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+					DescriptionFrom:     &v1alpha1.ConfigMapKeySelector{Namespace: "team-a", Name: "description", Key: "text"},
+					InClusterDeployment: &v1alpha1.DeploymentReference{Name: "gitops-agent"},
+				}},
+			},
+		},
+		"ForeignNamespace": {
+			reason: "A NamespacedAgent referencing objects in another namespace should be rejected.",
+			obj: &v1alpha1.NamespacedAgent{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "team-a"},
+				Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+					DescriptionFrom:     &v1alpha1.ConfigMapKeySelector{Namespace: "team-b", Name: "description", Key: "text"},
+					InClusterDeployment: &v1alpha1.DeploymentReference{Namespace: "harness", Name: "gitops-agent"},
+				}},
+			},
+			want: errors.Errorf(errForeignReferences, "spec.forProvider.descriptionFrom, spec.forProvider.inClusterDeployment", "team-a"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateReferences(tc.obj)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nvalidateReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDefaultAgent(t *testing.T) {
+	ns, ha, noHA := "gitops", true, false
+
+	type want struct {
+		obj runtime.Object
+		err error
+	}
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   want
+	}{
+		"Unset": {
+			reason: "An Agent should default to the harness namespace and high availability.",
+			obj:    &v1alpha1.Agent{},
+			want: want{obj: &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				Namespace:        func() *string { s := defaultAgentNamespace; return &s }(),
+				HighAvailability: &ha,
+			}}}},
+		},
+		"Set": {
+			reason: "Values a NamespacedAgent specifies should not be overridden.",
+			obj: &v1alpha1.NamespacedAgent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				Namespace:        &ns,
+				HighAvailability: &noHA,
+			}}},
+			want: want{obj: &v1alpha1.NamespacedAgent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{
+				Namespace:        &ns,
+				HighAvailability: &noHA,
+			}}}},
+		},
+		"UnsupportedKind": {
+			reason: "Kinds other than agents should be rejected.",
+			obj:    &v1alpha1.Cluster{},
+			want:   want{obj: &v1alpha1.Cluster{}, err: errors.Errorf(errUnsupportedKind, &v1alpha1.Cluster{})},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := DefaultAgent(context.Background(), tc.obj)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nDefaultAgent(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obj, tc.obj); diff != "" {
+				t.Errorf("\n%s\nDefaultAgent(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
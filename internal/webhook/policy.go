@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Keys of a scope policy ConfigMap.
+const (
+	// KeyAllowedScopes lists the allowed scopes, one per line. A line of the
+	// form "org" allows an organization and all of its projects, while a
+	// line of the form "org/project" allows a single project.
+	KeyAllowedScopes = "allowedScopes"
+
+	// KeyAllowAccountScope allows resources that target no organization
+	// when set to "true".
+	KeyAllowAccountScope = "allowAccountScope"
+)
+
+const (
+	errGetPolicy           = "cannot get scope policy ConfigMap"
+	errAccountScopeDenied  = "account scoped resources are not allowed by scope policy %s"
+	errOrgScopeDenied      = "organization %q is not allowed by scope policy %s"
+	errProjectScopeDenied  = "project %q of organization %q is not allowed by scope policy %s"
+	errProjectWithoutOrgID = "project %q must specify its organization"
+)
+
+// A ScopePolicy restricts the Harness organizations and projects that
+// managed resources may target.
+type ScopePolicy struct {
+	source      string
+	account     bool
+	orgs        map[string]bool
+	orgProjects map[string]bool
+}
+
+// ParseScopePolicy parses a ScopePolicy from the data of a ConfigMap. The
+// supplied source is used to identify the policy in error messages.
+func ParseScopePolicy(source string, data map[string]string) *ScopePolicy {
+	p := &ScopePolicy{
+		source:      source,
+		account:     strings.TrimSpace(data[KeyAllowAccountScope]) == "true",
+		orgs:        map[string]bool{},
+		orgProjects: map[string]bool{},
+	}
+	for _, l := range strings.Split(data[KeyAllowedScopes], "\n") {
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "" || strings.HasPrefix(l, "#"):
+			continue
+		case strings.Contains(l, "/"):
+			p.orgProjects[l] = true
+		default:
+			p.orgs[l] = true
+		}
+	}
+	return p
+}
+
+// Allows returns an error if the supplied organization and project are not
+// allowed by the policy. A nil ScopePolicy allows everything.
+func (p *ScopePolicy) Allows(org, project string) error {
+	if p == nil {
+		return nil
+	}
+	switch {
+	case org == "" && project != "":
+		return errors.Errorf(errProjectWithoutOrgID, project)
+	case org == "":
+		if !p.account {
+			return errors.Errorf(errAccountScopeDenied, p.source)
+		}
+		return nil
+	case p.orgs[org]:
+		return nil
+	case project == "":
+		return errors.Errorf(errOrgScopeDenied, org, p.source)
+	case p.orgProjects[org+"/"+project]:
+		return nil
+	}
+	return errors.Errorf(errProjectScopeDenied, project, org, p.source)
+}
+
+// A ScopePolicyLoader loads the ScopePolicy in effect from a ConfigMap. The
+// ConfigMap is read on every load so that policy changes apply immediately.
+type ScopePolicyLoader struct {
+	reader client.Reader
+	ref    types.NamespacedName
+}
+
+// NewScopePolicyLoader returns a ScopePolicyLoader that reads the supplied
+// ConfigMap.
+func NewScopePolicyLoader(r client.Reader, ref types.NamespacedName) *ScopePolicyLoader {
+	return &ScopePolicyLoader{reader: r, ref: ref}
+}
+
+// Load the ScopePolicy in effect. It returns a nil ScopePolicy, which allows
+// everything, if the ConfigMap does not exist.
+func (l *ScopePolicyLoader) Load(ctx context.Context) (*ScopePolicy, error) {
+	cm := &corev1.ConfigMap{}
+	if err := l.reader.Get(ctx, l.ref, cm); err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errGetPolicy)
+	}
+	return ParseScopePolicy(l.ref.String(), cm.Data), nil
+}
@@ -0,0 +1,287 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	platformv1alpha1 "github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+func TestAllows(t *testing.T) {
+	source := "crossplane-system/scopes"
+	data := map[string]string{
+		KeyAllowedScopes: "# platform teams\nplatform\n\npayments/checkout\n",
+	}
+
+	type args struct {
+		data    map[string]string
+		org     string
+		project string
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"AllowedOrg": {
+			reason: "An allowed organization should be allowed.",
+			args:   args{data: data, org: "platform"},
+		},
+		"AllowedOrgProject": {
+			reason: "Any project of an allowed organization should be allowed.",
+			args:   args{data: data, org: "platform", project: "anything"},
+		},
+		"AllowedProject": {
+			reason: "An allowed project should be allowed.",
+			args:   args{data: data, org: "payments", project: "checkout"},
+		},
+		"DeniedProject": {
+			reason: "A project that is not allowed should be denied.",
+			args:   args{data: data, org: "payments", project: "ledger"},
+			want:   errors.Errorf(errProjectScopeDenied, "ledger", "payments", source),
+		},
+		"DeniedOrg": {
+			reason: "An organization is not allowed just because one of its projects is.",
+			args:   args{data: data, org: "payments"},
+			want:   errors.Errorf(errOrgScopeDenied, "payments", source),
+		},
+		"DeniedAccount": {
+			reason: "Account scoped resources should be denied by default.",
+			args:   args{data: data},
+			want:   errors.Errorf(errAccountScopeDenied, source),
+		},
+		"AllowedAccount": {
+			reason: "Account scoped resources should be allowed when the policy allows them.",
+			args:   args{data: map[string]string{KeyAllowAccountScope: "true"}},
+		},
+		"ProjectWithoutOrg": {
+			reason: "A project without an organization should be denied.",
+			args:   args{data: data, project: "checkout"},
+			want:   errors.Errorf(errProjectWithoutOrgID, "checkout"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ParseScopePolicy(source, tc.args.data).Allows(tc.args.org, tc.args.project)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nAllows(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	errBoom := errors.New("boom")
+	ref := types.NamespacedName{Namespace: "crossplane-system", Name: "scopes"}
+	account := "account"
+	agent := func(org string) *v1alpha1.Agent {
+		return &v1alpha1.Agent{Spec: v1alpha1.AgentSpec{ForProvider: v1alpha1.AgentParameters{AccountIdentifier: &account, OrgIdentifier: &org}}}
+	}
+	withPolicy := func(scopes string) test.MockGetFn {
+		return func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			obj.(*corev1.ConfigMap).Data = map[string]string{KeyAllowedScopes: scopes}
+			return nil
+		}
+	}
+
+	project := func(org, identifier string) *platformv1alpha1.Project {
+		return &platformv1alpha1.Project{Spec: platformv1alpha1.ProjectSpec{ForProvider: platformv1alpha1.ProjectParameters{
+			AccountIdentifier: account, OrgIdentifier: org, Identifier: identifier,
+		}}}
+	}
+
+	connector := func(org, project string) *platformv1alpha1.Connector {
+		return &platformv1alpha1.Connector{Spec: platformv1alpha1.ConnectorSpec{ForProvider: platformv1alpha1.ConnectorParameters{
+			AccountIdentifier: account, OrgIdentifier: &org, ProjectIdentifier: &project, Identifier: "github",
+		}}}
+	}
+
+	type args struct {
+		get test.MockGetFn
+		obj runtime.Object
+	}
+	cases := map[string]struct {
+		reason string
+		args   args
+		want   error
+	}{
+		"NoPolicy": {
+			reason: "Everything should be allowed if the policy ConfigMap does not exist.",
+			args: args{
+				get: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, "scopes")),
+				obj: agent("anything"),
+			},
+		},
+		"GetError": {
+			reason: "Errors reading the policy should be returned.",
+			args: args{
+				get: test.NewMockGetFn(errBoom),
+				obj: agent("anything"),
+			},
+			want: errors.Wrap(errBoom, errGetPolicy),
+		},
+		"Allowed": {
+			reason: "An Agent in an allowed organization should be admitted.",
+			args: args{
+				get: withPolicy("platform"),
+				obj: agent("platform"),
+			},
+		},
+		"Denied": {
+			reason: "An Agent in another organization should be rejected.",
+			args: args{
+				get: withPolicy("platform"),
+				obj: agent("payments"),
+			},
+			want: errors.Errorf(errOrgScopeDenied, "payments", ref.String()),
+		},
+		"InvalidParameters": {
+			reason: "An Agent Harness would reject should be rejected before the policy is read.",
+			args: args{
+				get: test.NewMockGetFn(errBoom),
+				obj: agent("platform-team"),
+			},
+			want: errors.Errorf(errInvalidIdentifier, "orgIdentifier", "platform-team"),
+		},
+		"InvalidTags": {
+			reason: "An Agent whose tags exceed Harness's limits should be rejected.",
+			args: args{
+				get: withPolicy("platform"),
+				obj: func() *v1alpha1.Agent {
+					a := agent("platform")
+					a.Spec.ForProvider.Tags = &map[string]string{"": "v"}
+					return a
+				}(),
+			},
+			want: errors.Wrap(clients.ValidateTags(map[string]string{"": "v"}), errInvalidTags),
+		},
+		"DefaultAccount": {
+			reason: "An Agent without an account should be admitted if its ProviderConfig has a default account.",
+			args: args{
+				get: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *apisv1alpha1.ProviderConfig:
+						o.Spec.DefaultScope = &apisv1alpha1.Scope{AccountIdentifier: "account"}
+					case *corev1.ConfigMap:
+						o.Data = map[string]string{KeyAllowedScopes: "platform"}
+					}
+					return nil
+				},
+				obj: func() *v1alpha1.Agent {
+					a := agent("platform")
+					a.Spec.ForProvider.AccountIdentifier = nil
+					a.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+					return a
+				}(),
+			},
+		},
+		"ProviderConfigError": {
+			reason: "Errors reading the ProviderConfig should be returned.",
+			args: args{
+				get: test.NewMockGetFn(errBoom),
+				obj: func() *v1alpha1.Agent {
+					a := agent("platform")
+					a.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+					return a
+				}(),
+			},
+			want: errors.Wrap(errBoom, errGetPC),
+		},
+		"ProjectAllowed": {
+			reason: "A Project the policy allows should be admitted.",
+			args: args{
+				get: withPolicy("payments/guestbook"),
+				obj: project("payments", "guestbook"),
+			},
+		},
+		"ProjectDenied": {
+			reason: "A Project the policy does not allow should be rejected.",
+			args: args{
+				get: withPolicy("payments/guestbook"),
+				obj: project("payments", "ledger"),
+			},
+			want: errors.Errorf(errProjectScopeDenied, "ledger", "payments", ref.String()),
+		},
+		"ProjectInvalidTags": {
+			reason: "A Project whose tags exceed Harness's limits should be rejected.",
+			args: args{
+				get: withPolicy("payments"),
+				obj: func() *platformv1alpha1.Project {
+					p := project("payments", "guestbook")
+					p.Spec.ForProvider.Tags = map[string]string{"": "v"}
+					return p
+				}(),
+			},
+			want: errors.Wrap(clients.ValidateTags(map[string]string{"": "v"}), errInvalidTags),
+		},
+		"ConnectorAllowed": {
+			reason: "A Connector in a project the policy allows should be admitted.",
+			args: args{
+				get: withPolicy("payments/guestbook"),
+				obj: connector("payments", "guestbook"),
+			},
+		},
+		"ConnectorDenied": {
+			reason: "A Connector in a project the policy does not allow should be rejected.",
+			args: args{
+				get: withPolicy("payments/guestbook"),
+				obj: connector("payments", "ledger"),
+			},
+			want: errors.Errorf(errProjectScopeDenied, "ledger", "payments", ref.String()),
+		},
+		"ConnectorInvalidTags": {
+			reason: "A Connector whose tags exceed Harness's limits should be rejected.",
+			args: args{
+				get: withPolicy("payments"),
+				obj: func() *platformv1alpha1.Connector {
+					c := connector("payments", "guestbook")
+					c.Spec.ForProvider.Tags = map[string]string{"": "v"}
+					return c
+				}(),
+			},
+			want: errors.Wrap(clients.ValidateTags(map[string]string{"": "v"}), errInvalidTags),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			l := NewScopePolicyLoader(&test.MockClient{MockGet: tc.args.get}, ref)
+			got := l.ValidateCreate(context.Background(), tc.args.obj)
+			if diff := cmp.Diff(tc.want, got, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nValidateCreate(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2022 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook contains the admission webhooks of the Harness provider.
+package webhook
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	xpwebhook "github.com/crossplane/crossplane-runtime/pkg/webhook"
+
+	"github.com/crossplane/provider-harness/apis/gitops/v1alpha1"
+	platformv1alpha1 "github.com/crossplane/provider-harness/apis/platform/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-harness/apis/v1alpha1"
+	"github.com/crossplane/provider-harness/internal/clients"
+)
+
+const (
+	errUnsupportedKind = "unsupported kind %T"
+	errSetupWebhook    = "cannot setup webhook for %T"
+	errInvalidTags     = "invalid tags"
+	errGetPC           = "cannot get ProviderConfig"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-gitops-harness-crossplane-io-v1alpha1-agent,mutating=false,failurePolicy=fail,groups=gitops.harness.crossplane.io,resources=agents,versions=v1alpha1,name=agents.gitops.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-gitops-harness-crossplane-io-v1alpha1-namespacedagent,mutating=false,failurePolicy=fail,groups=gitops.harness.crossplane.io,resources=namespacedagents,versions=v1alpha1,name=namespacedagents.gitops.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-platform-harness-crossplane-io-v1alpha1-project,mutating=false,failurePolicy=fail,groups=platform.harness.crossplane.io,resources=projects,versions=v1alpha1,name=projects.platform.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-platform-harness-crossplane-io-v1alpha1-connector,mutating=false,failurePolicy=fail,groups=platform.harness.crossplane.io,resources=connectors,versions=v1alpha1,name=connectors.platform.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-gitops-harness-crossplane-io-v1alpha1-agent,mutating=true,failurePolicy=fail,groups=gitops.harness.crossplane.io,resources=agents,versions=v1alpha1,name=default.agents.gitops.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-gitops-harness-crossplane-io-v1alpha1-namespacedagent,mutating=true,failurePolicy=fail,groups=gitops.harness.crossplane.io,resources=namespacedagents,versions=v1alpha1,name=default.namespacedagents.gitops.harness.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+
+// Setup registers the admission webhooks with the supplied manager. The scope
+// policy is read from the ConfigMap identified by policy; resources are not
+// restricted if it does not exist. Agents are defaulted before they are
+// validated; other kinds are only validated.
+func Setup(mgr ctrl.Manager, policy types.NamespacedName) error {
+	l := NewScopePolicyLoader(mgr.GetAPIReader(), policy)
+	v := xpwebhook.NewValidator(
+		xpwebhook.WithValidateCreationFns(l.ValidateCreate),
+		xpwebhook.WithValidateUpdateFns(l.ValidateUpdate),
+	)
+	m := xpwebhook.NewMutator(xpwebhook.WithMutationFns(DefaultAgent))
+	for _, obj := range []runtime.Object{&v1alpha1.Agent{}, &v1alpha1.NamespacedAgent{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).WithDefaulter(m).WithValidator(v).Complete(); err != nil {
+			return errors.Wrapf(err, errSetupWebhook, obj)
+		}
+	}
+	for _, obj := range []runtime.Object{&platformv1alpha1.Project{}, &platformv1alpha1.Connector{}} {
+		if err := ctrl.NewWebhookManagedBy(mgr).For(obj).WithValidator(v).Complete(); err != nil {
+			return errors.Wrapf(err, errSetupWebhook, obj)
+		}
+	}
+	return nil
+}
+
+// ValidateCreate rejects resources with parameters Harness would reject, that
+// reference objects outside their own namespace, whose scope is not allowed by the policy, or whose tags exceed the limits Harness
+// enforces. Resources are validated with the default scope of their
+// ProviderConfig applied, as they are when they are reconciled.
+func (l *ScopePolicyLoader) ValidateCreate(ctx context.Context, obj runtime.Object) error {
+	obj, err := l.withDefaultScope(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err := validateParameters(obj); err != nil {
+		return err
+	}
+	if err := validateReferences(obj); err != nil {
+		return err
+	}
+	p, err := l.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := validateScope(p, obj); err != nil {
+		return err
+	}
+	return validateTags(obj)
+}
+
+// ValidateUpdate rejects resources with parameters Harness would reject, whose
+// scope is not allowed by the policy, or whose tags exceed the limits Harness
+// enforces.
+func (l *ScopePolicyLoader) ValidateUpdate(ctx context.Context, _, newObj runtime.Object) error {
+	return l.ValidateCreate(ctx, newObj)
+}
+
+// withDefaultScope returns a copy of the supplied resource with the default
+// scope of its ProviderConfig applied. No default scope is applied if the
+// ProviderConfig does not exist (yet).
+func (l *ScopePolicyLoader) withDefaultScope(ctx context.Context, obj runtime.Object) (runtime.Object, error) {
+	r, ok := obj.(resource.ProviderConfigReferencer)
+	if !ok || r.GetProviderConfigReference() == nil {
+		return obj, nil
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := l.reader.Get(ctx, types.NamespacedName{Name: r.GetProviderConfigReference().Name}, pc); err != nil {
+		if kerrors.IsNotFound(err) {
+			return obj, nil
+		}
+		return nil, errors.Wrap(err, errGetPC)
+	}
+	d := pc.Spec.DefaultScope
+	if d == nil {
+		return obj, nil
+	}
+	obj = obj.DeepCopyObject()
+	switch o := obj.(type) {
+	case *v1alpha1.Agent:
+		o.Spec.ForProvider.DefaultScope(d.AccountIdentifier, d.OrgIdentifier, d.ProjectIdentifier)
+	case *v1alpha1.NamespacedAgent:
+		o.Spec.ForProvider.DefaultScope(d.AccountIdentifier, d.OrgIdentifier, d.ProjectIdentifier)
+	}
+	return obj, nil
+}
+
+func validateScope(p *ScopePolicy, obj runtime.Object) error {
+	org, project, _, err := scope(obj)
+	if err != nil {
+		return err
+	}
+	return p.Allows(org, project)
+}
+
+// validateTags validates the tags set on the supplied resource. The
+// ProviderConfig's default tags are merged in, and validated again, when the
+// resource is reconciled.
+func validateTags(obj runtime.Object) error {
+	_, _, tags, err := scope(obj)
+	if err != nil || tags == nil {
+		return err
+	}
+	return errors.Wrap(clients.ValidateTags(tags), errInvalidTags)
+}
+
+// scope returns the organization and project the supplied resource targets,
+// and its tags. A Project targets itself.
+func scope(obj runtime.Object) (org, project string, tags map[string]string, err error) {
+	switch o := obj.(type) {
+	case *platformv1alpha1.Project:
+		p := o.Spec.ForProvider
+		return p.OrgIdentifier, p.Identifier, p.Tags, nil
+	case *platformv1alpha1.Connector:
+		p := o.Spec.ForProvider
+		return clients.StringValue(p.OrgIdentifier), clients.StringValue(p.ProjectIdentifier), p.Tags, nil
+	}
+	params, err := parameters(obj)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if params.Tags != nil {
+		tags = *params.Tags
+	}
+	return clients.StringValue(params.OrgIdentifier), clients.StringValue(params.ProjectIdentifier), tags, nil
+}
+
+func parameters(obj runtime.Object) (v1alpha1.AgentParameters, error) {
+	switch o := obj.(type) {
+	case *v1alpha1.Agent:
+		return o.Spec.ForProvider, nil
+	case *v1alpha1.NamespacedAgent:
+		return o.Spec.ForProvider, nil
+	}
+	return v1alpha1.AgentParameters{}, errors.Errorf(errUnsupportedKind, obj)
+}